@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/layla-lili/blockchain_tools/internal/api"
+	"github.com/layla-lili/blockchain_tools/internal/api/cache"
 	"github.com/layla-lili/blockchain_tools/internal/api/handlers"
+	"github.com/layla-lili/blockchain_tools/internal/api/metrics"
 	"github.com/layla-lili/blockchain_tools/internal/api/middleware"
 	"github.com/layla-lili/blockchain_tools/internal/api/swagger"
+	"github.com/layla-lili/blockchain_tools/internal/common/logging"
+	"github.com/layla-lili/blockchain_tools/internal/common/tracing"
 	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/indexer"
 )
 
 var (
@@ -24,18 +33,106 @@ var (
 )
 
 func main() {
+	// Requests and the RPC calls they make are traced when
+	// BLOCKCHAIN_TRACE_EXPORTER is otlp or stdout
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       os.Getenv("BLOCKCHAIN_TRACE_EXPORTER"),
+		ServiceName:    "blockchain-api",
+		ServiceVersion: Version,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+
 	// Initialize RPC client
 	rpcURL := os.Getenv("BLOCKCHAIN_RPC_URL")
 	if rpcURL == "" {
 		rpcURL = "http://localhost:8545" // default value
 	}
-	client, err := rpc.NewClient(rpcURL)
+	// Several endpoints may be listed, separated by commas. Calls fail over
+	// between them and retry transient errors.
+	clientOpts, err := clientOptions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Requests to the API and to the node are recorded for /metrics
+	m := metrics.New()
+	clientOpts = append(clientOpts, rpc.WithObserver(m.ObserveCall))
+	client, err := rpc.NewClient(rpcURL, clientOpts...)
 	if err != nil {
 		log.Fatalf("Failed to create RPC client: %v", err)
 	}
+	defer client.Close()
+
+	// Streaming uses eth_subscribe when a WebSocket endpoint is configured
+	// and polls the HTTP endpoint otherwise
+	streamClient := client
+	if wsURL := os.Getenv("BLOCKCHAIN_WS_URL"); wsURL != "" {
+		streamClient, err = rpc.NewClient(wsURL, rpc.WithObserver(m.ObserveCall))
+		if err != nil {
+			log.Fatalf("Failed to create WebSocket RPC client: %v", err)
+		}
+	}
+
+	// The chain index backs the transaction listing endpoints. It is
+	// optional since building it requires following the chain.
+	indexCtx, stopIndexer := context.WithCancel(context.Background())
+	defer stopIndexer()
+
+	var store *indexer.Store
+	if indexDB := os.Getenv("BLOCKCHAIN_INDEX_DB"); indexDB != "" {
+		store, err = indexer.OpenStore(indexDB)
+		if err != nil {
+			log.Fatalf("Failed to open chain index: %v", err)
+		}
+		defer store.Close()
+
+		var start uint64
+		if v := os.Getenv("BLOCKCHAIN_INDEX_START"); v != "" {
+			if start, err = strconv.ParseUint(v, 10, 64); err != nil {
+				log.Fatalf("Invalid BLOCKCHAIN_INDEX_START: %v", err)
+			}
+		}
+
+		ix := indexer.New(client, store,
+			indexer.WithStartHeight(start),
+			indexer.WithLogger(logging.NewLogger()))
+		go ix.Run(indexCtx)
+	}
+
+	// Final blocks, transactions and receipts are served from the cache
+	responses, err := responseCache(client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m.WatchNode(client)
+	m.WatchCache(responses)
+
+	// API keys are required once a keys file is configured
+	var keys *middleware.APIKeys
+	if keysFile := os.Getenv("BLOCKCHAIN_API_KEYS_FILE"); keysFile != "" {
+		keys, err = middleware.LoadAPIKeys(keysFile)
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
+		}
+		log.Printf("Loaded %d API keys from %s", keys.Len(), keysFile)
+	} else {
+		log.Printf("WARNING: BLOCKCHAIN_API_KEYS_FILE is not set, the API is open to anyone")
+	}
 
-	// Set up Gin router
+	// Set up Gin router. Client addresses are taken from X-Forwarded-For
+	// only when the request comes through a trusted proxy.
 	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid BLOCKCHAIN_API_TRUSTED_PROXIES: %v", err)
+	}
 
 	// Load API spec
 	spec := api.GetSwagger()
@@ -63,7 +160,12 @@ func main() {
 		c.JSON(http.StatusOK, spec)
 	})
 
+	// Serve Prometheus metrics. Like the docs, they need no API key.
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+
 	// Add middleware
+	router.Use(middleware.Tracing())
+	router.Use(middleware.Metrics(m))
 	router.Use(middleware.Logger())
 	router.Use(middleware.Cors())
 
@@ -77,24 +179,55 @@ func main() {
 		}
 	})
 
-	// API routes
+	// API routes. Every route needs a key with the scope of its group when
+	// API keys are configured, and each group is rate limited per client.
 	api := router.Group("/api/v1")
+	readLimit, sendLimit, adminLimit, authLimit, err := rateLimits()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if keys != nil {
+		var opts []middleware.AuthOption
+		if authLimit != nil {
+			opts = append(opts, middleware.WithFailureLimit(authLimit))
+		}
+		api.Use(middleware.Auth(keys, opts...))
+	}
+	read := api.Group("", middleware.RequireScope(middleware.ScopeRead), readLimit)
+	send := api.Group("", middleware.RequireScope(middleware.ScopeSend), sendLimit)
+	admin := api.Group("", middleware.RequireScope(middleware.ScopeAdmin), adminLimit)
 	{
 		// Block endpoints
-		api.GET("/blocks/:number", handlers.GetBlock(client))
-		api.GET("/blocks/latest", handlers.GetLatestBlock(client))
+		read.GET("/blocks/:number", handlers.GetBlock(client, responses))
+		read.GET("/blocks/latest", handlers.GetLatestBlock(client))
 
 		// Transaction endpoints
-		api.POST("/transactions", handlers.SendTransaction(client))
-		api.GET("/transactions/:hash", handlers.GetTransaction(client))
+		send.POST("/transactions", handlers.SendTransaction(client))
+		read.GET("/transactions", handlers.ListTransactions(store))
+		read.GET("/transactions/:hash", handlers.GetTransaction(client, responses))
+		read.GET("/transactions/:hash/receipt", handlers.GetTransactionReceipt(client, responses))
+		read.GET("/transactions/:hash/trace", handlers.TraceTransaction(client))
 
 		// Account endpoints
-		api.GET("/accounts/:address", handlers.GetAccount(client))
-		api.GET("/accounts/:address/balance", handlers.GetBalance(client))
+		read.GET("/accounts/:address", handlers.GetAccount(client))
+		read.GET("/accounts/:address/balance", handlers.GetBalance(client))
+		read.GET("/accounts/:address/transactions", handlers.GetAccountTransactions(store))
+		read.GET("/accounts/:address/tokens", handlers.GetAccountTokens(client, store))
+
+		// Token endpoints
+		read.GET("/tokens/:address", handlers.GetToken(client))
+
+		// Log endpoints
+		read.GET("/logs", handlers.QueryLogs(client))
 
 		// Node info endpoints
-		api.GET("/node/status", handlers.GetNodeStatus(client))
-		api.GET("/node/peers", handlers.GetPeers(client))
+		read.GET("/node/status", handlers.GetNodeStatus(client))
+		read.GET("/node/peers", handlers.GetPeers(client))
+		admin.GET("/node/endpoints", handlers.GetEndpoints(client))
+		admin.GET("/node/cache", handlers.GetCacheStats(responses))
+
+		// Streaming endpoints
+		read.GET("/ws", handlers.Watch(streamClient))
 	}
 
 	// Create HTTP server
@@ -108,6 +241,7 @@ func main() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
+		stopIndexer()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -123,3 +257,128 @@ func main() {
 		log.Fatalf("HTTP server error: %v", err)
 	}
 }
+
+// clientOptions reads the RPC client settings from the environment
+func clientOptions() ([]rpc.Option, error) {
+	opts := []rpc.Option{rpc.WithLogger(logging.NewLogger())}
+
+	if v := os.Getenv("BLOCKCHAIN_RPC_RETRIES"); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("invalid BLOCKCHAIN_RPC_RETRIES: %q", v)
+		}
+		opts = append(opts, rpc.WithRetries(retries))
+	}
+
+	if v := os.Getenv("BLOCKCHAIN_RPC_RATE_LIMIT"); v != "" {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("invalid BLOCKCHAIN_RPC_RATE_LIMIT: %q", v)
+		}
+		opts = append(opts, rpc.WithRateLimit(rps, int(math.Ceil(rps))))
+	}
+
+	interval := 15 * time.Second
+	if v := os.Getenv("BLOCKCHAIN_RPC_HEALTH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BLOCKCHAIN_RPC_HEALTH_INTERVAL: %q", v)
+		}
+		interval = d
+	}
+	if interval > 0 {
+		opts = append(opts, rpc.WithHealthCheck(interval))
+	}
+	return opts, nil
+}
+
+// responseCache returns the cache of final responses configured from the
+// environment, or nil when BLOCKCHAIN_API_CACHE_SIZE is 0
+func responseCache(client *rpc.Client) (*cache.Cache, error) {
+	size := cache.DefaultSize
+	if v := os.Getenv("BLOCKCHAIN_API_CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid BLOCKCHAIN_API_CACHE_SIZE: %q", v)
+		}
+		size = n
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	opts := []cache.Option{cache.WithSize(size), cache.WithLogger(logging.NewLogger())}
+	if v := os.Getenv("BLOCKCHAIN_API_CACHE_DEPTH"); v != "" {
+		depth, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BLOCKCHAIN_API_CACHE_DEPTH: %q", v)
+		}
+		opts = append(opts, cache.WithDepth(depth))
+	}
+	if dir := os.Getenv("BLOCKCHAIN_API_CACHE_DIR"); dir != "" {
+		store, err := cache.OpenStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, cache.WithStore(store))
+	}
+	return cache.New(client.BlockNumber, opts...), nil
+}
+
+// rateLimits returns the rate limiting middleware of the read, send and
+// admin route groups, and the limiter of failed authentication attempts per
+// IP address, configured from the environment. auth is nil when disabled.
+func rateLimits() (read, send, admin gin.HandlerFunc, auth *middleware.Limiter, err error) {
+	newLimiter := func(env, def string) (*middleware.Limiter, error) {
+		v := os.Getenv(env)
+		if v == "" {
+			v = def
+		}
+		limit, err := middleware.ParseLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", env, err)
+		}
+		if !limit.Enabled() {
+			return nil, nil
+		}
+		return middleware.NewLimiter(limit), nil
+	}
+	limiter := func(env, def string) (gin.HandlerFunc, error) {
+		l, err := newLimiter(env, def)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return func(c *gin.Context) { c.Next() }, nil
+		}
+		return middleware.RateLimit(l), nil
+	}
+
+	if read, err = limiter("BLOCKCHAIN_API_RATE_LIMIT", "20/s"); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if send, err = limiter("BLOCKCHAIN_API_SEND_RATE_LIMIT", "10/m"); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if admin, err = limiter("BLOCKCHAIN_API_ADMIN_RATE_LIMIT", "60/m"); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if auth, err = newLimiter("BLOCKCHAIN_API_AUTH_RATE_LIMIT", "10/m"); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return read, send, admin, auth, nil
+}
+
+// trustedProxies returns the proxies listed in
+// BLOCKCHAIN_API_TRUSTED_PROXIES, as IP addresses or CIDR ranges separated
+// by commas. None are trusted by default, so clients cannot pick their
+// address with X-Forwarded-For.
+func trustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("BLOCKCHAIN_API_TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
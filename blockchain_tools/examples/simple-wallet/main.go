@@ -1 +1,225 @@
-package simplewallet
\ No newline at end of file
+// Command simple-wallet is a minimal HD wallet built on the keystore,
+// hdwallet and rpc packages. Accounts are derived from a single BIP-39
+// mnemonic and kept encrypted in the same key file format that
+// blockchain-cli uses, so they can also be used there with --from.
+//
+// Usage:
+//
+//	simple-wallet [-keys file] [-rpc url] new
+//	simple-wallet [-keys file] [-rpc url] restore < mnemonic.txt
+//	simple-wallet [-keys file] [-rpc url] derive
+//	simple-wallet [-keys file] [-rpc url] accounts
+//	simple-wallet [-keys file] [-rpc url] send <from> <to> <ether>
+//
+// The password is read from the BLOCKCHAIN_PASSWORD environment variable.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/hdwallet"
+	"github.com/layla-lili/blockchain_tools/pkg/keystore"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+func main() {
+	home, _ := os.UserHomeDir()
+	keys := flag.String("keys", filepath.Join(home, ".blockchain-cli", "keys.json"), "Key file holding the wallet")
+	rpcURL := flag.String("rpc", "http://localhost:8545", "URL of the blockchain RPC endpoint")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] new|restore|derive|accounts|send <from> <to> <ether>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	w, err := openWallet(*keys, *rpcURL)
+	if err == nil {
+		err = w.run(flag.Arg(0), flag.Args()[1:])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// wallet is an HD wallet stored in a key file
+type wallet struct {
+	store    *keystore.Store
+	rpcURL   string
+	password string
+}
+
+func openWallet(keys, rpcURL string) (*wallet, error) {
+	password := os.Getenv("BLOCKCHAIN_PASSWORD")
+	if password == "" {
+		return nil, errors.New("set BLOCKCHAIN_PASSWORD to the wallet password")
+	}
+	store, err := keystore.Open(keys)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet{store: store, rpcURL: rpcURL, password: password}, nil
+}
+
+func (w *wallet) run(command string, args []string) error {
+	switch command {
+	case "new":
+		return w.create()
+	case "restore":
+		return w.restore(os.Stdin)
+	case "derive":
+		return w.derive()
+	case "accounts":
+		return w.accounts()
+	case "send":
+		if len(args) != 3 {
+			return errors.New("usage: send <from> <to> <ether>")
+		}
+		return w.send(args[0], args[1], args[2])
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// create generates a mnemonic and derives the first account from it
+func (w *wallet) create() error {
+	if w.store.HasSeed() {
+		return fmt.Errorf("%s already holds a wallet", w.store.Path())
+	}
+	mnemonic, err := hdwallet.NewMnemonic(12)
+	if err != nil {
+		return err
+	}
+	if err := w.store.SetMnemonic(mnemonic, "", w.password); err != nil {
+		return err
+	}
+	fmt.Println("Mnemonic:", mnemonic)
+	fmt.Println("Write it down: it is the only backup of this wallet.")
+	return w.derive()
+}
+
+// restore reads a mnemonic from r and derives the first account from it
+func (w *wallet) restore(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := w.store.SetMnemonic(strings.TrimSpace(string(data)), "", w.password); err != nil {
+		return err
+	}
+	addr, err := w.store.Derive(hdwallet.DefaultPath, w.password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  %s\n", addr.Hex(), hdwallet.DefaultPath)
+	return nil
+}
+
+// derive adds the account at the next unused index
+func (w *wallet) derive() error {
+	path := w.store.NextPath()
+	addr, err := w.store.Derive(path, w.password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  %s\n", addr.Hex(), path)
+	return nil
+}
+
+// accounts prints every derived account with its balance
+func (w *wallet) accounts() error {
+	client, err := rpc.NewClient(w.rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, addr := range w.store.Accounts() {
+		path := w.store.DerivationPath(addr)
+		if path == "" {
+			continue
+		}
+		balance, err := client.GetAccountBalance(ctx, addr.Hex())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s  %-18s  %s ether\n", addr.Hex(), path, types.FormatUnits(balance, 18))
+	}
+	return nil
+}
+
+// send transfers ether from one of the wallet's accounts and waits for it
+// to be mined
+func (w *wallet) send(from, to, amount string) error {
+	if !common.IsHexAddress(from) || !w.store.Has(common.HexToAddress(from)) {
+		return fmt.Errorf("%s is not an account of this wallet", from)
+	}
+	if !common.IsHexAddress(to) {
+		return fmt.Errorf("invalid recipient %s", to)
+	}
+	value, err := types.ParseUnits(amount, 18)
+	if err != nil {
+		return err
+	}
+
+	client, err := rpc.NewClient(w.rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tx, err := rpc.NewTxBuilder(client).Build(ctx, &types.Transaction{
+		From:  common.HexToAddress(from).Hex(),
+		To:    common.HexToAddress(to).Hex(),
+		Value: value,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	signed, err := w.sign(tx)
+	if err != nil {
+		return err
+	}
+	hash, err := client.SendRawTransaction(ctx, signed)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Sent", hash)
+
+	receipt, err := client.WaitForReceipt(ctx, hash, rpc.WaitOptions{})
+	if err != nil {
+		return err
+	}
+	if receipt.Status == types.StatusFailed {
+		return fmt.Errorf("transaction %s reverted", hash)
+	}
+	fmt.Printf("Mined in block %d\n", receipt.BlockNumber)
+	return nil
+}
+
+func (w *wallet) sign(tx *types.Transaction) (*gethtypes.Transaction, error) {
+	unsigned, err := tx.ToGeth()
+	if err != nil {
+		return nil, err
+	}
+	return w.store.SignTx(common.HexToAddress(tx.From), w.password, unsigned, tx.ChainID)
+}
@@ -0,0 +1,258 @@
+// Package cache keeps API responses for chain data that can no longer
+// change: blocks buried below a finality depth, and the transactions and
+// receipts they hold. Responses are kept in memory in least recently used
+// order, and optionally in a Store on disk that survives restarts.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/pkg/logger"
+)
+
+const (
+	// DefaultSize is the number of responses kept in memory
+	DefaultSize = 10000
+	// DefaultDepth is how many blocks a block needs on top of it before it
+	// is treated as final
+	DefaultDepth = 64
+)
+
+// headRefresh is how long a known chain head is trusted before Final asks
+// the node again
+const headRefresh = time.Second
+
+// Kinds of cached responses
+const (
+	KindBlock       = "block"
+	KindTransaction = "transaction"
+	KindReceipt     = "receipt"
+)
+
+var kinds = []string{KindBlock, KindTransaction, KindReceipt}
+
+// HeadFunc returns the number of the latest block
+type HeadFunc func(ctx context.Context) (uint64, error)
+
+// Entry is an encoded response with its entity tag
+type Entry struct {
+	Body []byte
+	ETag string
+}
+
+// NewEntry returns the entry for body, tagged with a hash of it
+func NewEntry(body []byte) *Entry {
+	sum := sha256.Sum256(body)
+	return &Entry{Body: body, ETag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+}
+
+// Stats counts the lookups of one kind of response
+type Stats struct {
+	Kind string `json:"kind"`
+	// Hits includes DiskHits, the lookups answered by the disk store
+	Hits     uint64 `json:"hits"`
+	DiskHits uint64 `json:"diskHits"`
+	Misses   uint64 `json:"misses"`
+	Entries  int    `json:"entries"`
+}
+
+// Cache holds final responses. A nil Cache holds nothing, so callers need
+// not check whether caching is enabled.
+type Cache struct {
+	size   int
+	depth  uint64
+	head   HeadFunc
+	store  *Store
+	logger logger.Logger
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+	stats map[string]*Stats
+
+	headMu    sync.Mutex
+	knownHead uint64
+	headAt    time.Time
+}
+
+type item struct {
+	kind, key string
+	entry     *Entry
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithSize sets the number of responses kept in memory
+func WithSize(size int) Option {
+	return func(c *Cache) {
+		c.size = size
+	}
+}
+
+// WithDepth sets how many blocks a block needs on top of it to be final
+func WithDepth(depth uint64) Option {
+	return func(c *Cache) {
+		c.depth = depth
+	}
+}
+
+// WithStore keeps responses in store as well, which is asked when memory
+// misses
+func WithStore(store *Store) Option {
+	return func(c *Cache) {
+		c.store = store
+	}
+}
+
+// WithLogger sets the logger that receives errors of the disk store
+func WithLogger(l logger.Logger) Option {
+	return func(c *Cache) {
+		c.logger = l
+	}
+}
+
+// New creates a cache that learns the chain head from head
+func New(head HeadFunc, opts ...Option) *Cache {
+	c := &Cache{
+		size:   DefaultSize,
+		depth:  DefaultDepth,
+		head:   head,
+		logger: logger.Nop,
+		lru:    list.New(),
+		items:  make(map[string]*list.Element),
+		stats:  make(map[string]*Stats, len(kinds)),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, kind := range kinds {
+		c.stats[kind] = &Stats{Kind: kind}
+	}
+	return c
+}
+
+// Final reports whether the block with the given number is deep enough to
+// be cached. The chain head is only fetched again when the one known is too
+// low and more than headRefresh old.
+func (c *Cache) Final(ctx context.Context, number uint64) bool {
+	if c == nil {
+		return false
+	}
+	c.headMu.Lock()
+	defer c.headMu.Unlock()
+
+	if c.knownHead >= number+c.depth {
+		return true
+	}
+	if time.Since(c.headAt) < headRefresh {
+		return false
+	}
+	head, err := c.head(ctx)
+	if err != nil {
+		return false
+	}
+	c.knownHead, c.headAt = head, time.Now()
+	return head >= number+c.depth
+}
+
+// Get returns the response of kind stored under key. Errors of the disk
+// store are logged with the request and trace IDs carried by ctx.
+func (c *Cache) Get(ctx context.Context, kind, key string) (*Entry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	id := kind + ":" + key
+
+	c.mu.Lock()
+	if el, ok := c.items[id]; ok {
+		c.lru.MoveToFront(el)
+		c.stats[kind].Hits++
+		c.mu.Unlock()
+		return el.Value.(*item).entry, true
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		body, err := c.store.Get(kind, key)
+		if err != nil {
+			logger.ErrorContext(ctx, c.logger, "Failed to read cached response", "kind", kind, "key", key, "error", err)
+		}
+		if body != nil {
+			entry := NewEntry(body)
+			c.mu.Lock()
+			c.stats[kind].Hits++
+			c.stats[kind].DiskHits++
+			c.insert(id, &item{kind: kind, key: key, entry: entry})
+			c.mu.Unlock()
+			return entry, true
+		}
+	}
+
+	c.mu.Lock()
+	c.stats[kind].Misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Add stores body as the response of kind under key and returns its entry.
+// Only responses that can never change may be added.
+func (c *Cache) Add(ctx context.Context, kind, key string, body []byte) *Entry {
+	entry := NewEntry(body)
+	if c == nil {
+		return entry
+	}
+
+	c.mu.Lock()
+	c.insert(kind+":"+key, &item{kind: kind, key: key, entry: entry})
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.Put(kind, key, body); err != nil {
+			logger.ErrorContext(ctx, c.logger, "Failed to write cached response", "kind", kind, "key", key, "error", err)
+		}
+	}
+	return entry
+}
+
+// insert puts it in front of the memory cache, evicting the least recently
+// used responses beyond the size. c.mu must be held.
+func (c *Cache) insert(id string, it *item) {
+	if c.size <= 0 {
+		return
+	}
+	if el, ok := c.items[id]; ok {
+		el.Value = it
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[id] = c.lru.PushFront(it)
+	c.stats[it.kind].Entries++
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		old := c.lru.Remove(oldest).(*item)
+		delete(c.items, old.kind+":"+old.key)
+		c.stats[old.kind].Entries--
+	}
+}
+
+// Stats returns the lookup counters of every kind of response
+func (c *Cache) Stats() []Stats {
+	if c == nil {
+		return []Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make([]Stats, 0, len(kinds))
+	for _, kind := range kinds {
+		stats = append(stats, *c.stats[kind])
+	}
+	return stats
+}
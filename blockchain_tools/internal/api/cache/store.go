@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps responses as files in a directory, one subdirectory per kind.
+// Final responses never change, so the store is never pruned.
+type Store struct {
+	dir string
+}
+
+// OpenStore opens the store in dir, creating it when needed
+func OpenStore(dir string) (*Store, error) {
+	for _, kind := range kinds {
+		if err := os.MkdirAll(filepath.Join(dir, kind), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Get returns the response of kind stored under key, or nil when there is
+// none
+func (s *Store) Get(kind, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, nil
+	}
+	body, err := os.ReadFile(s.path(kind, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return body, err
+}
+
+// Put stores body as the response of kind under key. The file is written
+// under a temporary name first, so readers never see part of it.
+func (s *Store) Put(kind, key string, body []byte) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	path := s.path(kind, key)
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (s *Store) path(kind, key string) string {
+	return filepath.Join(s.dir, kind, key+".json")
+}
+
+// validKey reports whether key is a block number or hash, which keeps keys
+// taken from request paths inside the store
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 
     "github.com/gin-gonic/gin"
     "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/types"
 )
 
 func GetAccount(client *rpc.Client) gin.HandlerFunc {
@@ -36,6 +37,6 @@ func GetBalance(client *rpc.Client) gin.HandlerFunc {
             return
         }
 
-        c.JSON(http.StatusOK, gin.H{"address": address, "balance": balance.String()})
+        c.JSON(http.StatusOK, &types.Balance{Address: address, Amount: balance})
     }
 }
\ No newline at end of file
@@ -5,16 +5,23 @@ import (
     "strconv"
 
     "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/internal/api/cache"
     "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
 )
 
-func GetBlock(client *rpc.Client) gin.HandlerFunc {
+// GetBlock returns a block by number. Blocks below the finality depth of
+// responses are served from it.
+func GetBlock(client *rpc.Client, responses *cache.Cache) gin.HandlerFunc {
     return func(c *gin.Context) {
         number, err := strconv.ParseUint(c.Param("number"), 10, 64)
         if err != nil {
             c.JSON(http.StatusBadRequest, gin.H{"error": "invalid block number"})
             return
         }
+        key := strconv.FormatUint(number, 10)
+        if cached(c, responses, cache.KindBlock, key) {
+            return
+        }
 
         block, err := client.GetBlockByHeight(c.Request.Context(), number)
         if err != nil {
@@ -22,7 +29,7 @@ func GetBlock(client *rpc.Client) gin.HandlerFunc {
             return
         }
 
-        c.JSON(http.StatusOK, block)
+        respond(c, responses, cache.KindBlock, key, block, responses.Final(c.Request.Context(), block.Height))
     }
 }
 
@@ -34,6 +41,6 @@ func GetLatestBlock(client *rpc.Client) gin.HandlerFunc {
             return
         }
 
-        c.JSON(http.StatusOK, block)
+        respond(c, nil, cache.KindBlock, "", block, false)
     }
 }
\ No newline at end of file
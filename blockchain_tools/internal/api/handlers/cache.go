@@ -0,0 +1,82 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/internal/api/cache"
+)
+
+// cacheControlFinal lets clients keep responses that can never change
+const cacheControlFinal = "public, max-age=31536000, immutable"
+
+// cached answers the request from responses when it holds kind under key
+func cached(c *gin.Context, responses *cache.Cache, kind, key string) bool {
+    entry, ok := responses.Get(c.Request.Context(), kind, key)
+    if !ok {
+        return false
+    }
+    c.Header("X-Cache", "HIT")
+    writeEntry(c, entry, true)
+    return true
+}
+
+// respond writes v as JSON with an ETag. Final responses are added to
+// responses and may be kept by clients for good; the others, such as the
+// latest block or pending transactions, have to be revalidated.
+func respond(c *gin.Context, responses *cache.Cache, kind, key string, v interface{}, final bool) {
+    body, err := json.Marshal(v)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    var entry *cache.Entry
+    if final {
+        entry = responses.Add(c.Request.Context(), kind, key, body)
+    } else {
+        entry = cache.NewEntry(body)
+    }
+    c.Header("X-Cache", "MISS")
+    writeEntry(c, entry, final)
+}
+
+// writeEntry writes entry, or 304 when the client already has it
+func writeEntry(c *gin.Context, entry *cache.Entry, final bool) {
+    c.Header("ETag", entry.ETag)
+    if final {
+        c.Header("Cache-Control", cacheControlFinal)
+    } else {
+        c.Header("Cache-Control", "no-cache")
+    }
+
+    if etagMatch(c.GetHeader("If-None-Match"), entry.ETag) {
+        c.Status(http.StatusNotModified)
+        return
+    }
+    c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Body)
+}
+
+// etagMatch reports whether an If-None-Match header lists etag. Weak tags
+// match as well, as GET only needs weak comparison.
+func etagMatch(header, etag string) bool {
+    if header == "" {
+        return false
+    }
+    for _, tag := range strings.Split(header, ",") {
+        tag = strings.TrimSpace(tag)
+        if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+            return true
+        }
+    }
+    return false
+}
+
+// GetCacheStats reports the hits and misses of the response cache
+func GetCacheStats(responses *cache.Cache) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.JSON(http.StatusOK, responses.Stats())
+    }
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/pkg/indexer"
+    "github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// transactionPage is a page of indexed transactions
+type transactionPage struct {
+    Transactions []*types.Transaction `json:"transactions"`
+    Limit        int                  `json:"limit"`
+    Offset       int                  `json:"offset"`
+}
+
+// ListTransactions serves GET /transactions from the chain index. The
+// optional address query parameter restricts the list to one account.
+func ListTransactions(store *indexer.Store) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        listIndexedTransactions(c, store, c.Query("address"))
+    }
+}
+
+// GetAccountTransactions serves GET /accounts/:address/transactions from the
+// chain index
+func GetAccountTransactions(store *indexer.Store) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        listIndexedTransactions(c, store, c.Param("address"))
+    }
+}
+
+func listIndexedTransactions(c *gin.Context, store *indexer.Store, address string) {
+    if store == nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "chain index not configured"})
+        return
+    }
+    if address != "" && !common.IsHexAddress(address) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address"})
+        return
+    }
+
+    limit, err := queryInt(c, "limit", indexer.DefaultLimit)
+    if err != nil || limit < 1 || limit > indexer.MaxLimit {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(indexer.MaxLimit)})
+        return
+    }
+    offset, err := queryInt(c, "offset", 0)
+    if err != nil || offset < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+        return
+    }
+
+    txs, err := store.Transactions(c.Request.Context(), indexer.TxQuery{
+        Address: address,
+        Limit:   limit,
+        Offset:  offset,
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, transactionPage{Transactions: txs, Limit: limit, Offset: offset})
+}
+
+func queryInt(c *gin.Context, key string, def int) (int, error) {
+    value := c.Query(key)
+    if value == "" {
+        return def, nil
+    }
+    return strconv.Atoi(value)
+}
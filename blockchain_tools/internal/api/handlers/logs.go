@@ -0,0 +1,100 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/contract"
+)
+
+// maxLogRange is the largest block range a single request may search
+const maxLogRange = 10000
+
+// QueryLogs serves GET /logs. address and topic filter the logs as for the
+// WebSocket stream; fromBlock and toBlock bound the search, defaulting to
+// the latest block. Logs of the events given as event signatures are
+// decoded, and the search is restricted to them unless the first topic is
+// set.
+func QueryLogs(client *rpc.Client) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ctx := c.Request.Context()
+        filter := rpc.LogFilter{
+            Addresses: c.QueryArray("address"),
+            Topics:    rpc.ParseTopics(c.QueryArray("topic")),
+        }
+
+        var decoder *contract.Contract
+        if events := c.QueryArray("event"); len(events) > 0 {
+            var err error
+            if decoder, err = contract.FromEvents(events...); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+                return
+            }
+            if len(filter.Topics) == 0 {
+                filter.Topics = [][]string{nil}
+            }
+            if len(filter.Topics[0]) == 0 {
+                filter.Topics[0] = decoder.EventIDs()
+            }
+        }
+
+        to, err := queryBlock(c, "toBlock")
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid toBlock"})
+            return
+        }
+        if to == nil {
+            head, err := client.BlockNumber(ctx)
+            if err != nil {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+                return
+            }
+            to = &head
+        }
+        from, err := queryBlock(c, "fromBlock")
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fromBlock"})
+            return
+        }
+        if from == nil {
+            from = to
+        }
+        if *from <= *to && *to-*from >= maxLogRange {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "block range exceeds " + strconv.Itoa(maxLogRange) + " blocks"})
+            return
+        }
+        filter.FromBlock, filter.ToBlock = from, to
+
+        logs, err := client.QueryLogs(ctx, filter, rpc.DefaultLogChunkSize)
+        if err != nil {
+            status := watchErrorStatus(err)
+            if errors.Is(err, rpc.ErrInvalidRange) {
+                status = http.StatusBadRequest
+            }
+            c.JSON(status, gin.H{"error": err.Error()})
+            return
+        }
+        if decoder != nil {
+            decoder.DecodeLogs(logs)
+        }
+
+        c.JSON(http.StatusOK, logs)
+    }
+}
+
+// queryBlock reads a block number parameter, where a missing value and
+// latest mean the current head
+func queryBlock(c *gin.Context, key string) (*uint64, error) {
+    value := c.Query(key)
+    if value == "" || value == "latest" {
+        return nil, nil
+    }
+    n, err := strconv.ParseUint(value, 0, 64)
+    if err != nil {
+        return nil, err
+    }
+    return &n, nil
+}
@@ -29,4 +29,10 @@ func GetPeers(client *rpc.Client) gin.HandlerFunc {
 
         c.JSON(http.StatusOK, peers)
     }
-}
\ No newline at end of file
+}
+// GetEndpoints reports the health and request counters of each RPC endpoint
+func GetEndpoints(client *rpc.Client) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.JSON(http.StatusOK, client.Endpoints())
+    }
+}
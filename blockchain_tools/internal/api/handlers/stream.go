@@ -0,0 +1,132 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/gorilla/websocket"
+    "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+const (
+    streamWriteTimeout = 10 * time.Second
+    streamPingInterval = 30 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+    // Cross-origin access is already allowed for the whole API by the CORS middleware
+    CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Watch streams chain events over a WebSocket, one JSON event per message.
+// The events query parameter selects newHeads, pendingTransactions and logs
+// (comma separated or repeated); address and topic filter the logs.
+func Watch(client *rpc.Client) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        opts, err := watchOptionsFromQuery(c)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        ctx, cancel := context.WithCancel(c.Request.Context())
+        defer cancel()
+
+        events := make(chan *types.Event)
+        sub, err := client.Watch(ctx, opts, events)
+        if err != nil {
+            c.JSON(watchErrorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        defer sub.Unsubscribe()
+
+        // The upgrader answers the request itself when the handshake fails
+        conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+        if err != nil {
+            return
+        }
+        defer conn.Close()
+
+        // Clients send nothing; reading detects when they go away
+        go func() {
+            defer cancel()
+            for {
+                if _, _, err := conn.NextReader(); err != nil {
+                    return
+                }
+            }
+        }()
+
+        ping := time.NewTicker(streamPingInterval)
+        defer ping.Stop()
+
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case err, ok := <-sub.Err():
+                if ok {
+                    closeStream(conn, websocket.CloseInternalServerErr, err.Error())
+                } else {
+                    closeStream(conn, websocket.CloseNormalClosure, "")
+                }
+                return
+            case <-ping.C:
+                if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+                    return
+                }
+            case event := <-events:
+                conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+                if err := conn.WriteJSON(event); err != nil {
+                    return
+                }
+            }
+        }
+    }
+}
+
+// watchOptionsFromQuery reads the streams and log filter from the query string
+func watchOptionsFromQuery(c *gin.Context) (rpc.WatchOptions, error) {
+    var opts rpc.WatchOptions
+    for _, value := range c.QueryArray("events") {
+        for _, name := range strings.Split(value, ",") {
+            switch strings.TrimSpace(name) {
+            case "newHeads":
+                opts.NewHeads = true
+            case "pendingTransactions":
+                opts.PendingTransactions = true
+            case "logs":
+                opts.Logs = &rpc.LogFilter{}
+            default:
+                return opts, errors.New("unknown event type: " + name)
+            }
+        }
+    }
+
+    addresses := c.QueryArray("address")
+    topics := c.QueryArray("topic")
+    if len(addresses) > 0 || len(topics) > 0 {
+        opts.Logs = &rpc.LogFilter{
+            Addresses: addresses,
+            Topics:    rpc.ParseTopics(topics),
+        }
+    }
+    return opts, nil
+}
+
+func watchErrorStatus(err error) int {
+    if errors.Is(err, rpc.ErrInvalidAddress) || errors.Is(err, rpc.ErrInvalidHash) {
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
+
+func closeStream(conn *websocket.Conn, code int, text string) {
+    msg := websocket.FormatCloseMessage(code, text)
+    conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteTimeout))
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/indexer"
+    "github.com/layla-lili/blockchain_tools/pkg/token"
+    "github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// GetToken serves GET /tokens/:address with the metadata of an ERC-20 or
+// ERC-721 token
+func GetToken(client *rpc.Client) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        address := c.Param("address")
+        if !common.IsHexAddress(address) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address"})
+            return
+        }
+
+        info, err := token.Info(c.Request.Context(), client, address)
+        if err != nil {
+            status := http.StatusInternalServerError
+            if errors.Is(err, token.ErrNotToken) {
+                status = http.StatusNotFound
+            }
+            c.JSON(status, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, info)
+    }
+}
+
+// GetAccountTokens serves GET /accounts/:address/tokens with the balances
+// the account holds of the tokens given by the token parameter. Without
+// it the tokens are those the account sent or received according to the
+// chain index, skipping contracts that turn out not to be tokens.
+func GetAccountTokens(client *rpc.Client, store *indexer.Store) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ctx := c.Request.Context()
+        address := c.Param("address")
+        if !common.IsHexAddress(address) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address"})
+            return
+        }
+
+        addresses := c.QueryArray("token")
+        discovered := len(addresses) == 0
+        if discovered {
+            if store == nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "token is required when the chain index is not configured"})
+                return
+            }
+            var err error
+            if addresses, err = store.TokenContracts(ctx, address); err != nil {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+                return
+            }
+        }
+
+        balances := make([]*types.TokenBalance, 0, len(addresses))
+        for _, addr := range addresses {
+            if !common.IsHexAddress(addr) {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token address " + addr})
+                return
+            }
+
+            info, err := token.Info(ctx, client, addr)
+            if err != nil {
+                if errors.Is(err, token.ErrNotToken) {
+                    if discovered {
+                        continue
+                    }
+                    c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+                    return
+                }
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+                return
+            }
+
+            balance, err := token.BalanceOf(ctx, client, info, address)
+            if err != nil {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+                return
+            }
+            balances = append(balances, balance)
+        }
+
+        c.JSON(http.StatusOK, balances)
+    }
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/contract"
+)
+
+// TraceTransaction serves GET /transactions/:hash/trace with the call tree
+// of a mined transaction. steps=true adds the executed opcodes, and the
+// custom errors given as error signatures are decoded in reverted calls.
+func TraceTransaction(client *rpc.Client) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var decoder *contract.Contract
+        if errs := c.QueryArray("error"); len(errs) > 0 {
+            var err error
+            if decoder, err = contract.FromErrors(errs...); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+                return
+            }
+        }
+
+        trace, err := client.TraceTransaction(c.Request.Context(), c.Param("hash"), c.Query("steps") == "true")
+        if err != nil {
+            status := watchErrorStatus(err)
+            switch {
+            case errors.Is(err, rpc.ErrNotFound):
+                status = http.StatusNotFound
+            case rpc.IsMethodNotFound(err):
+                status = http.StatusNotImplemented
+            }
+            c.JSON(status, gin.H{"error": err.Error()})
+            return
+        }
+        decoder.DecodeCalls(trace.Call)
+
+        c.JSON(http.StatusOK, trace)
+    }
+}
@@ -1,40 +1,152 @@
 package handlers
 
 import (
+    "encoding/json"
+    "errors"
     "net/http"
+    "strings"
 
     "github.com/gin-gonic/gin"
+    "github.com/layla-lili/blockchain_tools/internal/api/cache"
     "github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+    "github.com/layla-lili/blockchain_tools/pkg/contract"
     "github.com/layla-lili/blockchain_tools/pkg/types"
 )
 
-func GetTransaction(client *rpc.Client) gin.HandlerFunc {
+// GetTransaction returns a transaction by hash. Transactions mined below
+// the finality depth of responses are served from it.
+func GetTransaction(client *rpc.Client, responses *cache.Cache) gin.HandlerFunc {
     return func(c *gin.Context) {
         hash := c.Param("hash")
+        key := strings.ToLower(hash)
+        if cached(c, responses, cache.KindTransaction, key) {
+            return
+        }
+
         tx, err := client.GetTransaction(c.Request.Context(), hash)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
             return
         }
 
-        c.JSON(http.StatusOK, tx)
+        final := tx.BlockHash != "" && responses.Final(c.Request.Context(), tx.BlockNumber)
+        respond(c, responses, cache.KindTransaction, key, tx, final)
     }
 }
 
+// GetTransactionReceipt returns the receipt of a mined transaction, or 404
+// while it is pending. Receipts below the finality depth of responses are
+// served from it.
+func GetTransactionReceipt(client *rpc.Client, responses *cache.Cache) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        hash := c.Param("hash")
+        key := strings.ToLower(hash)
+        if cached(c, responses, cache.KindReceipt, key) {
+            return
+        }
+
+        receipt, err := client.GetTransactionReceipt(c.Request.Context(), hash)
+        if err != nil {
+            status := watchErrorStatus(err)
+            if errors.Is(err, rpc.ErrNotFound) {
+                status = http.StatusNotFound
+            }
+            c.JSON(status, gin.H{"error": err.Error()})
+            return
+        }
+
+        respond(c, responses, cache.KindReceipt, key, receipt, responses.Final(c.Request.Context(), receipt.BlockNumber))
+    }
+}
+
+// sendOverrides are the optional POST /transactions body fields that
+// override the values the builder would pick, and the state overrides
+// applied when simulating
+type sendOverrides struct {
+    Nonce          *uint64              `json:"nonce"`
+    Gas            *uint64              `json:"gas"`
+    Legacy         bool                 `json:"legacy"`
+    StateOverrides types.StateOverrides `json:"stateOverrides"`
+}
+
 func SendTransaction(client *rpc.Client) gin.HandlerFunc {
+    builder := rpc.NewTxBuilder(client)
+
     return func(c *gin.Context) {
+        body, err := c.GetRawData()
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
         var tx types.Transaction
-        if err := c.BindJSON(&tx); err != nil {
+        if err := json.Unmarshal(body, &tx); err != nil {
             c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
             return
         }
 
-        hash, err := client.SendTransaction(c.Request.Context(), &tx)
+        var fields sendOverrides
+        if err := json.Unmarshal(body, &fields); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        overrides := &rpc.TxOverrides{
+            Nonce:                fields.Nonce,
+            Gas:                  fields.Gas,
+            GasPrice:             tx.GasPrice,
+            MaxFeePerGas:         tx.MaxFeePerGas,
+            MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+            Legacy:               fields.Legacy,
+        }
+
+        if c.Query("simulate") == "true" {
+            simulateTransaction(c, builder, &tx, overrides, fields.StateOverrides)
+            return
+        }
+
+        built, err := builder.Build(c.Request.Context(), &tx, overrides)
+        if err != nil {
+            c.JSON(buildErrorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+
+        hash, err := client.SendTransaction(c.Request.Context(), built)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
             return
         }
 
-        c.JSON(http.StatusOK, gin.H{"hash": hash})
+        c.JSON(http.StatusOK, gin.H{"hash": hash, "status": types.StatusPending})
+    }
+}
+
+// simulateTransaction answers a POST /transactions?simulate=true request
+// with the simulation of the built transaction at the block query
+// parameter, latest by default. A transaction that would fail is still a
+// successful simulation and is answered with 200.
+func simulateTransaction(c *gin.Context, builder *rpc.TxBuilder, tx *types.Transaction, overrides *rpc.TxOverrides, state types.StateOverrides) {
+    block := c.DefaultQuery("block", "latest")
+    sim, err := builder.Simulate(c.Request.Context(), tx, overrides, block, state)
+    if err != nil {
+        c.JSON(buildErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    if len(sim.RevertData) > 0 {
+        sim.Revert = contract.DecodeRevert(sim.RevertData)
     }
-}
\ No newline at end of file
+
+    c.JSON(http.StatusOK, sim)
+}
+
+// buildErrorStatus maps a builder error to 400 when the node rejected the
+// transaction itself or the request was malformed, and 500 when the node
+// could not be queried
+func buildErrorStatus(err error) int {
+    var rpcErr *rpc.RPCError
+    if errors.As(err, &rpcErr) || errors.Is(err, rpc.ErrInvalidAddress) ||
+        errors.Is(err, rpc.ErrInvalidBlock) || errors.Is(err, rpc.ErrInvalidOverride) {
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
@@ -0,0 +1,203 @@
+// Package metrics exposes Prometheus metrics of the API server: the
+// requests it serves, the RPC calls it sends upstream, the state of the
+// node and the response cache.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/internal/api/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blockchain"
+
+// nodeTimeout bounds the calls made to the node while scraping
+const nodeTimeout = 2 * time.Second
+
+// Metrics holds the collectors of the API server in their own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	rpcCalls        *prometheus.CounterVec
+	rpcErrors       *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+}
+
+// New creates the metrics, including the Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "requests_total",
+			Help:      "HTTP requests served, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rpcCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "calls_total",
+			Help:      "JSON-RPC requests sent to the node, retries included, by method.",
+		}, []string{"method"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "errors_total",
+			Help:      "JSON-RPC requests that failed, by method.",
+		}, []string{"method"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "call_duration_seconds",
+			Help:      "Time taken by JSON-RPC requests, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.rpcCalls,
+		m.rpcErrors,
+		m.rpcDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records an HTTP request served for route
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// ObserveCall records a JSON-RPC request. It is an rpc.Observer.
+func (m *Metrics) ObserveCall(method string, elapsed time.Duration, err error) {
+	m.rpcCalls.WithLabelValues(method).Inc()
+	m.rpcDuration.WithLabelValues(method).Observe(elapsed.Seconds())
+	if err != nil {
+		m.rpcErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// Node is the part of the RPC client the node metrics are read from
+type Node interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	PeerCount(ctx context.Context) (uint64, error)
+}
+
+// WatchNode reports the chain head and peer count of node, which are asked
+// for on every scrape
+func (m *Metrics) WatchNode(node Node) {
+	m.registry.MustRegister(&nodeCollector{node: node})
+}
+
+// WatchCache reports the lookups of the response cache
+func (m *Metrics) WatchCache(responses *cache.Cache) {
+	m.registry.MustRegister(&cacheCollector{cache: responses})
+}
+
+var (
+	nodeUpDesc = prometheus.NewDesc(
+		"blockchain_node_up",
+		"Whether the node answered the last scrape.",
+		nil, nil)
+	nodeHeadDesc = prometheus.NewDesc(
+		"blockchain_node_head_block",
+		"Number of the latest block of the node.",
+		nil, nil)
+	nodePeersDesc = prometheus.NewDesc(
+		"blockchain_node_peers",
+		"Number of peers of the node.",
+		nil, nil)
+)
+
+type nodeCollector struct {
+	node Node
+}
+
+func (c *nodeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodeUpDesc
+	ch <- nodeHeadDesc
+	ch <- nodePeersDesc
+}
+
+// Collect asks the node for its head and peers. Nodes without net_peerCount
+// only lack the peers metric.
+func (c *nodeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), nodeTimeout)
+	defer cancel()
+
+	head, err := c.node.BlockNumber(ctx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(nodeUpDesc, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(nodeUpDesc, prometheus.GaugeValue, 1)
+	ch <- prometheus.MustNewConstMetric(nodeHeadDesc, prometheus.GaugeValue, float64(head))
+
+	if peers, err := c.node.PeerCount(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(nodePeersDesc, prometheus.GaugeValue, float64(peers))
+	}
+}
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(
+		"blockchain_api_cache_hits_total",
+		"Responses served from the cache, by kind.",
+		[]string{"kind"}, nil)
+	cacheDiskHitsDesc = prometheus.NewDesc(
+		"blockchain_api_cache_disk_hits_total",
+		"Responses served from the disk store of the cache, by kind.",
+		[]string{"kind"}, nil)
+	cacheMissesDesc = prometheus.NewDesc(
+		"blockchain_api_cache_misses_total",
+		"Lookups the cache could not answer, by kind.",
+		[]string{"kind"}, nil)
+	cacheEntriesDesc = prometheus.NewDesc(
+		"blockchain_api_cache_entries",
+		"Responses held in memory, by kind.",
+		[]string{"kind"}, nil)
+)
+
+type cacheCollector struct {
+	cache *cache.Cache
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheDiskHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEntriesDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.cache.Stats() {
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(s.Hits), s.Kind)
+		ch <- prometheus.MustNewConstMetric(cacheDiskHitsDesc, prometheus.CounterValue, float64(s.DiskHits), s.Kind)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(s.Misses), s.Kind)
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(s.Entries), s.Kind)
+	}
+}
@@ -0,0 +1,182 @@
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/layla-lili/blockchain_tools/internal/api/cache"
+	"github.com/layla-lili/blockchain_tools/internal/api/metrics"
+	"github.com/layla-lili/blockchain_tools/internal/api/middleware"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+)
+
+// newNode starts a JSON-RPC server answering eth_blockNumber and, when
+// peers is set, net_peerCount
+func newNode(t *testing.T, peers bool) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch {
+		case req.Method == "eth_blockNumber":
+			resp["result"] = "0x2a"
+		case req.Method == "net_peerCount" && peers:
+			resp["result"] = "0x3"
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// scrape serves m.Handler() and returns the exposition it answers with
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scrape: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	return string(body)
+}
+
+// expectSeries fails unless every line of want is in the exposition
+func expectSeries(t *testing.T, exposition string, want ...string) {
+	t.Helper()
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(exposition, "\n") {
+		lines[line] = true
+	}
+	for _, line := range want {
+		if !lines[line] {
+			t.Errorf("missing series %q", line)
+		}
+	}
+}
+
+func TestRequestSeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+	router := gin.New()
+	router.Use(middleware.Metrics(m))
+	router.GET("/api/v1/blocks/:number", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/v1/blocks/1", "/api/v1/blocks/2", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expectSeries(t, scrape(t, m),
+		`blockchain_api_requests_total{method="GET",route="/api/v1/blocks/:number",status="200"} 2`,
+		`blockchain_api_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`blockchain_api_request_duration_seconds_count{method="GET",route="/api/v1/blocks/:number",status="200"} 2`,
+	)
+}
+
+func TestRPCSeries(t *testing.T) {
+	m := metrics.New()
+	client, err := rpc.NewClient(newNode(t, false), rpc.WithRetries(0), rpc.WithObserver(m.ObserveCall))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.BlockNumber(ctx); err != nil {
+			t.Fatalf("BlockNumber: %v", err)
+		}
+	}
+	if _, err := client.PeerCount(ctx); err == nil {
+		t.Fatal("PeerCount succeeded on a node without net_peerCount")
+	}
+
+	expectSeries(t, scrape(t, m),
+		`blockchain_rpc_calls_total{method="eth_blockNumber"} 2`,
+		`blockchain_rpc_calls_total{method="net_peerCount"} 1`,
+		`blockchain_rpc_errors_total{method="net_peerCount"} 1`,
+		`blockchain_rpc_call_duration_seconds_count{method="eth_blockNumber"} 2`,
+	)
+	if strings.Contains(scrape(t, m), `blockchain_rpc_errors_total{method="eth_blockNumber"}`) {
+		t.Error("successful calls were counted as errors")
+	}
+}
+
+func TestNodeSeries(t *testing.T) {
+	m := metrics.New()
+	client, err := rpc.NewClient(newNode(t, true), rpc.WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	m.WatchNode(client)
+
+	expectSeries(t, scrape(t, m),
+		"blockchain_node_up 1",
+		"blockchain_node_head_block 42",
+		"blockchain_node_peers 3",
+	)
+}
+
+func TestNodeDownSeries(t *testing.T) {
+	// A node that cannot be reached is down and reports nothing else
+	down, err := rpc.NewClient("http://127.0.0.1:1", rpc.WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer down.Close()
+	m := metrics.New()
+	m.WatchNode(down)
+
+	exposition := scrape(t, m)
+	expectSeries(t, exposition, "blockchain_node_up 0")
+	if strings.Contains(exposition, "blockchain_node_head_block") {
+		t.Error("head block reported for a node that is down")
+	}
+}
+
+func TestCacheSeries(t *testing.T) {
+	m := metrics.New()
+	responses := cache.New(func(context.Context) (uint64, error) { return 100, nil })
+	m.WatchCache(responses)
+
+	ctx := context.Background()
+	responses.Add(ctx, cache.KindBlock, "1", []byte(`{"number":1}`))
+	responses.Get(ctx, cache.KindBlock, "1")
+	responses.Get(ctx, cache.KindBlock, "2")
+	responses.Get(ctx, cache.KindReceipt, "0xabc")
+
+	expectSeries(t, scrape(t, m),
+		`blockchain_api_cache_hits_total{kind="block"} 1`,
+		`blockchain_api_cache_misses_total{kind="block"} 1`,
+		`blockchain_api_cache_entries{kind="block"} 1`,
+		`blockchain_api_cache_disk_hits_total{kind="block"} 0`,
+		`blockchain_api_cache_misses_total{kind="receipt"} 1`,
+		`blockchain_api_cache_entries{kind="transaction"} 0`,
+	)
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+)
+
+// API key scopes. Admin implies every other scope.
+const (
+	ScopeRead  = "read"
+	ScopeSend  = "send"
+	ScopeAdmin = "admin"
+)
+
+// hashPrefix marks the hash algorithm of a key in the keys file
+const hashPrefix = "sha256:"
+
+// APIKey is an entry of the API keys file. Only the hash of the key is
+// stored, so the file does not need to be kept secret.
+type APIKey struct {
+	Name   string   `yaml:"name"`
+	Hash   string   `yaml:"hash"`
+	Scopes []string `yaml:"scopes"`
+
+	sum []byte
+}
+
+// HasScope reports whether the key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// APIKeys holds the keys accepted by Auth
+type APIKeys struct {
+	keys []*APIKey
+}
+
+// HashAPIKey returns the hash of key as written in the keys file
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys reads a YAML (or JSON) file listing API keys:
+//
+//	keys:
+//	  - name: dashboard
+//	    hash: sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae
+//	    scopes: [read]
+//
+// Hashes must carry the sha256: prefix, and unknown fields such as a plain
+// key are rejected, so a key pasted into the file is not taken for a hash.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+
+	var file struct {
+		Keys []*APIKey `yaml:"keys"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+	}
+
+	for i, key := range file.Keys {
+		if key.Name == "" {
+			key.Name = fmt.Sprintf("key %d", i+1)
+		}
+		digest, ok := strings.CutPrefix(key.Hash, hashPrefix)
+		sum, err := hex.DecodeString(digest)
+		if !ok || err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: hash must be sha256:<64 hex digits>", key.Name)
+		}
+		key.sum = sum
+		for _, scope := range key.Scopes {
+			if scope != ScopeRead && scope != ScopeSend && scope != ScopeAdmin {
+				return nil, fmt.Errorf("API key %q: unknown scope %q", key.Name, scope)
+			}
+		}
+	}
+	return &APIKeys{keys: file.Keys}, nil
+}
+
+// Len returns the number of keys
+func (k *APIKeys) Len() int {
+	return len(k.keys)
+}
+
+// Lookup returns the entry for key
+func (k *APIKeys) Lookup(key string) (*APIKey, bool) {
+	sum := sha256.Sum256([]byte(key))
+	for _, entry := range k.keys {
+		if subtle.ConstantTimeCompare(entry.sum, sum[:]) == 1 {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Auth rejects requests without a valid API key, given as
+// "Authorization: Bearer <key>" or in the X-API-Key header, with 401. The
+// key is stored in the context for RequireScope.
+func Auth(keys *APIKeys, opts ...AuthOption) gin.HandlerFunc {
+	var o authOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(c *gin.Context) {
+		// Addresses that used up their failures are turned away before the
+		// key is checked, so guessing keys gives no answer until they refill
+		if o.failures != nil {
+			if d := o.failures.Peek(ipKey(c)); !d.Allowed {
+				abortRateLimited(c, d, "too many failed authentication attempts")
+				return
+			}
+		}
+
+		key := requestKey(c.Request)
+		if key == "" {
+			o.fail(c)
+			abortAuth(c, http.StatusUnauthorized, "unauthorized", "missing API key")
+			return
+		}
+		entry, ok := keys.Lookup(key)
+		if !ok {
+			o.fail(c)
+			abortAuth(c, http.StatusUnauthorized, "unauthorized", "invalid API key")
+			return
+		}
+
+		c.Set("APIKey", entry)
+		c.Next()
+	}
+}
+
+// AuthOption configures Auth
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	failures *Limiter
+}
+
+// WithFailureLimit makes each request with a missing or invalid key take a
+// token from the bucket of its IP address in l. Once the bucket is empty,
+// requests from the address get 429 until it refills, whatever key they
+// send.
+func WithFailureLimit(l *Limiter) AuthOption {
+	return func(o *authOptions) {
+		o.failures = l
+	}
+}
+
+// fail counts a failed authentication attempt of the request's address
+func (o *authOptions) fail(c *gin.Context) {
+	if o.failures != nil {
+		o.failures.Allow(ipKey(c))
+	}
+}
+
+// RequireScope rejects requests whose API key lacks scope with 403. It must
+// run after Auth; without Auth in front, every request is let through.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, ok := c.Get("APIKey")
+		if !ok {
+			c.Next()
+			return
+		}
+		if key := v.(*APIKey); !key.HasScope(scope) {
+			abortAuth(c, http.StatusForbidden, "forbidden", fmt.Sprintf("API key %q lacks the %s scope", key.Name, scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestKey returns the API key sent with r
+func requestKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// abortAuth ends the request with the error model of the API
+func abortAuth(c *gin.Context, status int, code, message string) {
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="blockchain-api"`)
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": message, "code": code})
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	readerKey = "reader-secret"
+	senderKey = "sender-secret"
+	adminKey  = "admin-secret"
+)
+
+// writeKeysFile writes a keys file to a temp dir and returns its path
+func writeKeysFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testKeys(t *testing.T) *APIKeys {
+	t.Helper()
+	keys, err := LoadAPIKeys(writeKeysFile(t, `
+keys:
+  - name: dashboard
+    hash: `+HashAPIKey(readerKey)+`
+    scopes: [read]
+  - name: payments
+    hash: `+HashAPIKey(senderKey)+`
+    scopes: [read, send]
+  - name: ops
+    hash: `+HashAPIKey(adminKey)+`
+    scopes: [admin]
+`))
+	if err != nil {
+		t.Fatalf("LoadAPIKeys: %v", err)
+	}
+	return keys
+}
+
+// authRouter serves a route per scope behind Auth, as main does
+func authRouter(keys *APIKeys) *gin.Engine {
+	router := gin.New()
+	api := router.Group("/api/v1", Auth(keys))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	api.GET("/blocks/latest", RequireScope(ScopeRead), ok)
+	api.POST("/transactions", RequireScope(ScopeSend), ok)
+	api.GET("/node/endpoints", RequireScope(ScopeAdmin), ok)
+	return router
+}
+
+func TestAuth(t *testing.T) {
+	router := authRouter(testKeys(t))
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		header  http.Header
+		status  int
+		code    string
+		message string
+	}{
+		{"missing key", "GET", "/api/v1/blocks/latest", nil, 401, "unauthorized", "missing API key"},
+		{"wrong bearer key", "GET", "/api/v1/blocks/latest",
+			http.Header{"Authorization": {"Bearer nope"}}, 401, "unauthorized", "invalid API key"},
+		{"wrong X-API-Key", "GET", "/api/v1/blocks/latest",
+			http.Header{"X-Api-Key": {"nope"}}, 401, "unauthorized", "invalid API key"},
+		{"basic auth is not a key", "GET", "/api/v1/blocks/latest",
+			http.Header{"Authorization": {"Basic " + readerKey}}, 401, "unauthorized", "missing API key"},
+		{"bearer key", "GET", "/api/v1/blocks/latest",
+			http.Header{"Authorization": {"Bearer " + readerKey}}, 200, "", ""},
+		{"lowercase bearer scheme", "GET", "/api/v1/blocks/latest",
+			http.Header{"Authorization": {"bearer " + readerKey}}, 200, "", ""},
+		{"X-API-Key", "GET", "/api/v1/blocks/latest",
+			http.Header{"X-Api-Key": {readerKey}}, 200, "", ""},
+		{"read key cannot send", "POST", "/api/v1/transactions",
+			http.Header{"Authorization": {"Bearer " + readerKey}}, 403, "forbidden", `API key "dashboard" lacks the send scope`},
+		{"send key can send", "POST", "/api/v1/transactions",
+			http.Header{"X-Api-Key": {senderKey}}, 200, "", ""},
+		{"send key is not admin", "GET", "/api/v1/node/endpoints",
+			http.Header{"Authorization": {"Bearer " + senderKey}}, 403, "forbidden", `API key "payments" lacks the admin scope`},
+		{"admin implies every scope", "POST", "/api/v1/transactions",
+			http.Header{"Authorization": {"Bearer " + adminKey}}, 200, "", ""},
+		{"admin route", "GET", "/api/v1/node/endpoints",
+			http.Header{"Authorization": {"Bearer " + adminKey}}, 200, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusOK {
+				return
+			}
+			var body struct {
+				Error string `json:"error"`
+				Code  string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s is not the JSON error model: %v", w.Body, err)
+			}
+			if body.Code != tt.code || body.Error != tt.message {
+				t.Errorf("body = %+v, want code %q and error %q", body, tt.code, tt.message)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if (tt.status == http.StatusUnauthorized) != (challenge != "") {
+				t.Errorf("WWW-Authenticate = %q for status %d", challenge, tt.status)
+			}
+		})
+	}
+}
+
+func TestLoadAPIKeysRejects(t *testing.T) {
+	valid := HashAPIKey(readerKey)
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"plain key as hash", "keys:\n  - name: a\n    hash: " + readerKey + "\n    scopes: [read]\n", "hash must be"},
+		{"hex digest without prefix", "keys:\n  - name: a\n    hash: " + strings.TrimPrefix(valid, hashPrefix) + "\n    scopes: [read]\n", "hash must be"},
+		{"short digest", "keys:\n  - name: a\n    hash: sha256:abcd\n    scopes: [read]\n", "hash must be"},
+		{"other algorithm", "keys:\n  - name: a\n    hash: md5:" + strings.Repeat("0", 32) + "\n    scopes: [read]\n", "hash must be"},
+		{"missing hash", "keys:\n  - name: a\n    scopes: [read]\n", "hash must be"},
+		{"plain key field", "keys:\n  - name: a\n    key: " + readerKey + "\n    hash: " + valid + "\n    scopes: [read]\n", "key"},
+		{"unknown scope", "keys:\n  - name: a\n    hash: " + valid + "\n    scopes: [write]\n", "unknown scope"},
+		{"not YAML", "keys: [", "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadAPIKeys(writeKeysFile(t, tt.content))
+			if err == nil {
+				t.Fatalf("LoadAPIKeys accepted the file with %d keys", keys.Len())
+			}
+			if !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.errText)
+			}
+		})
+	}
+
+	if _, err := LoadAPIKeys(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadAPIKeys accepted a missing file")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	keys := testKeys(t)
+	if keys.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", keys.Len())
+	}
+	entry, ok := keys.Lookup(senderKey)
+	if !ok || entry.Name != "payments" || entry.Hash != HashAPIKey(senderKey) {
+		t.Errorf("Lookup(sender) = %+v, %t", entry, ok)
+	}
+	if _, ok := keys.Lookup(HashAPIKey(senderKey)); ok {
+		t.Error("the hash itself was accepted as a key")
+	}
+}
+
+func TestAuthFailureLimit(t *testing.T) {
+	clock := newFakeClock()
+	// 3 failures per 30 seconds, so a failure is forgiven every 10 seconds
+	failures := NewLimiter(Limit{Rate: 0.1, Burst: 3}, WithClock(clock.Now))
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/limited", Auth(testKeys(t), WithFailureLimit(failures)), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	guess := http.Header{"Authorization": {"Bearer guess"}}
+	valid := http.Header{"Authorization": {"Bearer " + readerKey}}
+
+	// Valid keys do not use up the quota
+	for i := 0; i < 5; i++ {
+		if w := get(router, valid); w.Code != http.StatusOK {
+			t.Fatalf("valid request %d: status = %d", i, w.Code)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if w := get(router, guess); w.Code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: status = %d, want 401", i, w.Code)
+		}
+	}
+
+	// The address is locked out whatever key it sends, so a guess that
+	// happens to be right is not confirmed
+	for _, header := range []http.Header{guess, valid, nil} {
+		w := get(router, header)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("locked out request: status = %d, want 429", w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != "10" {
+			t.Errorf("Retry-After = %q, want 10", got)
+		}
+		if !strings.Contains(w.Body.String(), `"code":"rate_limited"`) {
+			t.Errorf("body = %s", w.Body)
+		}
+	}
+
+	// Other addresses are not affected
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = "192.0.2.2:40000"
+	req.Header.Set("X-API-Key", readerKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("other address: status = %d, want 200", w.Code)
+	}
+
+	clock.Advance(10 * time.Second)
+	if w := get(router, valid); w.Code != http.StatusOK {
+		t.Errorf("after refill: status = %d, want 200", w.Code)
+	}
+	if w := get(router, guess); w.Code != http.StatusUnauthorized {
+		t.Errorf("guess after refill: status = %d, want 401", w.Code)
+	}
+	if w := get(router, valid); w.Code != http.StatusTooManyRequests {
+		t.Errorf("after another failure: status = %d, want 429", w.Code)
+	}
+}
@@ -9,6 +9,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/layla-lili/blockchain_tools/internal/common/logging"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // LogLevel represents the severity of the log entry
@@ -41,6 +45,18 @@ func Logger() gin.HandlerFunc {
 		c.Set("RequestID", requestID)
 		c.Header("X-Request-ID", requestID)
 
+		// Pass the ID on to loggers and to the node through the request
+		// context, and tie it to the span started by Tracing
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		ctx = rpc.ContextWithHeader(ctx, "X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(ctx)
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("request.id", requestID))
+		var traceID string
+		if sc := span.SpanContext(); sc.IsValid() {
+			traceID = sc.TraceID().String()
+		}
+
 		// Start timer
 		start := time.Now()
 
@@ -67,6 +83,7 @@ func Logger() gin.HandlerFunc {
 			Timestamp string `json:"timestamp"`
 			Level     string `json:"level"`
 			RequestID string `json:"request_id"`
+			TraceID   string `json:"trace_id,omitempty"`
 			Method    string `json:"method"`
 			Path      string `json:"path"`
 			Status    int    `json:"status"`
@@ -77,6 +94,7 @@ func Logger() gin.HandlerFunc {
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 			Level:     level.String(),
 			RequestID: requestID,
+			TraceID:   traceID,
 			Method:    c.Request.Method,
 			Path:      c.Request.URL.Path,
 			Status:    status,
@@ -89,6 +107,9 @@ func Logger() gin.HandlerFunc {
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("[%s] ", level))
 		sb.WriteString(fmt.Sprintf("RequestID=%s ", requestID))
+		if traceID != "" {
+			sb.WriteString(fmt.Sprintf("TraceID=%s ", traceID))
+		}
 		sb.WriteString(fmt.Sprintf("Method=%s ", c.Request.Method))
 		sb.WriteString(fmt.Sprintf("Path=%s ", c.Request.URL.Path))
 		sb.WriteString(fmt.Sprintf("Status=%d ", status))
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sweepInterval is how often idle buckets are dropped
+const sweepInterval = time.Minute
+
+// Limit allows Burst requests at once, refilled at Rate requests per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses a limit written as <requests>/<period>, such as 20/s,
+// 600/m or 100/10s. Clients may spend the whole quota of a period at once.
+// "off" and "0" give the zero Limit, which disables limiting.
+func ParseLimit(s string) (Limit, error) {
+	if s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+	count, period, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(count)
+	if !ok || err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>, such as 20/s", s)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad period %q", s, period)
+	}
+	return Limit{Rate: float64(n) / d.Seconds(), Burst: n}, nil
+}
+
+// Enabled reports whether the limit restricts anything
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Clock returns the current time. Replacing it lets the limiter be driven
+// without waiting.
+type Clock func() time.Time
+
+// Limiter keeps a token bucket per client
+type Limiter struct {
+	limit Limit
+	clock Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// LimiterOption configures a Limiter
+type LimiterOption func(*Limiter)
+
+// WithClock sets the clock the limiter refills buckets by
+func WithClock(clock Clock) LimiterOption {
+	return func(l *Limiter) {
+		l.clock = clock
+	}
+}
+
+// NewLimiter creates a limiter applying limit to every client
+func NewLimiter(limit Limit, opts ...LimiterOption) *Limiter {
+	l := &Limiter{
+		limit:   limit,
+		clock:   time.Now,
+		buckets: make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.lastSweep = l.clock()
+	return l
+}
+
+// Decision is the outcome of Allow
+type Decision struct {
+	Allowed bool
+	// Remaining is the number of requests the client may still send at once
+	Remaining int
+	// RetryAfter is how long a rejected client has to wait for a request
+	RetryAfter time.Duration
+	// Reset is how long until the client's quota is full again
+	Reset time.Duration
+}
+
+// Allow takes a token from the bucket of key if one is left
+func (l *Limiter) Allow(key string) Decision {
+	return l.decide(key, true)
+}
+
+// Peek reports whether Allow would let key through, without taking a token
+func (l *Limiter) Peek(key string) Decision {
+	return l.decide(key, false)
+}
+
+func (l *Limiter) decide(key string, take bool) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock()
+	l.sweep(now)
+
+	burst := float64(l.limit.Burst)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*l.limit.Rate)
+		b.last = now
+	}
+
+	d := Decision{Allowed: b.tokens >= 1}
+	if d.Allowed && take {
+		b.tokens--
+	} else {
+		d.RetryAfter = l.refill(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.refill(burst - b.tokens)
+	return d
+}
+
+// refill returns how long it takes to gain tokens
+func (l *Limiter) refill(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.Rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have been refilled completely, which are
+// the same as no bucket
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	full := l.refill(float64(l.limit.Burst))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit rejects clients that exceed the limiter's limit with 429. Clients
+// are told apart by API key when Auth runs first, and by IP address
+// otherwise. Every response carries RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers; rejections add Retry-After.
+func RateLimit(l *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := l.Allow(clientKey(c))
+
+		c.Header("RateLimit-Limit", strconv.Itoa(l.limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+
+		if !d.Allowed {
+			abortRateLimited(c, d, "rate limit exceeded")
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortRateLimited rejects a request with 429 and Retry-After
+func abortRateLimited(c *gin.Context, d Decision, message string) {
+	c.Header("Retry-After", strconv.Itoa(seconds(d.RetryAfter)))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message, "code": "rate_limited"})
+}
+
+// clientKey identifies the client of a request for rate limiting
+func clientKey(c *gin.Context) string {
+	if v, ok := c.Get("APIKey"); ok {
+		return "key:" + v.(*APIKey).Hash
+	}
+	return ipKey(c)
+}
+
+// ipKey identifies the client of a request by IP address
+func ipKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds d up to whole seconds, as the headers are written in
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeClock is a Clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "20/s", want: Limit{Rate: 20, Burst: 20}},
+		{in: "600/m", want: Limit{Rate: 10, Burst: 600}},
+		{in: "100/10s", want: Limit{Rate: 10, Burst: 100}},
+		{in: "off", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "20", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "x/s", wantErr: true},
+		{in: "20/fortnight", wantErr: true},
+		{in: "20/0s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLimit(%q) = %+v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLimit(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterBucket(t *testing.T) {
+	clock := newFakeClock()
+	// 3 requests at once, then one per second
+	l := NewLimiter(Limit{Rate: 1, Burst: 3}, WithClock(clock.Now))
+
+	for i := 2; i >= 0; i-- {
+		d := l.Allow("a")
+		if !d.Allowed || d.Remaining != i {
+			t.Fatalf("Allow = %+v, want allowed with %d remaining", d, i)
+		}
+	}
+
+	d := l.Allow("a")
+	if d.Allowed {
+		t.Fatal("Allow succeeded with an empty bucket")
+	}
+	if d.RetryAfter != time.Second || d.Reset != 3*time.Second {
+		t.Errorf("RetryAfter = %s, Reset = %s, want 1s and 3s", d.RetryAfter, d.Reset)
+	}
+
+	// Half a token is not enough
+	clock.Advance(500 * time.Millisecond)
+	if d := l.Allow("a"); d.Allowed || d.RetryAfter != 500*time.Millisecond {
+		t.Errorf("Allow = %+v, want rejected with 500ms to wait", d)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if d := l.Allow("a"); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("Allow = %+v, want allowed with 0 remaining", d)
+	}
+
+	// Other clients have their own bucket
+	if d := l.Allow("b"); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("Allow(b) = %+v, want allowed with 2 remaining", d)
+	}
+
+	// The bucket never holds more than the burst
+	clock.Advance(time.Hour)
+	if d := l.Allow("a"); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("Allow after an hour = %+v, want allowed with 2 remaining", d)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(Limit{Rate: 1, Burst: 10}, WithClock(clock.Now))
+	l.Allow("idle")
+	clock.Advance(sweepInterval - 5*time.Second)
+	l.Allow("busy")
+
+	// idle is full again and dropped; busy still has a token to regain
+	clock.Advance(5 * time.Second)
+	l.Allow("new")
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("full bucket was not dropped")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("bucket being refilled was dropped")
+	}
+}
+
+// limitedRouter serves GET /limited behind RateLimit(l). Requests carry the
+// API key named in the X-Test-Key header, if any.
+func limitedRouter(l *Limiter) *gin.Engine {
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.Use(func(c *gin.Context) {
+		if name := c.GetHeader("X-Test-Key"); name != "" {
+			c.Set("APIKey", &APIKey{Name: name, Hash: "sha256:" + name})
+		}
+	})
+	router.GET("/limited", RateLimit(l), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router
+}
+
+func get(router http.Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = "192.0.2.1:40000"
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	clock := newFakeClock()
+	// 2 requests per 10 seconds, so a token takes 5 seconds
+	router := limitedRouter(NewLimiter(Limit{Rate: 0.2, Burst: 2}, WithClock(clock.Now)))
+
+	tests := []struct {
+		status     int
+		remaining  string
+		reset      string
+		retryAfter string
+	}{
+		{http.StatusOK, "1", "5", ""},
+		{http.StatusOK, "0", "10", ""},
+		{http.StatusTooManyRequests, "0", "10", "5"},
+	}
+	for i, tt := range tests {
+		w := get(router, nil)
+		if w.Code != tt.status {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, tt.status)
+		}
+		h := w.Header()
+		if got := h.Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want 2", i, got)
+		}
+		if got := h.Get("RateLimit-Remaining"); got != tt.remaining {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %s", i, got, tt.remaining)
+		}
+		if got := h.Get("RateLimit-Reset"); got != tt.reset {
+			t.Errorf("request %d: RateLimit-Reset = %q, want %s", i, got, tt.reset)
+		}
+		if got := h.Get("Retry-After"); got != tt.retryAfter {
+			t.Errorf("request %d: Retry-After = %q, want %q", i, got, tt.retryAfter)
+		}
+	}
+
+	w := get(router, nil)
+	if body := w.Body.String(); body != `{"code":"rate_limited","error":"rate limit exceeded"}` {
+		t.Errorf("body = %s", body)
+	}
+
+	// Retry-After rounds up to whole seconds
+	clock.Advance(4500 * time.Millisecond)
+	if got := get(router, nil).Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if w := get(router, nil); w.Code != http.StatusOK {
+		t.Errorf("status after waiting = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitClientKey(t *testing.T) {
+	clock := newFakeClock()
+	router := limitedRouter(NewLimiter(Limit{Rate: 1, Burst: 1}, WithClock(clock.Now)))
+
+	if w := get(router, nil); w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want 200", w.Code)
+	}
+	// X-Forwarded-For from an untrusted peer does not make a new client
+	spoofed := http.Header{"X-Forwarded-For": {"203.0.113.7"}}
+	if w := get(router, spoofed); w.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For: status = %d, want 429", w.Code)
+	}
+	// Each API key has its own bucket, whatever the address
+	for _, key := range []string{"alice", "bob"} {
+		if w := get(router, http.Header{"X-Test-Key": {key}}); w.Code != http.StatusOK {
+			t.Errorf("key %s: status = %d, want 200", key, w.Code)
+		}
+	}
+	if w := get(router, http.Header{"X-Test-Key": {"alice"}}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("key alice again: status = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitTrustedProxy(t *testing.T) {
+	clock := newFakeClock()
+	router := limitedRouter(NewLimiter(Limit{Rate: 1, Burst: 1}, WithClock(clock.Now)))
+	if err := router.SetTrustedProxies([]string{"192.0.2.0/24"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Behind a trusted proxy, clients are told apart by X-Forwarded-For
+	for _, ip := range []string{"203.0.113.7", "203.0.113.8"} {
+		if w := get(router, http.Header{"X-Forwarded-For": {ip}}); w.Code != http.StatusOK {
+			t.Errorf("client %s: status = %d, want 200", ip, w.Code)
+		}
+	}
+	if w := get(router, http.Header{"X-Forwarded-For": {"203.0.113.7"}}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("client 203.0.113.7 again: status = %d, want 429", w.Code)
+	}
+}
@@ -1,12 +1,19 @@
 package commands
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
+	"strings"
 
-	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
-	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/layla-lili/blockchain_tools/pkg/hdwallet"
+	"github.com/layla-lili/blockchain_tools/pkg/indexer"
+	"github.com/layla-lili/blockchain_tools/pkg/keystore"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -19,67 +26,342 @@ func newAccountCmd() *cobra.Command {
 
 	// Add account subcommands
 	accountCmd.AddCommand(newAccountCreateCmd())
+	accountCmd.AddCommand(newAccountImportCmd())
+	accountCmd.AddCommand(newAccountMnemonicCmd())
+	accountCmd.AddCommand(newAccountDeriveCmd())
 	accountCmd.AddCommand(newAccountListCmd())
 	accountCmd.AddCommand(newAccountBalanceCmd())
+	accountCmd.AddCommand(newAccountHistoryCmd())
 
 	return accountCmd
 }
 
 func newAccountCreateCmd() *cobra.Command {
-	var password string
-
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new account",
+		Long: `Generate a new private key locally and store it encrypted in the key file.
+The key never leaves this machine and can be used with "tx send --from".`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			password, err := readPassword(cmd)
+			if err != nil {
+				return err
+			}
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			store, err := openKeystore(cmd)
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
-			account, err := client.CreateAccount(ctx, password)
+			addr, err := store.NewAccount(password)
 			if err != nil {
 				return fmt.Errorf("failed to create account: %w", err)
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), account)
+			account := &types.Account{
+				Address: addr.Hex(),
+				Balance: new(big.Int),
+			}
+
+			return getContext(cmd).Print(cmd, account)
 		},
 	}
 
-	cmd.Flags().StringVarP(&password, "password", "p", "", "Account password")
-	cmd.MarkFlagRequired("password")
+	addPasswordFlags(cmd)
 
 	return cmd
 }
 
-func newAccountListCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "list",
-		Short: "List all accounts",
+func newAccountImportCmd() *cobra.Command {
+	var (
+		keyFile    string
+		privateKey string
+		mnemonic   string
+		seedPass   string
+		path       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import an existing key into the key file",
+		Long: `Import a key from a Web3 Secret Storage JSON file (--keyfile), a raw
+hex private key (--private-key) or a BIP-39 mnemonic (--mnemonic). The key is
+stored encrypted with the given password.
+
+With --mnemonic the account at --path is derived, and the mnemonic's seed is
+stored encrypted too so that "account derive" can add further accounts. Pass
+"-" to read the mnemonic from standard input rather than the command line.
+A mnemonic created with a BIP-39 passphrase needs --mnemonic-passphrase too,
+or different accounts are derived.`,
+		Example: `  blockchain-cli account import --private-key 0x...
+  echo "word1 word2 ..." | blockchain-cli account import --mnemonic -`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			given := 0
+			for _, v := range []string{keyFile, privateKey, mnemonic} {
+				if v != "" {
+					given++
+				}
+			}
+			if given != 1 {
+				return fmt.Errorf("exactly one of --keyfile, --private-key or --mnemonic is required")
+			}
+			if cmd.Flags().Changed("path") && mnemonic == "" {
+				return fmt.Errorf("--path can only be used with --mnemonic")
+			}
+			if seedPass != "" && mnemonic == "" {
+				return fmt.Errorf("--mnemonic-passphrase can only be used with --mnemonic")
+			}
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			password, err := readPassword(cmd)
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
-			accounts, err := client.ListAccounts(ctx)
+			store, err := openKeystore(cmd)
 			if err != nil {
-				return fmt.Errorf("failed to list accounts: %w", err)
+				return err
+			}
+
+			var addr common.Address
+			if mnemonic != "" {
+				if mnemonic == "-" {
+					if mnemonic, err = readMnemonic(cmd.InOrStdin()); err != nil {
+						return err
+					}
+				}
+				derivationPath, err := hdwallet.ParsePath(path)
+				if err != nil {
+					return err
+				}
+				if err := store.SetMnemonic(mnemonic, seedPass, password); err != nil {
+					if errors.Is(err, keystore.ErrSeedExists) {
+						return fmt.Errorf("%w; use another key_file for a second mnemonic", err)
+					}
+					return fmt.Errorf("failed to import mnemonic: %w", err)
+				}
+				if addr, err = store.Derive(derivationPath, password); err != nil {
+					return fmt.Errorf("failed to derive account: %w", err)
+				}
+			} else if keyFile != "" {
+				keyJSON, err := os.ReadFile(keyFile)
+				if err != nil {
+					return fmt.Errorf("failed to read key file: %w", err)
+				}
+				addr, err = store.ImportJSON(keyJSON, password)
+				if err != nil {
+					return fmt.Errorf("failed to import key: %w", err)
+				}
+			} else {
+				priv, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+				if err != nil {
+					return fmt.Errorf("invalid private key: %w", err)
+				}
+				addr, err = store.Import(priv, password)
+				if err != nil {
+					return fmt.Errorf("failed to import key: %w", err)
+				}
+			}
+
+			account := &types.Account{
+				Address: addr.Hex(),
+				Balance: new(big.Int),
+				Path:    store.DerivationPath(addr),
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), accounts)
+			return getContext(cmd).Print(cmd, account)
 		},
 	}
+
+	cmd.Flags().StringVar(&keyFile, "keyfile", "", "Web3 Secret Storage JSON file to import")
+	cmd.Flags().StringVar(&privateKey, "private-key", "", "Hex encoded private key to import")
+	cmd.Flags().StringVar(&mnemonic, "mnemonic", "", "BIP-39 mnemonic to import, or - to read it from standard input")
+	cmd.Flags().StringVar(&seedPass, "mnemonic-passphrase", "", "BIP-39 passphrase the mnemonic was created with, if any")
+	cmd.Flags().StringVar(&path, "path", hdwallet.DefaultPath.String(), "Derivation path of the account imported from --mnemonic")
+	addPasswordFlags(cmd)
+
+	return cmd
+}
+
+func newAccountMnemonicCmd() *cobra.Command {
+	mnemonicCmd := &cobra.Command{
+		Use:   "mnemonic",
+		Short: "Manage the HD wallet mnemonic of the key file",
+	}
+
+	var words int
+
+	newCmd := &cobra.Command{
+		Use:   "new",
+		Short: "Generate a new mnemonic",
+		Long: `Generate a BIP-39 mnemonic, store its seed encrypted in the key file and
+derive its first account at m/44'/60'/0'/0/0. Further accounts are added with
+"account derive".
+
+The mnemonic is printed once and is not stored: write it down, as it is the
+only way to restore the derived accounts elsewhere. A key file holds a single
+mnemonic.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, err := readPassword(cmd)
+			if err != nil {
+				return err
+			}
+
+			store, err := openKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Failures past this point are outcomes, not usage mistakes
+			cmd.SilenceUsage = true
+
+			if store.HasSeed() {
+				return fmt.Errorf("%s already holds a mnemonic; use another key_file for a second one", store.Path())
+			}
+
+			phrase, err := hdwallet.NewMnemonic(words)
+			if err != nil {
+				return err
+			}
+			if err := store.SetMnemonic(phrase, "", password); err != nil {
+				return fmt.Errorf("failed to store mnemonic: %w", err)
+			}
+			addr, err := store.Derive(hdwallet.DefaultPath, password)
+			if err != nil {
+				return fmt.Errorf("failed to derive account: %w", err)
+			}
+
+			result := &types.Mnemonic{
+				Phrase: phrase,
+				Account: &types.Account{
+					Address: addr.Hex(),
+					Balance: new(big.Int),
+					Path:    store.DerivationPath(addr),
+				},
+			}
+
+			return getContext(cmd).Print(cmd, result)
+		},
+	}
+
+	newCmd.Flags().IntVar(&words, "words", 12, "Number of words: 12, 15, 18, 21 or 24")
+	addPasswordFlags(newCmd)
+
+	mnemonicCmd.AddCommand(newCmd)
+
+	return mnemonicCmd
+}
+
+func newAccountDeriveCmd() *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:   "derive",
+		Short: "Derive a new account from the stored mnemonic",
+		Long: `Derive the account at --path from the mnemonic held in the key file and store
+it encrypted next to the others. Without --path the account following the
+highest index derived so far along m/44'/60'/0'/0/N is used.
+
+The password must be the one the mnemonic was stored with.`,
+		Example: `  blockchain-cli account derive
+  blockchain-cli account derive --path "m/44'/60'/0'/0/5"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, err := readPassword(cmd)
+			if err != nil {
+				return err
+			}
+
+			store, err := openKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Failures past this point are outcomes, not usage mistakes
+			cmd.SilenceUsage = true
+
+			if !store.HasSeed() {
+				return fmt.Errorf("%s holds no mnemonic; create one with \"account mnemonic new\" or \"account import --mnemonic\"", store.Path())
+			}
+
+			derivationPath := store.NextPath()
+			if path != "" {
+				if derivationPath, err = hdwallet.ParsePath(path); err != nil {
+					return err
+				}
+			}
+
+			addr, err := store.Derive(derivationPath, password)
+			if err != nil {
+				return fmt.Errorf("failed to derive account: %w", err)
+			}
+
+			account := &types.Account{
+				Address: addr.Hex(),
+				Balance: new(big.Int),
+				Path:    store.DerivationPath(addr),
+			}
+
+			return getContext(cmd).Print(cmd, account)
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Derivation path, such as m/44'/60'/0'/0/1 (default the next unused index)")
+	addPasswordFlags(cmd)
+
+	return cmd
+}
+
+// readMnemonic reads a mnemonic from r, ignoring surrounding whitespace
+func readMnemonic(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read mnemonic: %w", err)
+	}
+	mnemonic := strings.TrimSpace(string(data))
+	if mnemonic == "" {
+		return "", fmt.Errorf("no mnemonic on standard input")
+	}
+	return mnemonic, nil
+}
+
+func newAccountListCmd() *cobra.Command {
+	var local bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all accounts",
+		Long: `List the accounts managed by the node, or with --local the accounts held
+in the local key file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var accounts []*types.Account
+			if local {
+				store, err := openKeystore(cmd)
+				if err != nil {
+					return err
+				}
+				for _, addr := range store.Accounts() {
+					accounts = append(accounts, &types.Account{Address: addr.Hex(), Path: store.DerivationPath(addr)})
+				}
+			} else {
+				client, err := getContext(cmd).Client()
+				if err != nil {
+					return err
+				}
+
+				accounts, err = client.ListAccounts(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to list accounts: %w", err)
+				}
+			}
+
+			return getContext(cmd).Print(cmd, accounts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&local, "local", false, "List accounts from the local key file")
+
+	return cmd
 }
 
 func newAccountBalanceCmd() *cobra.Command {
@@ -88,12 +370,11 @@ func newAccountBalanceCmd() *cobra.Command {
 		Short: "Get account balance",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			address := args[0]
@@ -102,17 +383,51 @@ func newAccountBalanceCmd() *cobra.Command {
 				return fmt.Errorf("failed to get balance: %w", err)
 			}
 
-			response := struct {
-				Address string   `json:"address"`
-				Balance *big.Int `json:"balance"`
-			}{
+			response := &types.Balance{
 				Address: address,
-				Balance: balance,
+				Amount:  balance,
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), response)
+			return getContext(cmd).Print(cmd, response)
 		},
 	}
 }
+
+func newAccountHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history [address]",
+		Short: "Show the transactions of an account",
+		Long: `Show the transactions sent from, sent to or creating an address, newest
+first. Results are read from the local chain index (see 'index run').`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address := args[0]
+			if !common.IsHexAddress(address) {
+				return fmt.Errorf("invalid address: %s", address)
+			}
+
+			store, err := openIndex(cmd)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			limit, _ := cmd.Flags().GetInt("limit")
+			offset, _ := cmd.Flags().GetInt("offset")
+			txs, err := store.Transactions(cmd.Context(), indexer.TxQuery{
+				Address: address,
+				Limit:   limit,
+				Offset:  offset,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read account history: %w", err)
+			}
+
+			return getContext(cmd).Print(cmd, txs)
+		},
+	}
+
+	addPageFlags(historyCmd)
+
+	return historyCmd
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
+	"github.com/spf13/cobra"
+)
+
+// apiTimeout bounds a request to blockchain-api
+const apiTimeout = 30 * time.Second
+
+// apiPrefix is prepended to API paths not starting with a slash
+const apiPrefix = "/api/v1/"
+
+// errNoAPI is returned when a command needs blockchain-api but no URL is
+// configured
+var errNoAPI = errors.New("no blockchain-api URL configured: set api_url, BLOCKCHAIN_API_URL or --api")
+
+// apiClient sends requests to a blockchain-api server. It is the only
+// client that sends the api_key, so the key never reaches JSON-RPC nodes.
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// apiError is the error body of blockchain-api
+type apiError struct {
+	Status  int
+	Message string `json:"error"`
+	Code    string `json:"code"`
+}
+
+func (e *apiError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("API request failed with %d (%s): %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("API request failed with %d: %s", e.Status, e.Message)
+}
+
+// Do sends a request for path, relative to /api/v1/ unless it starts with
+// a slash, and decodes the JSON response into v
+func (a *apiClient) Do(ctx context.Context, method, path string, body []byte, v interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = apiPrefix + path
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("invalid API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read API response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &apiError{Status: resp.StatusCode}
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid API response: %w", err)
+	}
+	return nil
+}
+
+func newAPICmd() *cobra.Command {
+	var method, data string
+
+	cmd := &cobra.Command{
+		Use:   "api [path]",
+		Short: "Send a request to blockchain-api",
+		Long: `Send a request to the blockchain-api server configured by api_url and print
+the JSON response. Paths are relative to /api/v1/ unless they start with a
+slash. The api_key setting is sent as a bearer token.
+
+Example: blockchain-cli --api http://localhost:8080 api blocks/latest
+         blockchain-cli api node/endpoints --format yaml
+         blockchain-cli api transactions -X POST -d '{"from": "0x...", "to": "0x...", "value": "1000"}'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getContext(cmd).API()
+			if err != nil {
+				return err
+			}
+
+			var body []byte
+			if data != "" {
+				if !json.Valid([]byte(data)) {
+					return fmt.Errorf("--data is not valid JSON")
+				}
+				body = []byte(data)
+			}
+
+			cmd.SilenceUsage = true
+			var out interface{}
+			if err := api.Do(cmd.Context(), strings.ToUpper(method), args[0], body, &out); err != nil {
+				return err
+			}
+
+			// Responses have no table layout, so they are printed as JSON
+			// unless another format is selected
+			if getContext(cmd).Config.Format == "table" {
+				return formatter.NewJSONFormatter().Format(cmd.OutOrStdout(), out)
+			}
+			return getContext(cmd).Print(cmd, out)
+		},
+	}
+
+	cmd.Flags().StringVarP(&method, "method", "X", http.MethodGet, "HTTP method")
+	cmd.Flags().StringVarP(&data, "data", "d", "", "JSON request body")
+	return cmd
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/layla-lili/blockchain_tools/internal/cli/config"
+)
+
+const testAPIKey = "secret-key"
+
+func testContext(cfg *config.Config) *cmdContext {
+	return &cmdContext{Config: cfg}
+}
+
+func TestAPISendsKey(t *testing.T) {
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number": 42}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{APIKey: testAPIKey}
+	cfg.APIURL = srv.URL + "/"
+	api, err := testContext(cfg).API()
+	if err != nil {
+		t.Fatalf("API: %v", err)
+	}
+
+	var block struct{ Number int }
+	if err := api.Do(context.Background(), http.MethodGet, "blocks/latest", nil, &block); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if block.Number != 42 {
+		t.Errorf("Number = %d, want 42", block.Number)
+	}
+	if auth != "Bearer "+testAPIKey {
+		t.Errorf("Authorization = %q, want the bearer api_key", auth)
+	}
+	if path != "/api/v1/blocks/latest" {
+		t.Errorf("path = %q, want /api/v1/blocks/latest", path)
+	}
+
+	// Absolute paths are sent as they are
+	if err := api.Do(context.Background(), http.MethodGet, "/openapi.json", nil, nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if path != "/openapi.json" {
+		t.Errorf("path = %q, want /openapi.json", path)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": "API key lacks the admin scope", "code": "forbidden"}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.APIURL = srv.URL
+	api, err := testContext(cfg).API()
+	if err != nil {
+		t.Fatalf("API: %v", err)
+	}
+	err = api.Do(context.Background(), http.MethodGet, "node/endpoints", nil, nil)
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden || apiErr.Code != "forbidden" {
+		t.Fatalf("Do error = %v, want a 403 forbidden apiError", err)
+	}
+}
+
+func TestAPIRequiresURL(t *testing.T) {
+	if _, err := testContext(&config.Config{APIKey: testAPIKey}).API(); !errors.Is(err, errNoAPI) {
+		t.Errorf("API error = %v, want errNoAPI", err)
+	}
+}
+
+// The api_key is for blockchain-api only and must never reach a node
+func TestRPCClientSendsNoKey(t *testing.T) {
+	var mu sync.Mutex
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		auths = append(auths, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x2a"})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{APIKey: testAPIKey}
+	cfg.RpcURL = srv.URL
+	cfg.APIURL = "http://api.invalid"
+	ctx := testContext(cfg)
+	defer ctx.Close()
+
+	client, err := ctx.Client()
+	if err != nil {
+		t.Fatalf("Client: %v", err)
+	}
+	if _, err := client.BlockNumber(context.Background()); err != nil {
+		t.Fatalf("BlockNumber: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(auths) == 0 {
+		t.Fatal("node received no request")
+	}
+	for _, auth := range auths {
+		if auth != "" {
+			t.Errorf("node received Authorization %q", auth)
+		}
+	}
+}
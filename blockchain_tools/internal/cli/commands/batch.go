@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/layla-lili/blockchain_tools/pkg/batch"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/token"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// batchUnits maps --unit to the decimals of ether amounts
+var batchUnits = map[string]uint8{
+	"wei":   0,
+	"gwei":  9,
+	"ether": 18,
+}
+
+func newBatchTransactionCmd() *cobra.Command {
+	var (
+		file          string
+		from          string
+		tokenAddress  string
+		unit          string
+		raw           bool
+		concurrency   int
+		resultsPath   string
+		journalPath   string
+		check         bool
+		gasMultiplier float64
+		wait          bool
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Send a batch of payments from a CSV or JSON file",
+		Long: `Send one transaction per row of a CSV or JSON file, all from the same
+local account.
+
+CSV files need a header row with a to column, a value (or amount) column and
+optionally a data column; other columns are ignored. JSON files hold an array
+of objects with the same fields. Values are in wei unless --unit is set. With
+--token every row is an ERC-20 transfer instead, with amounts in whole tokens
+unless --raw is set.
+
+Every row is validated, and the sender's balance checked against the total,
+before anything is sent; --check stops there. Rows then get sequential
+nonces, are signed in file order and are broadcast by --concurrency workers.
+The state of every row is written to a results file (CSV, or JSON when its
+name ends in .json) and printed.
+
+Each signed transaction is recorded in a journal next to the file before it
+is broadcast. Running the same command again after an interruption or
+failure resumes the batch: sent rows are skipped and failed rows are retried,
+reusing the nonces they had reserved. Failed rows may be corrected in the
+file before retrying; rows that were already signed must stay as they are.
+Delete the journal to start over.`,
+		Example: `  blockchain-cli tx batch --file payouts.csv --from 0x... --unit ether --wait
+  blockchain-cli tx batch --file airdrop.json --from 0x... --token 0x... --check`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("nonce") {
+				return fmt.Errorf("--nonce cannot be used with tx batch; rows are given sequential nonces")
+			}
+			if _, ok := batchUnits[unit]; !ok {
+				return fmt.Errorf("invalid --unit %q: expected wei, gwei or ether", unit)
+			}
+			if tokenAddress != "" && cmd.Flags().Changed("unit") {
+				return fmt.Errorf("--unit cannot be used with --token; use --raw for amounts in the token's smallest unit")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			if from, err = requireFrom(cmd, from); err != nil {
+				return err
+			}
+			store, addr, local, err := localAccount(cmd, from)
+			if err != nil {
+				return err
+			}
+			if !local {
+				return fmt.Errorf("%s is not in the local key file; batches are signed locally so they can be resumed", from)
+			}
+
+			var info *types.Token
+			decimals := batchUnits[unit]
+			if tokenAddress != "" {
+				if info, err = token.Info(ctx, client, tokenAddress); err != nil {
+					return err
+				}
+				if info.Standard != types.StandardERC20 {
+					return fmt.Errorf("%s is an %s token; only ERC-20 tokens can be sent in a batch", info.Address, info.Standard)
+				}
+				if !raw {
+					decimals = info.Decimals
+				}
+			}
+
+			rows, err := batch.ReadFile(file, decimals)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("invalid batch file %s: %w", file, err)
+			}
+			if check {
+				if err := checkBatchFunds(ctx, client, info, from, rows, decimals); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				cmd.Printf("%d rows are valid, paying %s in total\n", len(rows), types.FormatUnits(batch.Total(rows), decimals))
+				return nil
+			}
+
+			overrides, err := txOverridesFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			password, err := readPassword(cmd)
+			if err != nil {
+				return err
+			}
+			// Unlock once: decrypting the key for every row is slow
+			key, err := store.Unlock(addr, password)
+			if err != nil {
+				return err
+			}
+			sign := func(tx *types.Transaction) (*gethtypes.Transaction, error) {
+				unsigned, err := tx.ToGeth()
+				if err != nil {
+					return nil, err
+				}
+				return gethtypes.SignTx(unsigned, gethtypes.LatestSignerForChainID(tx.ChainID), key)
+			}
+
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get chain ID: %w", err)
+			}
+			if err := checkChainID(cmd, chainID); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			header := batch.Header{From: addr.Hex(), ChainID: chainID.String()}
+			if info != nil {
+				header.Token = info.Address
+			}
+			if journalPath == "" {
+				journalPath = file + ".journal"
+			}
+			journal, err := batch.OpenJournal(journalPath, header)
+			if errors.Is(err, batch.ErrJournalMismatch) {
+				return fmt.Errorf("journal %s was started for a different sender, chain or token; delete it to start over", journalPath)
+			}
+			if err != nil {
+				return err
+			}
+			defer journal.Close()
+			records := journal.Records()
+			if len(records) > 0 {
+				cmd.PrintErrf("Resuming batch: %d of %d rows recorded in %s\n", len(records), len(rows), journalPath)
+			}
+			if err := checkBatchFunds(ctx, client, info, from, batch.Unsent(rows, records), decimals); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			opts := []batch.Option{
+				batch.WithConcurrency(concurrency),
+				batch.WithOverrides(overrides),
+				batch.WithProgress(func(res *types.BatchResult) {
+					cmd.PrintErrf("row %d: %s %s\n", res.Row, res.Status, batchDetail(res))
+				}),
+			}
+			if info != nil {
+				opts = append(opts, batch.WithToken(info.Address))
+			}
+			if wait {
+				opts = append(opts, batch.WithWait(confirmations, timeout))
+			}
+			builder := rpc.NewTxBuilder(client, rpc.WithGasMultiplier(gasMultiplierFromFlags(cmd, gasMultiplier)))
+			sender := batch.NewSender(client, builder, journal, addr, sign, opts...)
+
+			// Failures past this point are outcomes, not usage mistakes
+			cmd.SilenceUsage = true
+
+			results, runErr := sender.Run(ctx, rows)
+			if errors.Is(runErr, batch.ErrJournalMismatch) {
+				return fmt.Errorf("%w; restore the row or delete %s to start over", runErr, journalPath)
+			}
+			if results == nil {
+				return runErr
+			}
+
+			if resultsPath == "" {
+				ext := filepath.Ext(file)
+				resultsPath = strings.TrimSuffix(file, ext) + ".results" + ext
+			}
+			if err := batch.WriteResults(resultsPath, results); err != nil {
+				return fmt.Errorf("failed to write results: %w", err)
+			}
+
+			if err := getContext(cmd).Print(cmd, results); err != nil {
+				return err
+			}
+
+			switch {
+			case errors.Is(runErr, context.Canceled) && ctx.Err() != nil:
+				return fmt.Errorf("interrupted; run the same command again to resume (results written to %s)", resultsPath)
+			case runErr != nil:
+				return fmt.Errorf("batch stopped: %w (results written to %s)", runErr, resultsPath)
+			}
+			if failed := countUnfinished(results); failed > 0 {
+				return fmt.Errorf("%d of %d rows did not succeed; fix them and run the same command again to retry (results written to %s)",
+					failed, len(results), resultsPath)
+			}
+			cmd.PrintErrf("All %d rows sent; results written to %s\n", len(results), resultsPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "CSV or JSON file of payments")
+	cmd.Flags().StringVar(&from, "from", "", "Sender address, held in the local key file (default default_account)")
+	cmd.Flags().StringVar(&tokenAddress, "token", "", "Send ERC-20 transfers of this token instead of ether")
+	cmd.Flags().StringVar(&unit, "unit", "wei", "Unit of ether values: wei, gwei or ether")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Token amounts are in the token's smallest unit")
+	cmd.Flags().IntVar(&concurrency, "concurrency", batch.DefaultConcurrency, "Number of rows built, sent or waited for at the same time")
+	cmd.Flags().StringVar(&resultsPath, "results", "", "Results file (default <file>.results.<ext>)")
+	cmd.Flags().StringVar(&journalPath, "journal", "", "Journal file used to resume the batch (default <file>.journal)")
+	cmd.Flags().BoolVar(&check, "check", false, "Only validate the file and the sender's balance")
+	cmd.Flags().Float64Var(&gasMultiplier, "gas-multiplier", rpc.DefaultGasMultiplier, "Safety multiplier applied to the estimated gas limit")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for every row to be mined")
+	addWaitFlags(cmd, &confirmations, &timeout)
+	addTxOverrideFlags(cmd)
+	addPasswordFlags(cmd)
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// checkBatchFunds fails when the sender cannot pay the total of rows, in
+// ether or in info's token. Gas is not included.
+func checkBatchFunds(ctx context.Context, client *rpc.Client, info *types.Token, from string, rows []*batch.Row, decimals uint8) error {
+	total := batch.Total(rows)
+
+	var balance *big.Int
+	if info != nil {
+		b, err := token.BalanceOf(ctx, client, info, from)
+		if err != nil {
+			return err
+		}
+		balance = b.Balance
+	} else {
+		b, err := client.GetAccountBalance(ctx, from)
+		if err != nil {
+			return fmt.Errorf("failed to get balance: %w", err)
+		}
+		balance = b
+	}
+
+	if balance.Cmp(total) < 0 {
+		return fmt.Errorf("insufficient funds: the batch pays %s but %s holds %s",
+			types.FormatUnits(total, decimals), from, types.FormatUnits(balance, decimals))
+	}
+	return nil
+}
+
+// batchDetail describes a row's new state in progress messages
+func batchDetail(res *types.BatchResult) string {
+	switch {
+	case res.Error != "":
+		return res.Error
+	case res.Nonce != nil:
+		return fmt.Sprintf("nonce %d %s", *res.Nonce, res.Hash)
+	}
+	return res.Hash
+}
+
+// countUnfinished returns how many rows were not sent or mined successfully
+func countUnfinished(results []*types.BatchResult) int {
+	n := 0
+	for _, res := range results {
+		if res.Status != batch.StatusSent && res.Status != batch.StatusConfirmed {
+			n++
+		}
+	}
+	return n
+}
@@ -2,9 +2,11 @@
 package commands
 
 import (
-	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
 	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
@@ -23,6 +25,7 @@ func newBlockCmd() *cobra.Command {
 	blockCmd.AddCommand(newGetBlockCmd())
 	blockCmd.AddCommand(newGetBlocksCmd())
 	blockCmd.AddCommand(newGetBlockCountCmd())
+	blockCmd.AddCommand(newWatchBlocksCmd())
 
 	return blockCmd
 }
@@ -33,15 +36,11 @@ func newGetBlockCmd() *cobra.Command {
 		Short: "Get a single block by hash or height",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			// Get RPC URL from flag or config
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-
-			// Create client
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			// Parse argument (could be hash or height)
@@ -61,12 +60,8 @@ func newGetBlockCmd() *cobra.Command {
 				return fmt.Errorf("failed to get block: %w", err)
 			}
 
-			// Get output format
-			format, _ := cmd.Flags().GetString("format")
-
 			// Format output
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), block)
+			return getContext(cmd).Print(cmd, block)
 		},
 	}
 
@@ -74,22 +69,22 @@ func newGetBlockCmd() *cobra.Command {
 }
 
 func newGetBlocksCmd() *cobra.Command {
+	var opts rpc.RangeOptions
+
 	getBlocksCmd := &cobra.Command{
 		Use:   "list [start_height] [end_height]",
 		Short: "List a range of blocks",
 		Long: `List multiple blocks from the blockchain by specifying a start and end height.
+Blocks are fetched in JSON-RPC batches, several batches at a time, and printed
+in order as they arrive.
 Example: blockchain-cli block list 1000 1010`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-
-			// Get RPC URL from flag
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
+			ctx := cmd.Context()
 
-			// Create client
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			// Parse start and end heights
@@ -107,30 +102,28 @@ Example: blockchain-cli block list 1000 1010`,
 			if endHeight < startHeight {
 				return fmt.Errorf("end height must be greater than or equal to start height")
 			}
-
-			// Safety limit
-			const maxBlocks = 100
-			if endHeight-startHeight > maxBlocks {
-				return fmt.Errorf("maximum range of %d blocks exceeded", maxBlocks)
+			if opts.BatchSize <= 0 || opts.Workers <= 0 {
+				return fmt.Errorf("--batch-size and --workers must be positive")
 			}
 
-			// Fetch blocks
-			var blocks []*types.Block
-			for height := startHeight; height <= endHeight; height++ {
-				block, err := client.GetBlockByHeight(ctx, height)
-				if err != nil {
-					return fmt.Errorf("failed to get block at height %d: %w", height, err)
-				}
-				blocks = append(blocks, block)
+			// Stream blocks to the output as they arrive
+			out := getContext(cmd).Stream(cmd)
+			err = client.GetBlockRange(ctx, startHeight, endHeight, opts, func(block *types.Block) error {
+				return out.Write(block)
+			})
+			if cerr := out.Close(); err == nil {
+				err = cerr
 			}
-
-			// Format output
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), blocks)
+			if err != nil {
+				return fmt.Errorf("failed to get blocks: %w", err)
+			}
+			return nil
 		},
 	}
 
+	getBlocksCmd.Flags().IntVar(&opts.BatchSize, "batch-size", rpc.DefaultBatchSize, "Blocks requested per JSON-RPC batch")
+	getBlocksCmd.Flags().IntVar(&opts.Workers, "workers", rpc.DefaultWorkers, "Batches fetched concurrently")
+
 	return getBlocksCmd
 }
 
@@ -141,15 +134,11 @@ func newGetBlockCountCmd() *cobra.Command {
 		Long:  `Retrieve the current block height (total number of blocks) in the blockchain.`,
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-
-			// Get RPC URL from flag
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
+			ctx := cmd.Context()
 
-			// Create client
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			// Get latest block to determine height
@@ -158,10 +147,6 @@ func newGetBlockCountCmd() *cobra.Command {
 				return fmt.Errorf("failed to get latest block: %w", err)
 			}
 
-			// Format output
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-
 			// Create a simple response structure
 			response := struct {
 				BlockHeight uint64 `json:"blockHeight"`
@@ -171,9 +156,99 @@ func newGetBlockCountCmd() *cobra.Command {
 				Timestamp:   block.Timestamp,
 			}
 
-			return fmt.Format(cmd.OutOrStdout(), response)
+			// Format output
+			return getContext(cmd).Print(cmd, response)
 		},
 	}
 
 	return getBlockCountCmd
 }
+
+func newWatchBlocksCmd() *cobra.Command {
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Stream new blocks, pending transactions and logs",
+		Long: `Stream chain events as they happen until interrupted.
+
+New heads are watched by default. Events are received through eth_subscribe
+when the endpoint is a WebSocket URL and polled otherwise. Use --format jsonl
+(or json) for one JSON object per line.
+
+Example: blockchain-cli block watch --pending --address 0x... --topic 0xddf2...`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// Subscriptions need a WebSocket endpoint, so prefer --ws-url
+			rpcURL := wsEndpoint(cmd)
+			interval, _ := cmd.Flags().GetDuration("poll-interval")
+
+			client, err := rpc.NewClient(rpcURL, rpc.WithPollInterval(interval))
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			defer client.Close()
+
+			events := make(chan *types.Event)
+			sub, err := client.Watch(ctx, watchOptionsFromFlags(cmd), events)
+			if err != nil {
+				return fmt.Errorf("failed to watch: %w", err)
+			}
+			defer sub.Unsubscribe()
+
+			format := getContext(cmd).Config.Format
+			if format == "json" {
+				format = "jsonl"
+			}
+			out := formatter.GetFormatter(format)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case err, ok := <-sub.Err():
+					if ok {
+						return fmt.Errorf("watch failed: %w", err)
+					}
+					return nil
+				case event := <-events:
+					if format == "yaml" {
+						fmt.Fprintln(cmd.OutOrStdout(), "---")
+					}
+					if err := out.Format(cmd.OutOrStdout(), event); err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+
+	watchCmd.Flags().Bool("heads", false, "Watch new blocks (default when nothing else is selected)")
+	watchCmd.Flags().Bool("pending", false, "Watch transactions entering the pool")
+	watchCmd.Flags().Bool("logs", false, "Watch contract logs")
+	watchCmd.Flags().StringSlice("address", nil, "Only logs from these contracts (implies --logs)")
+	watchCmd.Flags().StringArray("topic", nil, "Topic filter by position, repeatable; comma separates alternatives, empty or * matches any (implies --logs)")
+	watchCmd.Flags().String("ws-url", "", "WebSocket endpoint used for subscriptions (default ws_url, then --rpc-url)")
+	watchCmd.Flags().Duration("poll-interval", rpc.DefaultPollInterval, "Polling interval when the endpoint cannot push events")
+
+	return watchCmd
+}
+
+// watchOptionsFromFlags selects the streams requested on the command line
+func watchOptionsFromFlags(cmd *cobra.Command) rpc.WatchOptions {
+	var opts rpc.WatchOptions
+	opts.NewHeads, _ = cmd.Flags().GetBool("heads")
+	opts.PendingTransactions, _ = cmd.Flags().GetBool("pending")
+
+	logs, _ := cmd.Flags().GetBool("logs")
+	addresses, _ := cmd.Flags().GetStringSlice("address")
+	topics, _ := cmd.Flags().GetStringArray("topic")
+	if logs || len(addresses) > 0 || len(topics) > 0 {
+		opts.Logs = &rpc.LogFilter{
+			Addresses: addresses,
+			Topics:    rpc.ParseTopics(topics),
+		}
+	}
+	return opts
+}
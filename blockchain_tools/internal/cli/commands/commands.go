@@ -21,6 +21,14 @@ var rootCmd = &cobra.Command{
 	Long: `A command line interface for interacting with the blockchain MVP.
 This tool provides commands for querying the blockchain state, sending transactions,
 managing accounts, and monitoring the network.`,
+	// Every command shares the context set up here, so settings are
+	// resolved once and the RPC client is reused
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupContext(cmd, true)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		getContext(cmd).Close()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -34,24 +42,32 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.blockchain-cli.yaml)")
-	rootCmd.PersistentFlags().String("rpc-url", "http://localhost:8545", "URL of the blockchain RPC endpoint")
-	rootCmd.PersistentFlags().String("format", "table", "Output format (table, json, yaml)")
+	rootCmd.PersistentFlags().String("rpc-url", "", "URL of the blockchain RPC endpoint (default rpc_url of the network, or http://localhost:8545)")
+	rootCmd.PersistentFlags().String("api", "", "URL of the blockchain-api server, such as http://localhost:8080 (default api_url of the network)")
+	rootCmd.PersistentFlags().String("network", "", "Network profile from the config file (default network)")
+	rootCmd.PersistentFlags().String("format", "table", "Output format (table, json, jsonl, yaml)")
 	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug logging")
 
+	config.BindFlags(rootCmd.PersistentFlags())
+
 	// Add subcommands
 	rootCmd.AddCommand(newBlockCmd())
 	rootCmd.AddCommand(newTransactionCmd())
 	rootCmd.AddCommand(newAccountCmd())
+	rootCmd.AddCommand(newContractCmd())
+	rootCmd.AddCommand(newLogsCmd())
+	rootCmd.AddCommand(newTokenCmd())
+	rootCmd.AddCommand(newNFTCmd())
+	rootCmd.AddCommand(newSignCmd())
+	rootCmd.AddCommand(newVerifyCmd())
 	rootCmd.AddCommand(newNodeCmd())
+	rootCmd.AddCommand(newIndexCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newAPICmd())
 	rootCmd.AddCommand(newVersionCmd())
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	config.InitConfig(cfgFile)
-	
-	// Set up logging based on debug flag
-	if debug, _ := rootCmd.PersistentFlags().GetBool("debug"); debug {
-		logging.SetLevel("debug")
-	}
 }
\ No newline at end of file
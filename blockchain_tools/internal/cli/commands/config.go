@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"github.com/layla-lili/blockchain_tools/internal/cli/config"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage network profiles and settings",
+		Long: `Manage the settings in the config file (default ~/.blockchain-cli.yaml).
+
+Network profiles are kept under networks, each with its own RPC and WebSocket
+URLs, chain ID, explorer URL, default account and gas policy:
+
+  network: local
+  networks:
+    local:
+      rpc_url: http://localhost:8545
+      chain_id: 31337
+    staging:
+      rpc_url: https://rpc.staging.example.com
+      chain_id: 11155111
+      explorer_url: https://sepolia.etherscan.io
+      default_account: 0x...
+      gas:
+        multiplier: 1.5
+        max_priority_fee: "2000000000"
+
+The active profile is chosen with --network, BLOCKCHAIN_NETWORK or network.
+Its settings override the top-level ones; explicit flags and environment
+variables override both. Transactions and batches are not signed when the
+node's chain ID differs from the profile's chain_id.`,
+		// The active network may be unknown here, for example when it is
+		// being fixed with config use
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setupContext(cmd, false)
+		},
+	}
+
+	configCmd.AddCommand(newConfigUseCmd())
+	configCmd.AddCommand(newConfigListCmd())
+	configCmd.AddCommand(newConfigShowCmd())
+	configCmd.AddCommand(newConfigSetCmd())
+
+	return configCmd
+}
+
+func newConfigUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <network>",
+		Short: "Make a network profile the default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := getContext(cmd).Config.Profile(args[0]); err != nil {
+				return err
+			}
+			if err := config.Set("network", args[0]); err != nil {
+				return err
+			}
+			cmd.Printf("Using network %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newConfigListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List network profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := getContext(cmd).Config
+
+			networks := make([]*types.Network, 0, len(cfg.Networks))
+			for _, name := range cfg.NetworkNames() {
+				networks = append(networks, networkProfile(name, cfg.Networks[name], name == cfg.Active))
+			}
+
+			return getContext(cmd).Print(cmd, networks)
+		},
+	}
+}
+
+func newConfigShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [network]",
+		Short: "Show the settings in effect, or those of a network profile",
+		Long: `Show the network settings in effect, after applying the active profile,
+environment variables and --rpc-url. With a name, show that profile as it is
+written in the config file.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := getContext(cmd).Config
+
+			var network *types.Network
+			if len(args) == 1 {
+				n, err := cfg.Profile(args[0])
+				if err != nil {
+					return err
+				}
+				network = networkProfile(args[0], n, args[0] == cfg.Active)
+			} else {
+				network = networkProfile(cfg.Active, &cfg.Network, true)
+			}
+
+			return getContext(cmd).Print(cmd, network)
+		},
+	}
+}
+
+func newConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a setting to the config file",
+		Long: `Write a setting to the config file. Keys are top-level settings such as
+rpc_url, key_file or network, or profile settings written as
+networks.<name>.<setting>. Profile settings are rpc_url, ws_url, chain_id,
+explorer_url, default_account, gas.multiplier, gas.max_fee,
+gas.max_priority_fee and gas.legacy. Setting a key of a new profile creates
+it.`,
+		Example: `  blockchain-cli config set networks.staging.rpc_url https://rpc.staging.example.com
+  blockchain-cli config set networks.staging.chain_id 11155111
+  blockchain-cli config set networks.staging.gas.multiplier 1.5`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			cmd.Printf("Set %s in %s\n", args[0], config.ConfigFile())
+			return nil
+		},
+	}
+}
+
+// networkProfile converts a config profile for output
+func networkProfile(name string, n *config.Network, active bool) *types.Network {
+	if name == "" {
+		name = "(none)"
+	}
+	return &types.Network{
+		Name:           name,
+		Active:         active,
+		RPCURL:         n.RpcURL,
+		WSURL:          n.WsURL,
+		APIURL:         n.APIURL,
+		ChainID:        n.ChainID,
+		ExplorerURL:    n.ExplorerURL,
+		DefaultAccount: n.DefaultAccount,
+		GasMultiplier:  n.Gas.Multiplier,
+		MaxFee:         n.Gas.MaxFee,
+		MaxPriorityFee: n.Gas.MaxPriorityFee,
+		Legacy:         n.Gas.Legacy,
+	}
+}
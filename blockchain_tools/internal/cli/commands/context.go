@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/layla-lili/blockchain_tools/internal/cli/config"
+	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
+	"github.com/layla-lili/blockchain_tools/internal/common/logging"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/spf13/cobra"
+)
+
+// cmdContext is the state shared by every command of an invocation. It is
+// built once by the root command's PersistentPreRunE, so commands read
+// settings from Config rather than from their own flags.
+type cmdContext struct {
+	// Config is resolved from flags, the environment, the active network
+	// profile, the config file and defaults, in that order
+	Config *config.Config
+	// Formatter writes results in the selected output format
+	Formatter formatter.Formatter
+
+	mu     sync.Mutex
+	client *rpc.Client
+}
+
+type cmdContextKey struct{}
+
+// setupContext resolves the configuration and attaches a cmdContext to
+// cmd. An unknown network is only reported when strict is set.
+func setupContext(cmd *cobra.Command, strict bool) error {
+	cfg, err := config.Load()
+	if cfg == nil || (err != nil && strict) {
+		return err
+	}
+	if cfg.Debug {
+		logging.SetLevel("debug")
+	}
+	attachContext(cmd, cfg)
+	return nil
+}
+
+func attachContext(cmd *cobra.Command, cfg *config.Config) *cmdContext {
+	c := &cmdContext{
+		Config:    cfg,
+		Formatter: formatter.GetFormatter(cfg.Format),
+	}
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	cmd.SetContext(context.WithValue(parent, cmdContextKey{}, c))
+	return c
+}
+
+// getContext returns the cmdContext of the running command
+func getContext(cmd *cobra.Command) *cmdContext {
+	if ctx := cmd.Context(); ctx != nil {
+		if c, ok := ctx.Value(cmdContextKey{}).(*cmdContext); ok {
+			return c
+		}
+	}
+	// Commands executed without the root command's hooks resolve the
+	// configuration on first use
+	return attachContext(cmd, config.GetConfig())
+}
+
+// Client returns the client for the configured RPC endpoint, connecting on
+// first use. Later calls return the same client.
+func (c *cmdContext) Client() (*rpc.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		client, err := rpc.NewClient(c.Config.RpcURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client: %w", err)
+		}
+		c.client = client
+	}
+	return c.client, nil
+}
+
+// API returns a client for the blockchain-api server in api_url, which
+// sends the api_key as a bearer token
+func (c *cmdContext) API() (*apiClient, error) {
+	base := strings.TrimRight(c.Config.APIURL, "/")
+	if base == "" {
+		return nil, errNoAPI
+	}
+	return &apiClient{
+		baseURL: base,
+		apiKey:  c.Config.APIKey,
+		http:    &http.Client{Timeout: apiTimeout},
+	}, nil
+}
+
+// Close releases the shared client
+func (c *cmdContext) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
+
+// Print writes v to the command's output with the selected formatter
+func (c *cmdContext) Print(cmd *cobra.Command, v interface{}) error {
+	return c.Formatter.Format(cmd.OutOrStdout(), v)
+}
+
+// Stream returns a stream writing a list to the command's output in the
+// selected format
+func (c *cmdContext) Stream(cmd *cobra.Command) formatter.Stream {
+	return formatter.NewStream(c.Config.Format, cmd.OutOrStdout())
+}
@@ -0,0 +1,289 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/contract"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func newContractCmd() *cobra.Command {
+	contractCmd := &cobra.Command{
+		Use:   "contract",
+		Short: "Interact with smart contracts",
+		Long: `Commands to call, send transactions to and deploy smart contracts.
+
+--abi takes a JSON ABI file or a compiler artifact (Hardhat, Truffle or
+Foundry) that also holds the creation bytecode. Methods are named as in the
+ABI, or by signature such as 'transfer(address,uint256)' for overloads.
+Arguments are written as on a command line: integers in decimal or 0x hex,
+addresses and bytes as 0x hex, bools as true/false, and arrays and tuples as
+JSON, for example '["0xab..","0xcd.."]'.`,
+	}
+
+	contractCmd.PersistentFlags().String("abi", "", "Contract ABI JSON or compiler artifact file")
+	contractCmd.MarkPersistentFlagRequired("abi")
+
+	contractCmd.AddCommand(newContractCallCmd())
+	contractCmd.AddCommand(newContractSendCmd())
+	contractCmd.AddCommand(newContractDeployCmd())
+
+	return contractCmd
+}
+
+func newContractCallCmd() *cobra.Command {
+	var (
+		from  string
+		block string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "call [address] [method] [args...]",
+		Short: "Call a contract method without sending a transaction",
+		Long: `Execute a contract method with eth_call and print its decoded return values.
+Nothing is signed or sent, so this is how view and pure methods are read.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			abi, err := loadContract(cmd)
+			if err != nil {
+				return err
+			}
+			address, err := contractAddress(args[0])
+			if err != nil {
+				return err
+			}
+			method, err := abi.Method(args[1])
+			if err != nil {
+				return err
+			}
+			input, err := abi.Pack(args[1], args[2:])
+			if err != nil {
+				return err
+			}
+
+			msg := ethereum.CallMsg{To: &address, Data: input}
+			if from != "" {
+				if !common.IsHexAddress(from) {
+					return fmt.Errorf("invalid from address %q", from)
+				}
+				msg.From = common.HexToAddress(from)
+			}
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			output, err := client.CallContract(ctx, msg, block)
+			if err != nil {
+				return fmt.Errorf("failed to call %s: %w", method.Sig, revertError(abi, err))
+			}
+
+			outputs, err := abi.Unpack(args[1], output)
+			if err != nil {
+				return err
+			}
+
+			return getContext(cmd).Print(cmd, &types.CallResult{
+				Contract: address.Hex(),
+				Method:   method.Sig,
+				Outputs:  outputs,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Address the call is made from")
+	cmd.Flags().StringVar(&block, "block", "latest", "Block number or tag (latest, pending, safe, finalized) to call at")
+
+	return cmd
+}
+
+func newContractSendCmd() *cobra.Command {
+	var (
+		opts          contractTxOptions
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send [address] [method] [args...]",
+		Short: "Send a transaction calling a contract method",
+		Long: `Encode a contract method call and send it as a transaction. Signing, nonce,
+gas and fees work as for 'tx send'. With --wait the receipt is printed with
+the events it emitted decoded.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			abi, err := loadContract(cmd)
+			if err != nil {
+				return err
+			}
+			address, err := contractAddress(args[0])
+			if err != nil {
+				return err
+			}
+			input, err := abi.Pack(args[1], args[2:])
+			if err != nil {
+				return err
+			}
+
+			_, hash, err := opts.send(cmd, abi, address.Hex(), input)
+			if err != nil {
+				return err
+			}
+
+			printSent(cmd, hash)
+
+			if opts.wait {
+				return waitForReceipt(cmd, opts.client, hash, confirmations, timeout, abi)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	addWaitFlags(cmd, &confirmations, &timeout)
+
+	return cmd
+}
+
+func newContractDeployCmd() *cobra.Command {
+	var (
+		opts          contractTxOptions
+		bytecode      string
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deploy [constructor args...]",
+		Short: "Deploy a contract",
+		Long: `Deploy a contract from the creation bytecode in the --abi artifact, or given
+with --bytecode, followed by the encoded constructor arguments. The address
+of the new contract is printed once the transaction is sent.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			abi, err := loadContract(cmd)
+			if err != nil {
+				return err
+			}
+			if bytecode != "" {
+				if abi.Bytecode, err = readBytecode(bytecode); err != nil {
+					return err
+				}
+			}
+
+			input, err := abi.PackDeploy(args)
+			if err != nil {
+				if err == contract.ErrNoBytecode {
+					return fmt.Errorf("%w: use an artifact with bytecode or set --bytecode", err)
+				}
+				return err
+			}
+
+			sent, hash, err := opts.send(cmd, abi, "", input)
+			if err != nil {
+				return err
+			}
+
+			from := common.HexToAddress(sent.From)
+			cmd.Printf("Contract deployment sent successfully! Hash: %s\n", hash)
+			cmd.Printf("Contract address: %s\n", crypto.CreateAddress(from, sent.Nonce).Hex())
+
+			if opts.wait {
+				return waitForReceipt(cmd, opts.client, hash, confirmations, timeout, abi)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVar(&bytecode, "bytecode", "", "Creation bytecode as hex, or a file containing it")
+	addWaitFlags(cmd, &confirmations, &timeout)
+
+	return cmd
+}
+
+// contractTxOptions holds the flags shared by contract send and deploy
+type contractTxOptions struct {
+	from          string
+	value         string
+	gasMultiplier float64
+	wait          bool
+
+	client *rpc.Client
+}
+
+func (o *contractTxOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.from, "from", "", "Sender address, signed locally when held in the key file (default default_account)")
+	cmd.Flags().StringVar(&o.value, "value", "0", "Value sent with the call in wei")
+	cmd.Flags().Float64Var(&o.gasMultiplier, "gas-multiplier", rpc.DefaultGasMultiplier, "Safety multiplier applied to the estimated gas limit")
+	cmd.Flags().BoolVar(&o.wait, "wait", false, "Wait for the transaction to be mined and print its receipt")
+	addTxOverrideFlags(cmd)
+	addPasswordFlags(cmd)
+}
+
+// send sends input to the contract at to, or deploys it when to is empty.
+// It connects unless client is already set and keeps the client for
+// waiting on the receipt.
+func (o *contractTxOptions) send(cmd *cobra.Command, abi *contract.Contract, to string, input []byte) (*types.Transaction, string, error) {
+	amount, err := types.ParseBig(o.value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid value: %w", err)
+	}
+
+	if o.client == nil {
+		if o.client, err = getContext(cmd).Client(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	tx := &types.Transaction{
+		From:  o.from,
+		To:    to,
+		Value: amount,
+		Data:  input,
+	}
+	sent, hash, err := sendTransaction(cmd.Context(), cmd, o.client, tx, o.gasMultiplier)
+	if err != nil {
+		return nil, "", revertError(abi, err)
+	}
+	return sent, hash, nil
+}
+
+// loadContract reads the file named by --abi
+func loadContract(cmd *cobra.Command) (*contract.Contract, error) {
+	path, _ := cmd.Flags().GetString("abi")
+	return contract.Load(path)
+}
+
+func contractAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid contract address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// readBytecode reads --bytecode, which is either hex or a file holding it
+func readBytecode(value string) ([]byte, error) {
+	if data, err := os.ReadFile(value); err == nil {
+		value = string(data)
+	}
+	return contract.ParseBytecode(value)
+}
+
+// revertError replaces the error of a reverted call or gas estimate with
+// the revert reason or custom error decoded from its data
+func revertError(abi *contract.Contract, err error) error {
+	if data, ok := rpc.RevertData(err); ok && len(data) > 0 {
+		return fmt.Errorf("execution reverted: %s", abi.DecodeRevert(data))
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+// internal/cli/commands/index.go
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/indexer"
+	"github.com/spf13/cobra"
+)
+
+// errNoIndex is returned by commands served from an index that was never built
+var errNoIndex = errors.New("no chain index found; run 'blockchain-cli index run' first")
+
+func newIndexCmd() *cobra.Command {
+	indexCmd := &cobra.Command{
+		Use:   "index",
+		Short: "Manage the local chain index",
+		Long: `Commands to build and inspect the local SQLite index of blocks, transactions,
+receipts and logs. The index backs 'tx list' and 'account history'.
+
+The database location and first indexed block are set by index_db and
+index_start in the config file.`,
+	}
+
+	indexCmd.AddCommand(newIndexRunCmd())
+	indexCmd.AddCommand(newIndexStatusCmd())
+
+	return indexCmd
+}
+
+func newIndexRunCmd() *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Follow the chain and index new blocks",
+		Long: `Index blocks from the start height, then keep following the chain until
+interrupted. Reorgs are rolled back and re-indexed automatically.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			cfg := getContext(cmd).Config
+			store, err := indexer.OpenStore(cfg.IndexDB)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			start := cfg.IndexStart
+			if cmd.Flags().Changed("from") {
+				start, _ = cmd.Flags().GetUint64("from")
+			}
+			interval, _ := cmd.Flags().GetDuration("poll-interval")
+
+			ix := indexer.New(client, store,
+				indexer.WithStartHeight(start),
+				indexer.WithPollInterval(interval),
+				indexer.WithLogger(logger))
+
+			if once, _ := cmd.Flags().GetBool("once"); once {
+				return ix.Sync(ctx)
+			}
+			return ix.Run(ctx)
+		},
+	}
+
+	runCmd.Flags().Uint64("from", 0, "First block to index into an empty index (default index_start)")
+	runCmd.Flags().Bool("once", false, "Stop once the index has caught up with the chain")
+	runCmd.Flags().Duration("poll-interval", rpc.DefaultPollInterval, "How often to check for new blocks")
+
+	return runCmd
+}
+
+func newIndexStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show what the local index contains",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openIndex(cmd)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			status, err := store.Status(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read index: %w", err)
+			}
+
+			return getContext(cmd).Print(cmd, status)
+		},
+	}
+}
+
+// openIndex opens the index configured by index_db. It returns errNoIndex
+// instead of creating an empty database.
+func openIndex(cmd *cobra.Command) (*indexer.Store, error) {
+	path := getContext(cmd).Config.IndexDB
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, errNoIndex
+	}
+	store, err := indexer.OpenStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index: %w", err)
+	}
+	return store, nil
+}
+
+// addPageFlags registers the pagination flags of index-backed listings
+func addPageFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("limit", indexer.DefaultLimit, "Maximum number of results")
+	cmd.Flags().Int("offset", 0, "Number of results to skip")
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/keystore"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// passwordEnv is read when neither --password nor --password-file is set
+const passwordEnv = "BLOCKCHAIN_PASSWORD"
+
+// openKeystore opens the key file configured by key_file
+func openKeystore(cmd *cobra.Command) (*keystore.Store, error) {
+	path := getContext(cmd).Config.KeyFile
+	store, err := keystore.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keystore: %w", err)
+	}
+	return store, nil
+}
+
+// localAccount reports whether from names an account held in the local
+// key file, returning the opened store when it does
+func localAccount(cmd *cobra.Command, from string) (*keystore.Store, common.Address, bool, error) {
+	if !common.IsHexAddress(from) {
+		return nil, common.Address{}, false, nil
+	}
+
+	store, err := openKeystore(cmd)
+	if err != nil {
+		return nil, common.Address{}, false, err
+	}
+
+	addr := common.HexToAddress(from)
+	return store, addr, store.Has(addr), nil
+}
+
+// addPasswordFlags registers the flags read by readPassword
+func addPasswordFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("password", "p", "", "Keystore password")
+	cmd.Flags().String("password-file", "", "File containing the keystore password")
+}
+
+// readPassword returns the keystore password from --password,
+// --password-file or the BLOCKCHAIN_PASSWORD environment variable
+func readPassword(cmd *cobra.Command) (string, error) {
+	if password, _ := cmd.Flags().GetString("password"); password != "" {
+		return password, nil
+	}
+	if file, _ := cmd.Flags().GetString("password-file"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password := os.Getenv(passwordEnv); password != "" {
+		return password, nil
+	}
+	return "", fmt.Errorf("a password is required (use --password, --password-file or %s)", passwordEnv)
+}
+
+// signAndSend signs a fully built tx with the local key for its sender and
+// broadcasts it with eth_sendRawTransaction
+func signAndSend(ctx context.Context, client *rpc.Client, store *keystore.Store, password string, tx *types.Transaction) (string, error) {
+	unsigned, err := tx.ToGeth()
+	if err != nil {
+		return "", err
+	}
+	signed, err := store.SignTx(common.HexToAddress(tx.From), password, unsigned, tx.ChainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return client.SendRawTransaction(ctx, signed)
+}
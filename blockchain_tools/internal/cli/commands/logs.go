@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/contract"
+	"github.com/spf13/cobra"
+)
+
+func newLogsCmd() *cobra.Command {
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Query contract event logs",
+		Long:  `Commands to search and decode the event logs emitted by contracts.`,
+	}
+
+	logsCmd.AddCommand(newLogsQueryCmd())
+
+	return logsCmd
+}
+
+func newLogsQueryCmd() *cobra.Command {
+	var (
+		addresses []string
+		topics    []string
+		from      string
+		to        string
+		chunkSize uint64
+		abiFile   string
+		events    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Search logs by address, topics and block range",
+		Long: `Search logs with eth_getLogs. Large block ranges are split into chunks of
+--chunk-size blocks, and chunks the node rejects are split further.
+
+Logs are decoded when their event is in the --abi file or given with --event,
+for example --event 'Transfer(address indexed from, address indexed to, uint256 value)'.
+Unless --topic sets the first topic, --event also restricts the search to
+those events.
+
+Example: blockchain-cli logs query --address 0x... --from 17000000 --to 17100000 --abi ERC20.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := rpc.LogFilter{
+				Addresses: addresses,
+				Topics:    rpc.ParseTopics(topics),
+			}
+
+			var err error
+			if filter.FromBlock, err = parseBlockFlag("from", from); err != nil {
+				return err
+			}
+			if filter.ToBlock, err = parseBlockFlag("to", to); err != nil {
+				return err
+			}
+
+			var decoder *contract.Contract
+			if abiFile != "" {
+				if decoder, err = contract.Load(abiFile); err != nil {
+					return err
+				}
+			}
+			if len(events) > 0 {
+				selected, err := contract.FromEvents(events...)
+				if err != nil {
+					return err
+				}
+				if decoder == nil {
+					decoder = selected
+				} else if err := decoder.AddEvents(events...); err != nil {
+					return err
+				}
+
+				if len(filter.Topics) == 0 {
+					filter.Topics = [][]string{nil}
+				}
+				if len(filter.Topics[0]) == 0 {
+					filter.Topics[0] = selected.EventIDs()
+				}
+			}
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			logs, err := client.QueryLogs(cmd.Context(), filter, chunkSize)
+			if err != nil {
+				return fmt.Errorf("failed to query logs: %w", err)
+			}
+			if decoder != nil {
+				decoder.DecodeLogs(logs)
+			}
+
+			return getContext(cmd).Print(cmd, logs)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&addresses, "address", nil, "Only logs from these contracts")
+	cmd.Flags().StringArrayVar(&topics, "topic", nil, "Topic filter by position, repeatable; comma separates alternatives, empty or * matches any")
+	cmd.Flags().StringVar(&from, "from", "", "First block of the range (default: the --to block)")
+	cmd.Flags().StringVar(&to, "to", "latest", "Last block of the range, or latest")
+	cmd.Flags().Uint64Var(&chunkSize, "chunk-size", rpc.DefaultLogChunkSize, "Maximum number of blocks per eth_getLogs request")
+	cmd.Flags().StringVar(&abiFile, "abi", "", "Contract ABI JSON or compiler artifact used to decode logs")
+	cmd.Flags().StringArrayVar(&events, "event", nil, "Event signature used to decode and select logs, repeatable")
+
+	return cmd
+}
+
+// parseBlockFlag parses a block number flag, where empty and latest mean
+// the current head
+func parseBlockFlag(name, value string) (*uint64, error) {
+	if value == "" || value == "latest" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(value, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %q is not a block number", name, value)
+	}
+	return &n, nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// endpoint returns the RPC endpoint: --rpc-url when given, otherwise
+// rpc_url from the active network profile or the config
+func endpoint(cmd *cobra.Command) string {
+	return getContext(cmd).Config.RpcURL
+}
+
+// wsEndpoint returns the endpoint used for subscriptions: --ws-url when
+// given, then ws_url from the config, then the RPC endpoint
+func wsEndpoint(cmd *cobra.Command) string {
+	if url, _ := cmd.Flags().GetString("ws-url"); url != "" {
+		return url
+	}
+	if cmd.Flags().Changed("rpc-url") {
+		return endpoint(cmd)
+	}
+	if url := getContext(cmd).Config.WsURL; url != "" {
+		return url
+	}
+	return endpoint(cmd)
+}
+
+// defaultFrom returns from, or the default account of the active network
+// when it is empty
+func defaultFrom(cmd *cobra.Command, from string) string {
+	if from != "" {
+		return from
+	}
+	return getContext(cmd).Config.DefaultAccount
+}
+
+// requireFrom is defaultFrom for commands that cannot run without a sender
+func requireFrom(cmd *cobra.Command, from string) (string, error) {
+	if from = defaultFrom(cmd, from); from == "" {
+		return "", fmt.Errorf("--from is required (or set default_account for the network)")
+	}
+	return from, nil
+}
+
+// gasMultiplierFromFlags returns --gas-multiplier when given, otherwise the
+// network's gas policy or value, the flag default
+func gasMultiplierFromFlags(cmd *cobra.Command, value float64) float64 {
+	if cmd.Flags().Changed("gas-multiplier") {
+		return value
+	}
+	if m := getContext(cmd).Config.Gas.Multiplier; m > 0 {
+		return m
+	}
+	return value
+}
+
+// checkChainID refuses to sign for a node whose chain ID differs from the
+// one configured for the active network
+func checkChainID(cmd *cobra.Command, chainID *big.Int) error {
+	cfg := getContext(cmd).Config
+	if cfg.ChainID == 0 || chainID == nil {
+		return nil
+	}
+	if !chainID.IsUint64() || chainID.Uint64() != cfg.ChainID {
+		name := cfg.Active
+		if name == "" {
+			name = "the config"
+		} else {
+			name = fmt.Sprintf("network %q", name)
+		}
+		return fmt.Errorf("refusing to sign: the node reports chain ID %s but %s expects %d", chainID, name, cfg.ChainID)
+	}
+	return nil
+}
+
+// printSent reports a sent transaction, with its explorer page when the
+// network has one
+func printSent(cmd *cobra.Command, hash string) {
+	cmd.Printf("Transaction sent successfully! Hash: %s\n", hash)
+	if link := explorerLink(cmd, hash); link != "" {
+		cmd.Printf("Explorer: %s\n", link)
+	}
+}
+
+// explorerLink returns the block explorer page of a transaction, or "" when
+// no explorer_url is configured
+func explorerLink(cmd *cobra.Command, hash string) string {
+	base := getContext(cmd).Config.ExplorerURL
+	if base == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + "/tx/" + hash
+}
@@ -1,11 +1,8 @@
 package commands
 
 import (
-	"context"
 	"fmt"
 
-	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
-	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +17,7 @@ func newNodeCmd() *cobra.Command {
 	nodeCmd.AddCommand(newNodeStatusCmd())
 	nodeCmd.AddCommand(newNodePeersCmd())
 	nodeCmd.AddCommand(newNodeSyncCmd())
+	nodeCmd.AddCommand(newNodeEndpointsCmd())
 
 	return nodeCmd
 }
@@ -29,12 +27,11 @@ func newNodeStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Get node status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			status, err := client.GetNodeStatus(ctx)
@@ -42,9 +39,7 @@ func newNodeStatusCmd() *cobra.Command {
 				return fmt.Errorf("failed to get node status: %w", err)
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), status)
+			return getContext(cmd).Print(cmd, status)
 		},
 	}
 }
@@ -54,12 +49,11 @@ func newNodePeersCmd() *cobra.Command {
 		Use:   "peers",
 		Short: "List connected peers",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			peers, err := client.GetPeers(ctx)
@@ -67,9 +61,7 @@ func newNodePeersCmd() *cobra.Command {
 				return fmt.Errorf("failed to get peers: %w", err)
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), peers)
+			return getContext(cmd).Print(cmd, peers)
 		},
 	}
 }
@@ -79,12 +71,11 @@ func newNodeSyncCmd() *cobra.Command {
 		Use:   "sync",
 		Short: "Get node synchronization status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			syncStatus, err := client.GetSyncStatus(ctx)
@@ -92,9 +83,27 @@ func newNodeSyncCmd() *cobra.Command {
 				return fmt.Errorf("failed to get sync status: %w", err)
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), syncStatus)
+			return getContext(cmd).Print(cmd, syncStatus)
+		},
+	}
+}
+
+func newNodeEndpointsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "endpoints",
+		Short: "Check the health of each RPC endpoint",
+		Long: `Check each endpoint listed in rpc_url or --rpc-url with eth_blockNumber.
+Several endpoints of the same chain may be given separated by commas; calls
+go to the first healthy one and fail over to the others.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			client.CheckHealth(cmd.Context())
+			return getContext(cmd).Print(cmd, client.Endpoints())
 		},
 	}
 }
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/internal/cli/config"
+)
+
+// Commands run their calls under the command's context, so cancelling it
+// stops a call to an unresponsive node
+func TestCommandContextCancelsCalls(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &config.Config{}
+	cfg.RpcURL = srv.URL
+	cc := testContext(cfg)
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	cmd := newNodeStatusCmd()
+	cmd.SetArgs(nil)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+
+	start := time.Now()
+	err := cmd.ExecuteContext(context.WithValue(ctx, cmdContextKey{}, cc))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("node status error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("node status took %s after its context expired", elapsed)
+	}
+}
@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/signature"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func newSignCmd() *cobra.Command {
+	signCmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign messages and typed data with local keys",
+		Long: `Sign EIP-191 personal messages (personal_sign) and EIP-712 typed data
+(eth_signTypedData_v4) with a key held in the local key file. Signatures are
+65 bytes with V set to 27 or 28, as wallets produce them.`,
+	}
+
+	signCmd.AddCommand(newSignMessageCmd())
+	signCmd.AddCommand(newSignTypedDataCmd())
+
+	return signCmd
+}
+
+func newSignMessageCmd() *cobra.Command {
+	var (
+		from string
+		file string
+		hex  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "message [message]",
+		Short: "Sign an EIP-191 personal message",
+		Long: `Sign a message as personal_sign does: the keccak256 hash of
+"\x19Ethereum Signed Message:\n" followed by the message length and the
+message. The message is taken from the argument or from --file, as text or,
+with --hex, as 0x-prefixed hex bytes.`,
+		Example: `  blockchain-cli sign message "Log in to example.com: nonce 42" --from 0x...
+  blockchain-cli sign message --hex 0xdeadbeef --from 0x... --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg, err := readMessage(cmd, args, file, hex)
+			if err != nil {
+				return err
+			}
+			return signHash(cmd, from, signature.KindMessage, signature.HashMessage(msg))
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Signing address, held in the local key file (default default_account)")
+	cmd.Flags().StringVar(&file, "file", "", "Read the message from a file, or - for standard input")
+	cmd.Flags().BoolVar(&hex, "hex", false, "The message is 0x-prefixed hex bytes")
+	addPasswordFlags(cmd)
+
+	return cmd
+}
+
+func newSignTypedDataCmd() *cobra.Command {
+	var from string
+
+	cmd := &cobra.Command{
+		Use:   "typed-data <file>",
+		Short: "Sign EIP-712 typed data",
+		Long: `Sign an EIP-712 typed data document as eth_signTypedData_v4 does. The file
+holds the JSON document with its types, primaryType, domain and message; use -
+to read it from standard input.`,
+		Example: `  blockchain-cli sign typed-data order.json --from 0x...`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			td, err := readTypedData(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			hash, err := signature.HashTypedData(td)
+			if err != nil {
+				return err
+			}
+			return signHash(cmd, from, signature.KindTypedData, hash)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Signing address, held in the local key file (default default_account)")
+	addPasswordFlags(cmd)
+
+	return cmd
+}
+
+func newVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify message and typed data signatures",
+		Long: `Recover the signer of an EIP-191 personal message or EIP-712 typed data
+signature and check it against an expected address. The command exits
+non-zero when the signature was not made by that address.`,
+	}
+
+	verifyCmd.AddCommand(newVerifyMessageCmd())
+	verifyCmd.AddCommand(newVerifyTypedDataCmd())
+
+	return verifyCmd
+}
+
+func newVerifyMessageCmd() *cobra.Command {
+	var (
+		address string
+		sig     string
+		file    string
+		hex     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "message [message]",
+		Short: "Verify an EIP-191 personal message signature",
+		Long: `Verify a personal_sign signature. The message is read as for "sign message":
+from the argument or --file, as text or, with --hex, as hex bytes.`,
+		Example: `  blockchain-cli verify message "Log in to example.com: nonce 42" --address 0x... --signature 0x...`,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg, err := readMessage(cmd, args, file, hex)
+			if err != nil {
+				return err
+			}
+			return verifyHash(cmd, address, sig, signature.KindMessage, signature.HashMessage(msg))
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Read the message from a file, or - for standard input")
+	cmd.Flags().BoolVar(&hex, "hex", false, "The message is 0x-prefixed hex bytes")
+	addVerifyFlags(cmd, &address, &sig)
+
+	return cmd
+}
+
+func newVerifyTypedDataCmd() *cobra.Command {
+	var address, sig string
+
+	cmd := &cobra.Command{
+		Use:     "typed-data <file>",
+		Short:   "Verify an EIP-712 typed data signature",
+		Example: `  blockchain-cli verify typed-data order.json --address 0x... --signature 0x...`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			td, err := readTypedData(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			hash, err := signature.HashTypedData(td)
+			if err != nil {
+				return err
+			}
+			return verifyHash(cmd, address, sig, signature.KindTypedData, hash)
+		},
+	}
+
+	addVerifyFlags(cmd, &address, &sig)
+
+	return cmd
+}
+
+// addVerifyFlags registers the expected signer and signature flags
+func addVerifyFlags(cmd *cobra.Command, address, sig *string) {
+	cmd.Flags().StringVar(address, "address", "", "Address expected to have signed")
+	cmd.Flags().StringVar(sig, "signature", "", "65-byte signature as 0x-prefixed hex")
+	cmd.MarkFlagRequired("address")
+	cmd.MarkFlagRequired("signature")
+}
+
+// signHash signs hash with the local key for from and prints the signature
+func signHash(cmd *cobra.Command, from, kind string, hash common.Hash) error {
+	from, err := requireFrom(cmd, from)
+	if err != nil {
+		return err
+	}
+	store, addr, local, err := localAccount(cmd, from)
+	if err != nil {
+		return err
+	}
+	if !local {
+		return fmt.Errorf("%s is not in the local key file", from)
+	}
+	password, err := readPassword(cmd)
+	if err != nil {
+		return err
+	}
+
+	sig, err := store.SignHash(addr, password, hash)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to sign: %w", err)
+	}
+
+	result := &types.Signature{
+		Address:   addr.Hex(),
+		Type:      kind,
+		Hash:      hash.Hex(),
+		Signature: sig,
+	}
+
+	return getContext(cmd).Print(cmd, result)
+}
+
+// verifyHash checks that sig over hash recovers to address, printing the
+// outcome before failing for a mismatch
+func verifyHash(cmd *cobra.Command, address, sig, kind string, hash common.Hash) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+	sigBytes, err := hexutil.Decode(sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	signer, err := signature.Recover(hash, sigBytes)
+	if err != nil {
+		return err
+	}
+
+	expected := common.HexToAddress(address)
+	result := &types.Verification{
+		Address: expected.Hex(),
+		Signer:  signer.Hex(),
+		Type:    kind,
+		Hash:    hash.Hex(),
+		Valid:   signer == expected,
+	}
+
+	if err := getContext(cmd).Print(cmd, result); err != nil {
+		return err
+	}
+	if !result.Valid {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("signature was made by %s, not %s", result.Signer, result.Address)
+	}
+	return nil
+}
+
+// readMessage returns the message given as the only argument or in file,
+// decoding it from hex when hex is set
+func readMessage(cmd *cobra.Command, args []string, file string, hex bool) ([]byte, error) {
+	if (len(args) == 1) == (file != "") {
+		return nil, fmt.Errorf("give the message as an argument or with --file")
+	}
+
+	var msg []byte
+	if file != "" {
+		data, err := readInput(cmd, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		msg = data
+	} else {
+		msg = []byte(args[0])
+	}
+
+	if hex {
+		decoded, err := hexutil.Decode(string(msg))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex message: %w", err)
+		}
+		msg = decoded
+	}
+	return msg, nil
+}
+
+// readTypedData parses the EIP-712 document in file, or standard input for -
+func readTypedData(cmd *cobra.Command, file string) (*signature.TypedData, error) {
+	data, err := readInput(cmd, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read typed data: %w", err)
+	}
+	return signature.ParseTypedData(data)
+}
+
+// readInput reads file, or standard input for -
+func readInput(cmd *cobra.Command, file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(file)
+}
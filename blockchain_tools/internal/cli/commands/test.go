@@ -5,13 +5,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(testCmd)
-	testCmd.Flags().String("rpc-url", "http://localhost:8545", "RPC URL of the blockchain node")
 	testCmd.Flags().Bool("verbose", false, "Show detailed information")
 }
 
@@ -19,11 +17,11 @@ var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Test connection to local blockchain",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		rpcURL, _ := cmd.Flags().GetString("rpc-url")
+		rpcURL := endpoint(cmd)
 		verbose, _ := cmd.Flags().GetBool("verbose")
 
 		// Test connection to Anvil
-		client, err := rpc.NewClient(rpcURL)
+		client, err := getContext(cmd).Client()
 		if err != nil {
 			return fmt.Errorf("failed to connect to node: %v", err)
 		}
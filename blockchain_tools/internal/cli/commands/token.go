@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/pkg/token"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func newTokenCmd() *cobra.Command {
+	tokenCmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage ERC-20 and ERC-721 tokens",
+		Long: `Commands to read token balances and metadata and to send token transfers and
+approvals. Amounts are given in whole tokens, such as 1.5, and converted
+with the token's decimals unless --raw is set.`,
+	}
+
+	tokenCmd.AddCommand(newTokenInfoCmd())
+	tokenCmd.AddCommand(newTokenBalanceCmd())
+	tokenCmd.AddCommand(newTokenTransferCmd())
+	tokenCmd.AddCommand(newTokenApproveCmd())
+
+	return tokenCmd
+}
+
+func newTokenInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info [token]",
+		Short: "Show the name, symbol, decimals and supply of a token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			info, err := token.Info(ctx, client, args[0])
+			if err != nil {
+				return err
+			}
+
+			return getContext(cmd).Print(cmd, info)
+		},
+	}
+}
+
+func newTokenBalanceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "balance [token] [account]",
+		Short: "Get the token balance of an account",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			info, err := token.Info(ctx, client, args[0])
+			if err != nil {
+				return err
+			}
+			balance, err := token.BalanceOf(ctx, client, info, args[1])
+			if err != nil {
+				return err
+			}
+
+			return getContext(cmd).Print(cmd, balance)
+		},
+	}
+}
+
+func newTokenTransferCmd() *cobra.Command {
+	var (
+		opts          contractTxOptions
+		raw           bool
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "transfer [token] [to] [amount]",
+		Short: "Transfer tokens",
+		Long: `Transfer ERC-20 tokens, or an ERC-721 token given by its ID in place of the
+amount. ERC-721 transfers use transferFrom and require --from. Signing works
+as for 'tx send'.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := opts.token(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			abi := token.ERC20
+			var input []byte
+			if info.Standard == types.StandardERC721 {
+				opts.from = defaultFrom(cmd, opts.from)
+				if opts.from == "" {
+					return fmt.Errorf("--from is required for ERC-721 transfers")
+				}
+				id, err := types.ParseUint256(args[2])
+				if err != nil {
+					return fmt.Errorf("invalid token ID: %w", err)
+				}
+				abi = token.ERC721
+				input, err = token.PackTransferNFT(opts.from, args[1], id)
+				if err != nil {
+					return err
+				}
+			} else {
+				amount, err := parseTokenAmount(args[2], info.Decimals, raw)
+				if err != nil {
+					return err
+				}
+				input, err = token.PackTransfer(args[1], amount)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, hash, err := opts.send(cmd, abi, info.Address, input)
+			if err != nil {
+				return err
+			}
+
+			printSent(cmd, hash)
+
+			if opts.wait {
+				return waitForReceipt(cmd, opts.client, hash, confirmations, timeout, abi)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().BoolVar(&raw, "raw", false, "Amount is in the token's smallest unit")
+	addWaitFlags(cmd, &confirmations, &timeout)
+
+	return cmd
+}
+
+func newTokenApproveCmd() *cobra.Command {
+	var (
+		opts          contractTxOptions
+		raw           bool
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "approve [token] [spender] [amount]",
+		Short: "Allow a spender to transfer ERC-20 tokens",
+		Long: `Set the ERC-20 allowance of spender. Use max as the amount for an unlimited
+approval and 0 to revoke it. Signing works as for 'tx send'.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := opts.token(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			if info.Standard != types.StandardERC20 {
+				return fmt.Errorf("%s is an %s token; approve ERC-721 tokens with 'contract send'", info.Address, info.Standard)
+			}
+
+			amount := token.MaxAmount
+			if args[2] != "max" {
+				if amount, err = parseTokenAmount(args[2], info.Decimals, raw); err != nil {
+					return err
+				}
+			}
+			input, err := token.PackApprove(args[1], amount)
+			if err != nil {
+				return err
+			}
+
+			_, hash, err := opts.send(cmd, token.ERC20, info.Address, input)
+			if err != nil {
+				return err
+			}
+
+			printSent(cmd, hash)
+
+			if opts.wait {
+				return waitForReceipt(cmd, opts.client, hash, confirmations, timeout, token.ERC20)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().BoolVar(&raw, "raw", false, "Amount is in the token's smallest unit")
+	addWaitFlags(cmd, &confirmations, &timeout)
+
+	return cmd
+}
+
+func newNFTCmd() *cobra.Command {
+	nftCmd := &cobra.Command{
+		Use:   "nft",
+		Short: "Query ERC-721 tokens",
+		Long:  `Commands to query individual ERC-721 tokens.`,
+	}
+
+	nftCmd.AddCommand(&cobra.Command{
+		Use:   "owner [token] [tokenId]",
+		Short: "Get the owner and metadata URI of an ERC-721 token",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			id, err := types.ParseUint256(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid token ID: %w", err)
+			}
+
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			nft, err := token.OwnerOf(ctx, client, args[0], id)
+			if err != nil {
+				return err
+			}
+
+			return getContext(cmd).Print(cmd, nft)
+		},
+	})
+
+	return nftCmd
+}
+
+// token connects and reads the token the transaction is sent to
+func (o *contractTxOptions) token(cmd *cobra.Command, address string) (*types.Token, error) {
+	client, err := getContext(cmd).Client()
+	if err != nil {
+		return nil, err
+	}
+	o.client = client
+
+	return token.Info(cmd.Context(), client, address)
+}
+
+// parseTokenAmount converts an amount in whole tokens, or in the smallest
+// unit when raw is set. Either way it must fit in a uint256.
+func parseTokenAmount(amount string, decimals uint8, raw bool) (*big.Int, error) {
+	if raw {
+		return types.ParseUint256(amount)
+	}
+	return types.ParseUnits(amount, decimals)
+}
@@ -0,0 +1,39 @@
+package commands
+
+import "testing"
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		raw      bool
+		want     string
+		wantErr  bool
+	}{
+		{amount: "1.5", decimals: 6, want: "1500000"},
+		{amount: "1500000", decimals: 6, raw: true, want: "1500000"},
+		{amount: "0x10", decimals: 18, raw: true, want: "16"},
+		{amount: "-1", decimals: 18, raw: true, wantErr: true},
+		{amount: "-5", decimals: 18, raw: true, wantErr: true},
+		{amount: "-1", decimals: 18, wantErr: true},
+		{amount: "1.5", decimals: 18, raw: true, wantErr: true},
+		{amount: "115792089237316195423570985008687907853269984665640564039457584007913129639936", decimals: 0, raw: true, wantErr: true},
+		{amount: "1" + "000000000000000000000000000000000000000000000000000000000000", decimals: 18, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTokenAmount(tt.amount, tt.decimals, tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTokenAmount(%q, %d, %t) = %s, want error", tt.amount, tt.decimals, tt.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTokenAmount(%q, %d, %t): %v", tt.amount, tt.decimals, tt.raw, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("parseTokenAmount(%q, %d, %t) = %s, want %s", tt.amount, tt.decimals, tt.raw, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,18 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
-	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/contract"
+	"github.com/layla-lili/blockchain_tools/pkg/indexer"
 	"github.com/layla-lili/blockchain_tools/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +29,9 @@ func newTransactionCmd() *cobra.Command {
 	txCmd.AddCommand(newGetTransactionCmd())
 	txCmd.AddCommand(newSendTransactionCmd())
 	txCmd.AddCommand(newListTransactionsCmd())
+	txCmd.AddCommand(newWaitTransactionCmd())
+	txCmd.AddCommand(newTraceTransactionCmd())
+	txCmd.AddCommand(newBatchTransactionCmd())
 
 	return txCmd
 }
@@ -31,12 +42,11 @@ func newGetTransactionCmd() *cobra.Command {
 		Short: "Get transaction details by hash",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			tx, err := client.GetTransaction(ctx, args[0])
@@ -44,39 +54,61 @@ func newGetTransactionCmd() *cobra.Command {
 				return fmt.Errorf("failed to get transaction: %w", err)
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), tx)
+			return getContext(cmd).Print(cmd, tx)
 		},
 	}
 }
 
 func newSendTransactionCmd() *cobra.Command {
 	var (
-		to     string
-		value  uint64
-		data   string
-		isTest bool
+		from          string
+		to            string
+		value         string
+		data          string
+		isTest        bool
+		gasMultiplier float64
+		wait          bool
+		confirmations uint64
+		timeout       time.Duration
+		dryRun        bool
+		block         string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "send",
 		Short: "Send a new transaction",
-		Long:  `Create and send a new transaction to the blockchain.`,
+		Long: `Create and send a new transaction to the blockchain.
+
+When --from names an account in the local key file the transaction is signed
+locally (EIP-1559, or EIP-155 with --legacy) and submitted with
+eth_sendRawTransaction, so any node can be used. Otherwise the node signs it
+with one of its own unlocked accounts.
+
+Nonce, gas limit and fees are filled in automatically: the nonce from the
+pending pool, the gas limit from eth_estimateGas times --gas-multiplier, and
+fees from recent eth_feeHistory rewards. Any of them can be overridden.
+
+With --dry-run the transaction is built the same way but only run through
+eth_estimateGas and eth_call at --block, optionally with account balances
+and code replaced by --override-balance and --override-code. The outcome,
+gas and return data are printed and nothing is signed or sent; the command
+exits non-zero when the transaction would fail.`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			isTest, _ := cmd.Flags().GetBool("test")
 			if !isTest && to == "" {
 				return fmt.Errorf("required flag \"to\" not set")
 			}
+			if dryRun && (isTest || wait) {
+				return fmt.Errorf("--dry-run cannot be combined with --test or --wait")
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
 			if isTest {
@@ -92,7 +124,7 @@ func newSendTransactionCmd() *cobra.Command {
 				tx := &types.Transaction{
 					From:  accounts[0].Hex(),
 					To:    accounts[1].Hex(),
-					Value: 1000000000000000000, // 1 ETH in wei
+					Value: big.NewInt(params.Ether), // 1 ETH in wei
 				}
 
 				hash, err := client.SendTransaction(ctx, tx)
@@ -109,52 +141,437 @@ func newSendTransactionCmd() *cobra.Command {
 			}
 
 			// Handle regular transaction
+			amount, err := types.ParseBig(value)
+			if err != nil {
+				return fmt.Errorf("invalid value: %w", err)
+			}
+
+			input, err := parseData(data)
+			if err != nil {
+				return err
+			}
+
 			tx := &types.Transaction{
+				From:  from,
 				To:    to,
-				Value: value,
-				Data:  []byte(data),
+				Value: amount,
+				Data:  input,
+			}
+
+			if dryRun {
+				return simulateTransaction(ctx, cmd, client, tx, gasMultiplier, block)
 			}
 
-			hash, err := client.SendTransaction(ctx, tx)
+			_, hash, err := sendTransaction(ctx, cmd, client, tx, gasMultiplier)
 			if err != nil {
-				return fmt.Errorf("failed to send transaction: %w", err)
+				return err
 			}
 
-			cmd.Printf("Transaction sent successfully! Hash: %s\n", hash)
+			printSent(cmd, hash)
+
+			if wait {
+				return waitForReceipt(cmd, client, hash, confirmations, timeout, nil)
+			}
 			return nil
 		},
 	}
 
 	// Add flags
+	cmd.Flags().StringVar(&from, "from", "", "Sender address, signed locally when held in the key file (default default_account)")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient address")
-	cmd.Flags().Uint64Var(&value, "value", 0, "Transaction value in wei")
-	cmd.Flags().StringVar(&data, "data", "", "Transaction data (optional)")
+	cmd.Flags().StringVar(&value, "value", "0", "Transaction value in wei")
+	cmd.Flags().StringVar(&data, "data", "", "Transaction data as 0x-prefixed hex; other text is sent as raw bytes (optional)")
 	cmd.Flags().BoolVar(&isTest, "test", false, "Send a test transaction between first two accounts")
+	cmd.Flags().Float64Var(&gasMultiplier, "gas-multiplier", rpc.DefaultGasMultiplier, "Safety multiplier applied to the estimated gas limit")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the transaction to be mined and print its receipt")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Simulate the transaction instead of sending it")
+	cmd.Flags().StringVar(&block, "block", "latest", "Block number or tag (latest, pending) to simulate at with --dry-run")
+	cmd.Flags().StringArray("override-balance", nil, "Balance override for --dry-run as address=wei (repeatable)")
+	cmd.Flags().StringArray("override-code", nil, "Code override for --dry-run as address=hex, or address=file holding it (repeatable)")
+	addWaitFlags(cmd, &confirmations, &timeout)
+	addTxOverrideFlags(cmd)
+	addPasswordFlags(cmd)
 
 	return cmd
 }
 
-func newListTransactionsCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "list",
-		Short: "List recent transactions",
+func newWaitTransactionCmd() *cobra.Command {
+	var (
+		confirmations uint64
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wait [hash]",
+		Short: "Wait for a transaction to be mined",
+		Long: `Wait until a transaction is mined with the requested number of confirmations
+and print its receipt. Exits non-zero if the transaction reverted, was dropped
+or replaced, or the timeout expired.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
+			client, err := getContext(cmd).Client()
+			if err != nil {
+				return err
+			}
+
+			return waitForReceipt(cmd, client, args[0], confirmations, timeout, nil)
+		},
+	}
+
+	addWaitFlags(cmd, &confirmations, &timeout)
+
+	return cmd
+}
+
+func newTraceTransactionCmd() *cobra.Command {
+	var (
+		abiFile string
+		errs    []string
+		steps   bool
+	)
 
-			rpcURL, _ := cmd.Flags().GetString("rpc-url")
-			client, err := rpc.NewClient(rpcURL)
+	cmd := &cobra.Command{
+		Use:   "trace [hash]",
+		Short: "Trace the execution of a mined transaction",
+		Long: `Re-execute a mined transaction with debug_traceTransaction and print its call
+tree with the gas given to and used by every call. Reverted calls show their
+revert reason: Error(string) and Panic(uint256) are always decoded, and
+custom errors when they are in the --abi file or passed with --error. The
+node must expose the debug namespace.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var decoder *contract.Contract
+			if abiFile != "" {
+				var err error
+				if decoder, err = contract.Load(abiFile); err != nil {
+					return err
+				}
+				if err := decoder.AddErrors(errs...); err != nil {
+					return err
+				}
+			} else if len(errs) > 0 {
+				var err error
+				if decoder, err = contract.FromErrors(errs...); err != nil {
+					return err
+				}
+			}
+
+			client, err := getContext(cmd).Client()
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return err
 			}
 
-			txs, err := client.ListTransactions(ctx)
+			trace, err := client.TraceTransaction(ctx, args[0], steps)
 			if err != nil {
-				return fmt.Errorf("failed to list transactions: %w", err)
+				if rpc.IsMethodNotFound(err) {
+					return fmt.Errorf("failed to trace transaction: the node does not expose debug_traceTransaction")
+				}
+				return fmt.Errorf("failed to trace transaction: %w", err)
+			}
+			decoder.DecodeCalls(trace.Call)
+
+			return getContext(cmd).Print(cmd, trace)
+		},
+	}
+
+	cmd.Flags().StringVar(&abiFile, "abi", "", "Contract ABI JSON or compiler artifact used to decode methods and custom errors")
+	cmd.Flags().StringArrayVar(&errs, "error", nil, "Custom error signature to decode, such as 'TooBig(uint256 value)' (repeatable)")
+	cmd.Flags().BoolVar(&steps, "steps", false, "Also list every executed opcode (struct logger)")
+
+	return cmd
+}
+
+// addWaitFlags registers the flags shared by tx wait and tx send --wait
+func addWaitFlags(cmd *cobra.Command, confirmations *uint64, timeout *time.Duration) {
+	cmd.Flags().Uint64Var(confirmations, "confirmations", 1, "Number of confirmations to wait for")
+	cmd.Flags().DurationVar(timeout, "timeout", 2*time.Minute, "Maximum time to wait for the receipt")
+}
+
+// waitForReceipt waits for hash to be mined, prints the receipt and returns
+// an error when the transaction did not succeed. When abi is set, the logs
+// it can decode are printed decoded.
+func waitForReceipt(cmd *cobra.Command, client *rpc.Client, hash string, confirmations uint64, timeout time.Duration, abi *contract.Contract) error {
+	// Failures past this point are outcomes, not usage mistakes
+	cmd.SilenceUsage = true
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	receipt, err := client.WaitForReceipt(ctx, hash, rpc.WaitOptions{Confirmations: confirmations})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for transaction %s", timeout, hash)
+	}
+	if err != nil && receipt == nil {
+		return fmt.Errorf("failed to wait for transaction: %w", err)
+	}
+	if abi != nil {
+		abi.DecodeLogs(receipt.Logs)
+	}
+
+	if ferr := getContext(cmd).Print(cmd, receipt); ferr != nil {
+		return ferr
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("transaction %s: %w", hash, err)
+	case receipt.Status == types.StatusFailed:
+		return fmt.Errorf("transaction %s reverted", hash)
+	}
+	return nil
+}
+
+// sendTransaction fills in the nonce, gas limit and fees of tx and sends it,
+// signing locally when the sender is held in the key file. It reads the
+// flags registered by addTxOverrideFlags and addPasswordFlags and returns
+// the transaction as sent.
+func sendTransaction(ctx context.Context, cmd *cobra.Command, client *rpc.Client, tx *types.Transaction, gasMultiplier float64) (*types.Transaction, string, error) {
+	overrides, err := txOverridesFromFlags(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if tx.From == "" {
+		tx.From = defaultFrom(cmd, "")
+	}
+
+	builder := rpc.NewTxBuilder(client, rpc.WithGasMultiplier(gasMultiplierFromFlags(cmd, gasMultiplier)))
+	built, err := builder.Build(ctx, tx, overrides)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build transaction: %w", err)
+	}
+	if err := checkChainID(cmd, built.ChainID); err != nil {
+		cmd.SilenceUsage = true
+		return nil, "", err
+	}
+
+	store, _, local, err := localAccount(cmd, built.From)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var hash string
+	if local {
+		var password string
+		if password, err = readPassword(cmd); err != nil {
+			return nil, "", err
+		}
+		hash, err = signAndSend(ctx, client, store, password, built)
+	} else {
+		hash, err = client.SendTransaction(ctx, built)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return built, hash, nil
+}
+
+// simulateTransaction builds tx as sendTransaction would and simulates it
+// at block with the state overrides from the flags, printing the outcome.
+// A transaction that would fail is reported as an error after printing.
+func simulateTransaction(ctx context.Context, cmd *cobra.Command, client *rpc.Client, tx *types.Transaction, gasMultiplier float64, block string) error {
+	overrides, err := txOverridesFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	state, err := stateOverridesFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	if tx.From == "" {
+		tx.From = defaultFrom(cmd, "")
+	}
+
+	builder := rpc.NewTxBuilder(client, rpc.WithGasMultiplier(gasMultiplierFromFlags(cmd, gasMultiplier)))
+	sim, err := builder.Simulate(ctx, tx, overrides, block, state)
+	if err != nil {
+		return fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	if len(sim.RevertData) > 0 {
+		sim.Revert = contract.DecodeRevert(sim.RevertData)
+	}
+
+	// Failures past this point are outcomes, not usage mistakes
+	cmd.SilenceUsage = true
+
+	if err := getContext(cmd).Print(cmd, sim); err != nil {
+		return err
+	}
+	if !sim.Success {
+		return fmt.Errorf("simulated transaction failed: %s", simulationError(sim))
+	}
+	return nil
+}
+
+// simulationError describes why a simulated transaction failed. Nodes
+// include Error(string) reasons in the message themselves.
+func simulationError(sim *types.Simulation) string {
+	if sim.Revert != "" && !strings.HasSuffix(sim.Error, sim.Revert) {
+		return sim.Error + ": " + sim.Revert
+	}
+	return sim.Error
+}
+
+// stateOverridesFromFlags collects --override-balance and --override-code
+func stateOverridesFromFlags(cmd *cobra.Command) (types.StateOverrides, error) {
+	state := make(types.StateOverrides)
+	account := func(flag, value string) (*types.AccountOverride, string, error) {
+		address, v, ok := strings.Cut(value, "=")
+		if !ok || !common.IsHexAddress(address) {
+			return nil, "", fmt.Errorf("invalid --%s %q: expected address=value", flag, value)
+		}
+		key := common.HexToAddress(address).Hex()
+		if state[key] == nil {
+			state[key] = &types.AccountOverride{}
+		}
+		return state[key], v, nil
+	}
+
+	balances, _ := cmd.Flags().GetStringArray("override-balance")
+	for _, value := range balances {
+		override, v, err := account("override-balance", value)
+		if err != nil {
+			return nil, err
+		}
+		if override.Balance, err = types.ParseBig(v); err != nil {
+			return nil, fmt.Errorf("invalid --override-balance %q: %w", value, err)
+		}
+	}
+
+	codes, _ := cmd.Flags().GetStringArray("override-code")
+	for _, value := range codes {
+		override, v, err := account("override-code", value)
+		if err != nil {
+			return nil, err
+		}
+		if override.Code, err = readBytecode(v); err != nil {
+			return nil, fmt.Errorf("invalid --override-code %q: %w", value, err)
+		}
+	}
+
+	return state, nil
+}
+
+// parseData decodes 0x-prefixed hex call data. Other text is used as is.
+func parseData(data string) ([]byte, error) {
+	if !strings.HasPrefix(data, "0x") && !strings.HasPrefix(data, "0X") {
+		return []byte(data), nil
+	}
+	b, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --data: %w", err)
+	}
+	return b, nil
+}
+
+// addTxOverrideFlags registers the flags read by txOverridesFromFlags
+func addTxOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("nonce", 0, "Override the transaction nonce")
+	cmd.Flags().Uint64("gas", 0, "Override the gas limit")
+	cmd.Flags().String("gas-price", "", "Legacy gas price in wei (implies --legacy)")
+	cmd.Flags().String("max-fee", "", "EIP-1559 max fee per gas in wei")
+	cmd.Flags().String("max-priority-fee", "", "EIP-1559 max priority fee per gas in wei")
+	cmd.Flags().Bool("legacy", false, "Send a legacy EIP-155 transaction instead of EIP-1559")
+}
+
+// txOverridesFromFlags collects the override flags that were set explicitly
+func txOverridesFromFlags(cmd *cobra.Command) (*rpc.TxOverrides, error) {
+	flags := cmd.Flags()
+	overrides := &rpc.TxOverrides{}
+
+	if flags.Changed("nonce") {
+		nonce, _ := flags.GetUint64("nonce")
+		overrides.Nonce = &nonce
+	}
+	if flags.Changed("gas") {
+		gas, _ := flags.GetUint64("gas")
+		overrides.Gas = &gas
+	}
+	overrides.Legacy, _ = flags.GetBool("legacy")
+
+	// The gas policy of the network fills in fee flags that were not given
+	policy := getContext(cmd).Config.Gas
+	if !flags.Changed("legacy") && !flags.Changed("max-fee") && !flags.Changed("max-priority-fee") {
+		overrides.Legacy = policy.Legacy
+	}
+	for name, dst := range map[string]**big.Int{
+		"gas-price":        &overrides.GasPrice,
+		"max-fee":          &overrides.MaxFeePerGas,
+		"max-priority-fee": &overrides.MaxPriorityFeePerGas,
+	} {
+		raw, _ := flags.GetString(name)
+		source := "--" + name
+		if !flags.Changed(name) {
+			switch name {
+			case "max-fee":
+				raw, source = policy.MaxFee, "gas.max_fee"
+			case "max-priority-fee":
+				raw, source = policy.MaxPriorityFee, "gas.max_priority_fee"
+			}
+			if raw == "" || flags.Changed("gas-price") {
+				continue
 			}
+		}
+		v, err := types.ParseBig(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", source, err)
+		}
+		*dst = v
+	}
+
+	return overrides, nil
+}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), txs)
+func newListTransactionsCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent transactions",
+		Long: `List transactions from the local chain index, newest first.
+
+Without an index, the transactions of the last few blocks are read from the
+node instead; --address always requires the index (see 'index run').`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			address, _ := cmd.Flags().GetString("address")
+
+			var txs []*types.Transaction
+			store, err := openIndex(cmd)
+			switch {
+			case err == nil:
+				defer store.Close()
+				limit, _ := cmd.Flags().GetInt("limit")
+				offset, _ := cmd.Flags().GetInt("offset")
+				txs, err = store.Transactions(ctx, indexer.TxQuery{
+					Address: address,
+					Limit:   limit,
+					Offset:  offset,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to list transactions: %w", err)
+				}
+			case errors.Is(err, errNoIndex) && address == "":
+				client, err := getContext(cmd).Client()
+				if err != nil {
+					return err
+				}
+
+				txs, err = client.ListTransactions(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to list transactions: %w", err)
+				}
+			default:
+				return err
+			}
+
+			return getContext(cmd).Print(cmd, txs)
 		},
 	}
+
+	listCmd.Flags().String("address", "", "Only transactions sent from, sent to or creating this address")
+	addPageFlags(listCmd)
+
+	return listCmd
 }
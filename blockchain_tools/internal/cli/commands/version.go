@@ -5,7 +5,6 @@ import (
 	"runtime"
 	"sync"
 
-	"github.com/layla-lili/blockchain_tools/internal/cli/formatter"
 	"github.com/spf13/cobra"
 )
 
@@ -45,9 +44,7 @@ func newVersionCmd() *cobra.Command {
 				Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 			}
 
-			format, _ := cmd.Flags().GetString("format")
-			fmt := formatter.GetFormatter(format)
-			return fmt.Format(cmd.OutOrStdout(), versionInfo)
+			return getContext(cmd).Print(cmd, versionInfo)
 		},
 	}
 }
@@ -5,27 +5,168 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-// Config holds the application configuration
+// envPrefix is prepended to configuration keys to form environment
+// variable names, such as BLOCKCHAIN_RPC_URL
+const envPrefix = "BLOCKCHAIN"
+
+// flagKeys maps the global flags to the settings they override
+var flagKeys = map[string]string{
+	"rpc-url": "rpc_url",
+	"api":     "api_url",
+	"network": "network",
+	"format":  "format",
+	"debug":   "debug",
+}
+
+// bound holds the flags bound with BindFlags, by setting
+var bound = map[string]*pflag.Flag{}
+
+// Config holds the application configuration. The fields of Network may be
+// set at the top level of the config file and are overridden by the
+// profile selected with network.
 type Config struct {
-	RpcURL    string `mapstructure:"rpc_url"`
-	Format    string `mapstructure:"format"`
-	Debug     bool   `mapstructure:"debug"`
-	KeyFile   string `mapstructure:"key_file"`
-	APIKey    string `mapstructure:"api_key"`
+	Network    `mapstructure:",squash"`
+	Active     string              `mapstructure:"network"`
+	Networks   map[string]*Network `mapstructure:"networks"`
+	Format     string              `mapstructure:"format"`
+	Debug      bool                `mapstructure:"debug"`
+	KeyFile    string              `mapstructure:"key_file"`
+	APIKey     string              `mapstructure:"api_key"`
+	IndexDB    string              `mapstructure:"index_db"`
+	IndexStart uint64              `mapstructure:"index_start"`
 }
 
-// GetConfig returns the current configuration
-func GetConfig() *Config {
+// Network is a named profile for one chain, such as local, staging or
+// mainnet-fork
+type Network struct {
+	RpcURL         string    `mapstructure:"rpc_url"`
+	WsURL          string    `mapstructure:"ws_url"`
+	APIURL         string    `mapstructure:"api_url"`
+	ChainID        uint64    `mapstructure:"chain_id"`
+	ExplorerURL    string    `mapstructure:"explorer_url"`
+	DefaultAccount string    `mapstructure:"default_account"`
+	Gas            GasPolicy `mapstructure:"gas"`
+}
+
+// GasPolicy holds the defaults used for transactions when the matching
+// flags are not given. Fees are in wei.
+type GasPolicy struct {
+	Multiplier     float64 `mapstructure:"multiplier"`
+	MaxFee         string  `mapstructure:"max_fee"`
+	MaxPriorityFee string  `mapstructure:"max_priority_fee"`
+	Legacy         bool    `mapstructure:"legacy"`
+}
+
+// BindFlags makes the global flags in flags override the matching
+// settings. Each setting is then resolved in this order: a flag given on
+// the command line, a BLOCKCHAIN_* environment variable, the active network
+// profile, the config file and finally the defaults.
+func BindFlags(flags *pflag.FlagSet) {
+	for name, key := range flagKeys {
+		if f := flags.Lookup(name); f != nil {
+			viper.BindPFlag(key, f)
+			bound[key] = f
+		}
+	}
+}
+
+// Load resolves the configuration, with the active network profile
+// applied
+func Load() (*Config, error) {
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
-		fmt.Printf("Error unmarshalling config: %s\n", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if config.Active != "" {
+		n, err := config.Profile(config.Active)
+		if err != nil {
+			return &config, err
+		}
+		config.apply(n)
+	}
+	return &config, nil
+}
+
+// GetConfig returns the current configuration as Load does, ignoring an
+// unknown network
+func GetConfig() *Config {
+	config, err := Load()
+	if config == nil {
+		fmt.Fprintf(os.Stderr, "Error unmarshalling config: %s\n", err)
+		return &Config{}
+	}
+	return config
+}
+
+// Profile returns the network profile called name
+func (c *Config) Profile(name string) (*Network, error) {
+	n, ok := c.Networks[name]
+	if !ok || n == nil {
+		return nil, fmt.Errorf("unknown network %q (known: %s)", name, strings.Join(c.NetworkNames(), ", "))
+	}
+	return n, nil
+}
+
+// NetworkNames returns the names of the configured profiles in order
+func (c *Config) NetworkNames() []string {
+	names := make([]string, 0, len(c.Networks))
+	for name := range c.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply overrides the top-level network settings with those set in n.
+// Settings given as flags or in the environment keep precedence over the
+// profile.
+func (c *Config) apply(n *Network) {
+	set := func(key string) bool {
+		if f := bound[key]; f != nil && f.Changed {
+			return false
+		}
+		_, inEnv := os.LookupEnv(envPrefix + "_" + strings.ToUpper(key))
+		return !inEnv
+	}
+
+	if n.RpcURL != "" && set("rpc_url") {
+		c.RpcURL = n.RpcURL
+	}
+	if n.WsURL != "" && set("ws_url") {
+		c.WsURL = n.WsURL
+	}
+	if n.APIURL != "" && set("api_url") {
+		c.APIURL = n.APIURL
+	}
+	if n.ChainID != 0 && set("chain_id") {
+		c.ChainID = n.ChainID
+	}
+	if n.ExplorerURL != "" && set("explorer_url") {
+		c.ExplorerURL = n.ExplorerURL
+	}
+	if n.DefaultAccount != "" && set("default_account") {
+		c.DefaultAccount = n.DefaultAccount
+	}
+	if n.Gas.Multiplier != 0 {
+		c.Gas.Multiplier = n.Gas.Multiplier
+	}
+	if n.Gas.MaxFee != "" {
+		c.Gas.MaxFee = n.Gas.MaxFee
+	}
+	if n.Gas.MaxPriorityFee != "" {
+		c.Gas.MaxPriorityFee = n.Gas.MaxPriorityFee
+	}
+	if n.Gas.Legacy {
+		c.Gas.Legacy = true
 	}
-	return &config
 }
 
 // InitConfig initializes the configuration
@@ -47,19 +188,39 @@ func InitConfig(cfgFile string) {
 	}
 
 	// Read environment variables
-	viper.SetEnvPrefix("BLOCKCHAIN")
+	viper.SetEnvPrefix(envPrefix)
 	viper.AutomaticEnv()
 
 	// Set defaults
 	viper.SetDefault("rpc_url", "http://localhost:8545")
+	// Keys without a default are not read from the environment
+	viper.SetDefault("network", "")
+	viper.SetDefault("ws_url", "")
+	viper.SetDefault("api_url", "")
+	viper.SetDefault("chain_id", 0)
+	viper.SetDefault("explorer_url", "")
+	viper.SetDefault("default_account", "")
+	viper.SetDefault("api_key", "")
 	viper.SetDefault("format", "table")
 	viper.SetDefault("debug", false)
 	viper.SetDefault("key_file", filepath.Join(homeDir(), ".blockchain-cli", "keys.json"))
+	viper.SetDefault("index_db", filepath.Join(homeDir(), ".blockchain-cli", "index.db"))
+	viper.SetDefault("index_start", 0)
 
-	// If a config file is found, read it in
+	// If a config file is found, read it in. The notice goes to stderr so
+	// it does not mix with JSON or YAML output.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// ConfigFile returns the config file in use, or the default location when
+// none was found
+func ConfigFile() string {
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file
 	}
+	return filepath.Join(homeDir(), ".blockchain-cli.yaml")
 }
 
 // homeDir returns the user's home directory
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// testFile has top-level settings and two profiles, with local active
+const testFile = `
+rpc_url: http://file:8545
+ws_url: ws://file:8546
+format: yaml
+gas:
+  multiplier: 1.5
+  max_fee: "100"
+network: local
+networks:
+  local:
+    rpc_url: http://local:8545
+    ws_url: ws://local:8546
+    api_url: http://local:8080
+    chain_id: 1337
+    gas:
+      max_fee: "200"
+  staging:
+    rpc_url: http://staging:8545
+    chain_id: 5
+`
+
+// setting is one source of a configuration value
+type setting struct {
+	// file is the config file, none when empty
+	file string
+	// env holds BLOCKCHAIN_* variables by key, such as rpc_url
+	env map[string]string
+	// flags holds the global flags given on the command line
+	flags []string
+}
+
+// load resolves the configuration from s the way the root command does
+func load(t *testing.T, s setting) (*Config, error) {
+	t.Helper()
+	viper.Reset()
+	bound = map[string]*pflag.Flag{}
+	t.Cleanup(func() {
+		viper.Reset()
+		bound = map[string]*pflag.Flag{}
+	})
+
+	for key := range flagKeys {
+		clearEnv(t, key)
+	}
+	for _, key := range []string{"ws_url", "api_url", "chain_id"} {
+		clearEnv(t, key)
+	}
+	for key, value := range s.env {
+		t.Setenv(envName(key), value)
+	}
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(s.file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	InitConfig(file)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("rpc-url", "", "")
+	flags.String("api", "", "")
+	flags.String("network", "", "")
+	flags.String("format", "table", "")
+	flags.Bool("debug", false, "")
+	if err := flags.Parse(s.flags); err != nil {
+		t.Fatal(err)
+	}
+	BindFlags(flags)
+	return Load()
+}
+
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(key)
+}
+
+// clearEnv unsets the variable of key for the duration of the test
+func clearEnv(t *testing.T, key string) {
+	t.Setenv(envName(key), "")
+	os.Unsetenv(envName(key))
+}
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting setting
+		rpcURL  string
+		wsURL   string
+		chainID uint64
+		format  string
+	}{
+		{
+			name:    "default",
+			setting: setting{},
+			rpcURL:  "http://localhost:8545",
+			format:  "table",
+		},
+		{
+			name:    "file over default",
+			setting: setting{file: "rpc_url: http://file:8545\nformat: yaml\n"},
+			rpcURL:  "http://file:8545",
+			format:  "yaml",
+		},
+		{
+			name:    "profile over file",
+			setting: setting{file: testFile},
+			rpcURL:  "http://local:8545",
+			wsURL:   "ws://local:8546",
+			chainID: 1337,
+			format:  "yaml",
+		},
+		{
+			name: "env over profile",
+			setting: setting{file: testFile, env: map[string]string{
+				"rpc_url": "http://env:8545",
+				"ws_url":  "ws://env:8546",
+				"format":  "json",
+			}},
+			rpcURL:  "http://env:8545",
+			wsURL:   "ws://env:8546",
+			chainID: 1337,
+			format:  "json",
+		},
+		{
+			name: "flag over env",
+			setting: setting{
+				file:  testFile,
+				env:   map[string]string{"rpc_url": "http://env:8545", "format": "json"},
+				flags: []string{"--rpc-url", "http://flag:8545", "--format", "jsonl"},
+			},
+			rpcURL:  "http://flag:8545",
+			wsURL:   "ws://local:8546",
+			chainID: 1337,
+			format:  "jsonl",
+		},
+		{
+			name:    "flag over profile",
+			setting: setting{file: testFile, flags: []string{"--rpc-url", "http://flag:8545"}},
+			rpcURL:  "http://flag:8545",
+			wsURL:   "ws://local:8546",
+			chainID: 1337,
+			format:  "yaml",
+		},
+		{
+			name:    "profile selected in env",
+			setting: setting{file: testFile, env: map[string]string{"network": "staging"}},
+			rpcURL:  "http://staging:8545",
+			wsURL:   "ws://file:8546",
+			chainID: 5,
+			format:  "yaml",
+		},
+		{
+			name: "profile selected by flag over env",
+			setting: setting{
+				file:  testFile,
+				env:   map[string]string{"network": "local"},
+				flags: []string{"--network", "staging"},
+			},
+			rpcURL:  "http://staging:8545",
+			wsURL:   "ws://file:8546",
+			chainID: 5,
+			format:  "yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.setting)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.RpcURL != tt.rpcURL {
+				t.Errorf("RpcURL = %q, want %q", cfg.RpcURL, tt.rpcURL)
+			}
+			if cfg.WsURL != tt.wsURL {
+				t.Errorf("WsURL = %q, want %q", cfg.WsURL, tt.wsURL)
+			}
+			if cfg.ChainID != tt.chainID {
+				t.Errorf("ChainID = %d, want %d", cfg.ChainID, tt.chainID)
+			}
+			if cfg.Format != tt.format {
+				t.Errorf("Format = %q, want %q", cfg.Format, tt.format)
+			}
+		})
+	}
+}
+
+func TestProfileGas(t *testing.T) {
+	cfg, err := load(t, setting{file: testFile})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// The profile overrides max_fee and keeps the top-level multiplier
+	if cfg.Gas.MaxFee != "200" {
+		t.Errorf("Gas.MaxFee = %q, want 200", cfg.Gas.MaxFee)
+	}
+	if cfg.Gas.Multiplier != 1.5 {
+		t.Errorf("Gas.Multiplier = %v, want 1.5", cfg.Gas.Multiplier)
+	}
+}
+
+func TestUnknownNetwork(t *testing.T) {
+	cfg, err := load(t, setting{file: testFile, flags: []string{"--network", "mainnet"}})
+	if err == nil {
+		t.Fatal("Load succeeded with an unknown network")
+	}
+	// The rest of the configuration is still resolved
+	if cfg == nil || cfg.RpcURL != "http://file:8545" {
+		t.Errorf("Load returned %+v, want the file settings", cfg)
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting setting
+		want    string
+	}{
+		{"default", setting{}, ""},
+		{"profile", setting{file: testFile}, "http://local:8080"},
+		{"env over profile", setting{file: testFile, env: map[string]string{"api_url": "http://env:8080"}}, "http://env:8080"},
+		{"flag over env", setting{
+			file:  testFile,
+			env:   map[string]string{"api_url": "http://env:8080"},
+			flags: []string{"--api", "http://flag:8080"},
+		}, "http://flag:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.setting)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.APIURL != tt.want {
+				t.Errorf("APIURL = %q, want %q", cfg.APIURL, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// internal/cli/config/file.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of setting values
+const (
+	kindString = iota
+	kindUint
+	kindFloat
+	kindBool
+)
+
+// settings lists the keys that may be written with Set, relative to the
+// top level or to a network profile, with the kind of their values
+var settings = map[string]int{
+	"rpc_url":              kindString,
+	"ws_url":               kindString,
+	"api_url":              kindString,
+	"chain_id":             kindUint,
+	"explorer_url":         kindString,
+	"default_account":      kindString,
+	"gas.multiplier":       kindFloat,
+	"gas.max_fee":          kindString,
+	"gas.max_priority_fee": kindString,
+	"gas.legacy":           kindBool,
+}
+
+// topLevel lists the keys that are only valid at the top level
+var topLevel = map[string]int{
+	"network":     kindString,
+	"format":      kindString,
+	"debug":       kindBool,
+	"key_file":    kindString,
+	"api_key":     kindString,
+	"index_db":    kindString,
+	"index_start": kindUint,
+}
+
+// Set writes key to the YAML config file, keeping the rest of the file as
+// it is. Keys are dotted paths such as rpc_url or networks.staging.chain_id.
+func Set(key, value string) error {
+	path := strings.Split(key, ".")
+	kind, ok := settingKind(path)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	parsed, err := parseValue(kind, value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	file := ConfigFile()
+	if ext := filepath.Ext(file); ext != ".yaml" && ext != ".yml" {
+		return fmt.Errorf("only YAML config files can be changed, not %s", file)
+	}
+	doc, err := readFile(file)
+	if err != nil {
+		return err
+	}
+	doc = setPath(doc, path, parsed)
+	return writeFile(file, doc)
+}
+
+// settingKind returns the kind of the setting at path, which is top-level or
+// networks.<name>.<setting>
+func settingKind(path []string) (int, bool) {
+	key := strings.Join(path, ".")
+	if kind, ok := topLevel[key]; ok {
+		return kind, true
+	}
+	if kind, ok := settings[key]; ok {
+		return kind, true
+	}
+	if len(path) > 2 && path[0] == "networks" && path[1] != "" {
+		kind, ok := settings[strings.Join(path[2:], ".")]
+		return kind, ok
+	}
+	return 0, false
+}
+
+func parseValue(kind int, value string) (interface{}, error) {
+	switch kind {
+	case kindUint:
+		return strconv.ParseUint(value, 10, 64)
+	case kindFloat:
+		return strconv.ParseFloat(value, 64)
+	case kindBool:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+// setPath sets the value at path in doc, creating maps along the way
+func setPath(doc yaml.MapSlice, path []string, value interface{}) yaml.MapSlice {
+	for i := range doc {
+		if doc[i].Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			doc[i].Value = value
+			return doc
+		}
+		child, _ := doc[i].Value.(yaml.MapSlice)
+		doc[i].Value = setPath(child, path[1:], value)
+		return doc
+	}
+
+	if len(path) == 1 {
+		return append(doc, yaml.MapItem{Key: path[0], Value: value})
+	}
+	return append(doc, yaml.MapItem{Key: path[0], Value: setPath(nil, path[1:], value)})
+}
+
+// readFile reads the config file, preserving the order of its keys. A
+// missing file reads as empty.
+func readFile(file string) (yaml.MapSlice, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", file, err)
+	}
+	return doc, nil
+}
+
+func writeFile(file string, doc yaml.MapSlice) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -15,6 +15,8 @@ func GetFormatter(format string) Formatter {
 	switch format {
 	case "json":
 		return NewJSONFormatter()
+	case "jsonl":
+		return NewJSONLinesFormatter()
 	case "yaml":
 		return NewYAMLFormatter()
 	default:
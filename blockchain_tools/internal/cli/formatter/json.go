@@ -19,6 +19,12 @@ func NewJSONFormatter() *JSONFormatter {
 	}
 }
 
+// NewJSONLinesFormatter creates a JSON formatter that writes each value on a
+// single line, suitable for streaming output
+func NewJSONLinesFormatter() *JSONFormatter {
+	return &JSONFormatter{}
+}
+
 // Format formats the data as JSON and writes it to the writer
 func (f *JSONFormatter) Format(w io.Writer, data interface{}) error {
 	encoder := json.NewEncoder(w)
@@ -0,0 +1,137 @@
+// internal/cli/formatter/stream.go
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// tableFlushRows is how many rows a table stream aligns at a time
+const tableFlushRows = 100
+
+// Stream writes a list one item at a time, so that long results are printed
+// as they arrive instead of being held in memory. The output matches what
+// the formatter of the same format writes for the whole list.
+type Stream interface {
+	Write(item interface{}) error
+	// Close ends the list
+	Close() error
+}
+
+// NewStream returns a stream writing a list to w in the specified format
+func NewStream(format string, w io.Writer) Stream {
+	switch format {
+	case "json":
+		return &jsonStream{w: w}
+	case "jsonl":
+		return &jsonLinesStream{encoder: json.NewEncoder(w)}
+	case "yaml":
+		return &yamlStream{w: w}
+	default:
+		return &tableStream{w: w, tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	}
+}
+
+// jsonStream writes an indented JSON array
+type jsonStream struct {
+	w     io.Writer
+	count int
+}
+
+func (s *jsonStream) Write(item interface{}) error {
+	data, err := json.MarshalIndent(item, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if s.count == 0 {
+		sep = "[\n  "
+	}
+	s.count++
+	_, err = fmt.Fprintf(s.w, "%s%s", sep, data)
+	return err
+}
+
+func (s *jsonStream) Close() error {
+	if s.count == 0 {
+		_, err := io.WriteString(s.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(s.w, "\n]\n")
+	return err
+}
+
+// jsonLinesStream writes one JSON object per line
+type jsonLinesStream struct {
+	encoder *json.Encoder
+}
+
+func (s *jsonLinesStream) Write(item interface{}) error {
+	return s.encoder.Encode(item)
+}
+
+func (s *jsonLinesStream) Close() error {
+	return nil
+}
+
+// yamlStream writes a YAML sequence
+type yamlStream struct {
+	w     io.Writer
+	count int
+}
+
+func (s *yamlStream) Write(item interface{}) error {
+	data, err := yaml.Marshal([]interface{}{item})
+	if err != nil {
+		return err
+	}
+	s.count++
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *yamlStream) Close() error {
+	if s.count == 0 {
+		_, err := io.WriteString(s.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// tableStream writes table rows, aligning them in groups of tableFlushRows.
+// Types without a row format are written as separate tables.
+type tableStream struct {
+	w    io.Writer
+	tw   *tabwriter.Writer
+	rows int
+}
+
+func (s *tableStream) Write(item interface{}) error {
+	block, ok := item.(*types.Block)
+	if !ok {
+		if err := s.tw.Flush(); err != nil {
+			return err
+		}
+		return NewTableFormatter().Format(s.w, item)
+	}
+
+	if s.rows == 0 {
+		fmt.Fprintln(s.tw, "BLOCKS:")
+		fmt.Fprintln(s.tw, "HEIGHT\tHASH\tTIMESTAMP\tTX COUNT\tSIZE")
+	}
+	writeBlockRow(s.tw, block)
+	s.rows++
+	if s.rows%tableFlushRows == 0 {
+		return s.tw.Flush()
+	}
+	return nil
+}
+
+func (s *tableStream) Close() error {
+	return s.tw.Flush()
+}
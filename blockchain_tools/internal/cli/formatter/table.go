@@ -4,6 +4,7 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -32,6 +33,54 @@ func (f *TableFormatter) Format(w io.Writer, data interface{}) error {
 		return f.formatBlocks(tw, v)
 	case []*types.Transaction:
 		return f.formatTransactions(tw, v)
+	case *types.Account:
+		return f.formatAccounts(tw, []*types.Account{v})
+	case []*types.Account:
+		return f.formatAccounts(tw, v)
+	case *types.Mnemonic:
+		return f.formatMnemonic(tw, v)
+	case *types.Receipt:
+		return f.formatReceipt(tw, v)
+	case *types.Balance:
+		return f.formatBalance(tw, v)
+	case *types.NodeStatus:
+		return f.formatNodeStatus(tw, v)
+	case []*types.Peer:
+		return f.formatPeers(tw, v)
+	case []*types.Endpoint:
+		return f.formatEndpoints(tw, v)
+	case *types.SyncStatus:
+		return f.formatSyncStatus(tw, v)
+	case *types.IndexStatus:
+		return f.formatIndexStatus(tw, v)
+	case *types.Event:
+		return f.formatEvent(w, v)
+	case *types.CallResult:
+		return f.formatCallResult(tw, v)
+	case []*types.Log:
+		return f.formatLogs(tw, v)
+	case *types.Token:
+		return f.formatToken(tw, v)
+	case *types.TokenBalance:
+		return f.formatTokenBalances(tw, []*types.TokenBalance{v})
+	case []*types.TokenBalance:
+		return f.formatTokenBalances(tw, v)
+	case *types.NFT:
+		return f.formatNFT(tw, v)
+	case *types.Trace:
+		return f.formatTrace(tw, v)
+	case *types.Simulation:
+		return f.formatSimulation(tw, v)
+	case []*types.BatchResult:
+		return f.formatBatchResults(tw, v)
+	case *types.Network:
+		return f.formatNetwork(tw, v)
+	case []*types.Network:
+		return f.formatNetworks(tw, v)
+	case *types.Signature:
+		return f.formatSignature(tw, v)
+	case *types.Verification:
+		return f.formatVerification(tw, v)
 	default:
 		return fmt.Errorf("unsupported data type: %T", data)
 	}
@@ -54,11 +103,11 @@ func (f *TableFormatter) formatBlock(w io.Writer, block *types.Block) error {
 		fmt.Fprintln(tw, "HASH\tFROM\tTO\tVALUE")
 
 		for _, tx := range block.Transactions {
-			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n",
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
 				tx.Hash,
 				truncateString(tx.From, 8),
 				truncateString(tx.To, 8),
-				tx.Value)
+				types.FormatBig(tx.Value))
 		}
 	}
 
@@ -73,7 +122,15 @@ func (f *TableFormatter) formatTransaction(w io.Writer, tx *types.Transaction) e
 	fmt.Fprintf(tw, "Hash:\t%s\n", tx.Hash)
 	fmt.Fprintf(tw, "From:\t%s\n", tx.From)
 	fmt.Fprintf(tw, "To:\t%s\n", tx.To)
-	fmt.Fprintf(tw, "Value:\t%d\n", tx.Value)
+	fmt.Fprintf(tw, "Value:\t%s\n", types.FormatBig(tx.Value))
+	fmt.Fprintf(tw, "Nonce:\t%d\n", tx.Nonce)
+	fmt.Fprintf(tw, "Gas:\t%d\n", tx.Gas)
+	if tx.MaxFeePerGas != nil {
+		fmt.Fprintf(tw, "Max Fee Per Gas:\t%s\n", types.FormatBig(tx.MaxFeePerGas))
+		fmt.Fprintf(tw, "Max Priority Fee:\t%s\n", types.FormatBig(tx.MaxPriorityFeePerGas))
+	} else {
+		fmt.Fprintf(tw, "Gas Price:\t%s\n", types.FormatBig(tx.GasPrice))
+	}
 	fmt.Fprintf(tw, "Status:\t%s\n", tx.Status)
 	fmt.Fprintf(tw, "Block Hash:\t%s\n", tx.BlockHash)
 	fmt.Fprintf(tw, "Timestamp:\t%d\n", tx.Timestamp)
@@ -85,6 +142,44 @@ func (f *TableFormatter) formatTransaction(w io.Writer, tx *types.Transaction) e
 	return tw.Flush()
 }
 
+// formatReceipt formats a transaction receipt and its logs
+func (f *TableFormatter) formatReceipt(w io.Writer, receipt *types.Receipt) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TRANSACTION RECEIPT:")
+	fmt.Fprintf(tw, "Hash:\t%s\n", receipt.TransactionHash)
+	fmt.Fprintf(tw, "Status:\t%s\n", receipt.Status)
+
+	if receipt.ReplacedBy != "" {
+		fmt.Fprintf(tw, "Replaced By:\t%s\n", receipt.ReplacedBy)
+	}
+	if receipt.BlockHash == "" {
+		return tw.Flush()
+	}
+
+	fmt.Fprintf(tw, "Block Number:\t%d\n", receipt.BlockNumber)
+	fmt.Fprintf(tw, "Block Hash:\t%s\n", receipt.BlockHash)
+	fmt.Fprintf(tw, "Confirmations:\t%d\n", receipt.Confirmations)
+	fmt.Fprintf(tw, "Gas Used:\t%d\n", receipt.GasUsed)
+	fmt.Fprintf(tw, "Effective Gas Price:\t%s\n", types.FormatBig(receipt.EffectiveGasPrice))
+
+	if receipt.ContractAddress != "" {
+		fmt.Fprintf(tw, "Contract Address:\t%s\n", receipt.ContractAddress)
+	}
+
+	if len(receipt.Logs) > 0 {
+		fmt.Fprintln(tw, "\nLOGS:")
+		fmt.Fprintln(tw, "INDEX\tADDRESS\tEVENT\tDATA")
+
+		for _, l := range receipt.Logs {
+			event, data := logSummary(l)
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", l.LogIndex, l.Address, event, data)
+		}
+	}
+
+	return tw.Flush()
+}
+
 // formatTransactions formats multiple transactions
 func (f *TableFormatter) formatTransactions(w io.Writer, txs []*types.Transaction) error {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
@@ -92,11 +187,11 @@ func (f *TableFormatter) formatTransactions(w io.Writer, txs []*types.Transactio
 	fmt.Fprintln(tw, "HASH\tFROM\tTO\tVALUE\tSTATUS")
 
 	for _, tx := range txs {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n",
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
 			truncateString(tx.Hash, 12),
 			truncateString(tx.From, 8),
 			truncateString(tx.To, 8),
-			tx.Value,
+			types.FormatBig(tx.Value),
 			tx.Status)
 	}
 
@@ -113,17 +208,514 @@ func (f *TableFormatter) formatBlocks(w io.Writer, blocks []*types.Block) error
 
 	// Print each block as a row
 	for _, block := range blocks {
-		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d bytes\n",
-			block.Height,
-			truncateString(block.Hash, 12),
-			time.Unix(block.Timestamp, 0).Format(time.RFC3339),
-			len(block.Transactions),
-			block.Size)
+		writeBlockRow(tw, block)
+	}
+
+	return tw.Flush()
+}
+
+// writeBlockRow writes the row of block in the blocks table
+func writeBlockRow(w io.Writer, block *types.Block) {
+	fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d bytes\n",
+		block.Height,
+		truncateString(block.Hash, 12),
+		time.Unix(block.Timestamp, 0).Format(time.RFC3339),
+		len(block.Transactions),
+		block.Size)
+}
+
+// formatAccounts formats a list of accounts
+func (f *TableFormatter) formatAccounts(w io.Writer, accounts []*types.Account) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	// Derivation paths are only shown for local keys derived from a mnemonic
+	withPath := false
+	for _, acc := range accounts {
+		withPath = withPath || acc.Path != ""
+	}
+
+	if withPath {
+		fmt.Fprintln(tw, "ADDRESS\tBALANCE\tNONCE\tPATH")
+	} else {
+		fmt.Fprintln(tw, "ADDRESS\tBALANCE\tNONCE")
+	}
+
+	for _, acc := range accounts {
+		if withPath {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", acc.Address, types.FormatBig(acc.Balance), acc.Nonce, acc.Path)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", acc.Address, types.FormatBig(acc.Balance), acc.Nonce)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// formatMnemonic formats a new mnemonic and its first account
+func (f *TableFormatter) formatMnemonic(w io.Writer, m *types.Mnemonic) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Mnemonic:\t%s\n", m.Phrase)
+	fmt.Fprintf(tw, "Address:\t%s\n", m.Account.Address)
+	fmt.Fprintf(tw, "Path:\t%s\n", m.Account.Path)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Write the mnemonic down and keep it safe: it is the only backup of every")
+	fmt.Fprintln(tw, "account derived from it and is not shown again.")
+
+	return tw.Flush()
+}
+
+// formatBalance formats the balance of a single address
+func (f *TableFormatter) formatBalance(w io.Writer, balance *types.Balance) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Address:\t%s\n", balance.Address)
+	fmt.Fprintf(tw, "Balance:\t%s wei\n", types.FormatBig(balance.Amount))
+
+	return tw.Flush()
+}
+
+// formatNodeStatus formats the node status
+func (f *TableFormatter) formatNodeStatus(w io.Writer, status *types.NodeStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NODE STATUS:")
+	fmt.Fprintf(tw, "Client:\t%s\n", status.ClientVersion)
+	fmt.Fprintf(tw, "Network ID:\t%s\n", status.NetworkID)
+	fmt.Fprintf(tw, "Chain ID:\t%d\n", status.ChainID)
+	fmt.Fprintf(tw, "Block:\t%d\n", status.BlockNumber)
+	fmt.Fprintf(tw, "Peers:\t%d\n", status.PeerCount)
+	fmt.Fprintf(tw, "Listening:\t%t\n", status.Listening)
+	fmt.Fprintf(tw, "Syncing:\t%t\n", status.Syncing)
+
+	return tw.Flush()
+}
+
+// formatPeers formats the list of connected peers
+func (f *TableFormatter) formatPeers(w io.Writer, peers []*types.Peer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tREMOTE ADDRESS")
+
+	for _, peer := range peers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n",
+			truncateString(peer.ID, 12),
+			peer.Name,
+			peer.RemoteAddress)
+	}
+
+	return tw.Flush()
+}
+
+// formatEndpoints formats the health of RPC endpoints
+func (f *TableFormatter) formatEndpoints(w io.Writer, endpoints []*types.Endpoint) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "URL\tHEALTHY\tREQUESTS\tFAILURES\tAVG LATENCY\tLAST ERROR")
+
+	for _, e := range endpoints {
+		fmt.Fprintf(tw, "%s\t%t\t%d\t%d\t%.1fms\t%s\n",
+			e.URL,
+			e.Healthy,
+			e.Requests,
+			e.Failures,
+			e.AvgLatencyMs,
+			truncateString(e.LastError, 60))
+	}
+
+	return tw.Flush()
+}
+
+// formatSyncStatus formats the synchronization status
+func (f *TableFormatter) formatSyncStatus(w io.Writer, status *types.SyncStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SYNC STATUS:")
+	fmt.Fprintf(tw, "Syncing:\t%t\n", status.Syncing)
+	fmt.Fprintf(tw, "Starting Block:\t%d\n", status.StartingBlock)
+	fmt.Fprintf(tw, "Current Block:\t%d\n", status.CurrentBlock)
+	fmt.Fprintf(tw, "Highest Block:\t%d\n", status.HighestBlock)
+
+	return tw.Flush()
+}
+
+// formatIndexStatus formats the contents of the local chain index
+func (f *TableFormatter) formatIndexStatus(w io.Writer, status *types.IndexStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "INDEX STATUS:")
+	fmt.Fprintf(tw, "Start Height:\t%d\n", status.StartHeight)
+	fmt.Fprintf(tw, "Height:\t%d\n", status.Height)
+	fmt.Fprintf(tw, "Hash:\t%s\n", status.Hash)
+	fmt.Fprintf(tw, "Blocks:\t%d\n", status.Blocks)
+	fmt.Fprintf(tw, "Transactions:\t%d\n", status.Transactions)
+	fmt.Fprintf(tw, "Logs:\t%d\n", status.Logs)
+
+	return tw.Flush()
+}
+
+// formatCallResult formats the decoded return values of a contract call
+func (f *TableFormatter) formatCallResult(w io.Writer, result *types.CallResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Contract:\t%s\n", result.Contract)
+	fmt.Fprintf(tw, "Method:\t%s\n", result.Method)
+
+	if len(result.Outputs) > 0 {
+		fmt.Fprintln(tw, "\nOUTPUTS:")
+		fmt.Fprintln(tw, "NAME\tTYPE\tVALUE")
+		for i, out := range result.Outputs {
+			name := out.Name
+			if name == "" {
+				name = fmt.Sprintf("[%d]", i)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, out.Type, types.FormatValue(out.Value))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// formatToken formats the metadata of a token contract
+func (f *TableFormatter) formatToken(w io.Writer, token *types.Token) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Address:\t%s\n", token.Address)
+	fmt.Fprintf(tw, "Standard:\t%s\n", token.Standard)
+	fmt.Fprintf(tw, "Name:\t%s\n", token.Name)
+	fmt.Fprintf(tw, "Symbol:\t%s\n", token.Symbol)
+	if token.Standard == types.StandardERC20 {
+		fmt.Fprintf(tw, "Decimals:\t%d\n", token.Decimals)
+	}
+	if token.TotalSupply != nil {
+		fmt.Fprintf(tw, "Total Supply:\t%s\n", types.FormatUnits(token.TotalSupply, token.Decimals))
 	}
 
 	return tw.Flush()
 }
 
+// formatTokenBalances formats token balances in whole tokens
+func (f *TableFormatter) formatTokenBalances(w io.Writer, balances []*types.TokenBalance) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TOKEN\tSYMBOL\tACCOUNT\tBALANCE")
+
+	for _, b := range balances {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			b.Token,
+			b.Symbol,
+			b.Account,
+			b.Formatted())
+	}
+
+	return tw.Flush()
+}
+
+// formatNFT formats the owner of an ERC-721 token
+func (f *TableFormatter) formatNFT(w io.Writer, nft *types.NFT) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Token:\t%s\n", nft.Token)
+	fmt.Fprintf(tw, "Token ID:\t%s\n", types.FormatBig(nft.TokenID))
+	fmt.Fprintf(tw, "Owner:\t%s\n", nft.Owner)
+	if nft.TokenURI != "" {
+		fmt.Fprintf(tw, "Token URI:\t%s\n", nft.TokenURI)
+	}
+
+	return tw.Flush()
+}
+
+// formatLogs formats a list of logs, decoded where possible
+func (f *TableFormatter) formatLogs(w io.Writer, logs []*types.Log) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "BLOCK\tTX HASH\tINDEX\tADDRESS\tEVENT\tDATA")
+	for _, l := range logs {
+		event, data := logSummary(l)
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\t%s\n",
+			l.BlockNumber,
+			truncateString(l.TransactionHash, 12),
+			l.LogIndex,
+			l.Address,
+			event,
+			data)
+	}
+
+	return tw.Flush()
+}
+
+// formatBatchResults formats the state of every row of a batch send
+func (f *TableFormatter) formatBatchResults(w io.Writer, results []*types.BatchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ROW\tTO\tVALUE\tNONCE\tHASH\tSTATUS\tERROR")
+	for _, r := range results {
+		nonce := ""
+		if r.Nonce != nil {
+			nonce = fmt.Sprint(*r.Nonce)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Row,
+			r.To,
+			types.FormatBig(r.Value),
+			nonce,
+			r.Hash,
+			r.Status,
+			r.Error)
+	}
+
+	return tw.Flush()
+}
+
+// formatNetwork formats a single network profile
+func (f *TableFormatter) formatNetwork(w io.Writer, n *types.Network) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Network:\t%s\n", n.Name)
+	fmt.Fprintf(tw, "Active:\t%t\n", n.Active)
+	fmt.Fprintf(tw, "RPC URL:\t%s\n", n.RPCURL)
+	if n.WSURL != "" {
+		fmt.Fprintf(tw, "WS URL:\t%s\n", n.WSURL)
+	}
+	if n.APIURL != "" {
+		fmt.Fprintf(tw, "API URL:\t%s\n", n.APIURL)
+	}
+	if n.ChainID != 0 {
+		fmt.Fprintf(tw, "Chain ID:\t%d\n", n.ChainID)
+	}
+	if n.ExplorerURL != "" {
+		fmt.Fprintf(tw, "Explorer URL:\t%s\n", n.ExplorerURL)
+	}
+	if n.DefaultAccount != "" {
+		fmt.Fprintf(tw, "Default Account:\t%s\n", n.DefaultAccount)
+	}
+	if n.GasMultiplier != 0 {
+		fmt.Fprintf(tw, "Gas Multiplier:\t%g\n", n.GasMultiplier)
+	}
+	if n.MaxFee != "" {
+		fmt.Fprintf(tw, "Max Fee:\t%s wei\n", n.MaxFee)
+	}
+	if n.MaxPriorityFee != "" {
+		fmt.Fprintf(tw, "Max Priority Fee:\t%s wei\n", n.MaxPriorityFee)
+	}
+	if n.Legacy {
+		fmt.Fprintf(tw, "Legacy:\t%t\n", n.Legacy)
+	}
+
+	return tw.Flush()
+}
+
+// formatNetworks formats a list of network profiles, marking the active one
+func (f *TableFormatter) formatNetworks(w io.Writer, networks []*types.Network) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\tNAME\tCHAIN ID\tRPC URL\tDEFAULT ACCOUNT")
+
+	for _, n := range networks {
+		active, chainID := "", "-"
+		if n.Active {
+			active = "*"
+		}
+		if n.ChainID != 0 {
+			chainID = fmt.Sprint(n.ChainID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", active, n.Name, chainID, n.RPCURL, n.DefaultAccount)
+	}
+
+	return tw.Flush()
+}
+
+// formatSignature formats a message or typed data signature
+func (f *TableFormatter) formatSignature(w io.Writer, sig *types.Signature) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Address:\t%s\n", sig.Address)
+	fmt.Fprintf(tw, "Type:\t%s\n", sig.Type)
+	fmt.Fprintf(tw, "Hash:\t%s\n", sig.Hash)
+	fmt.Fprintf(tw, "Signature:\t%s\n", sig.Signature)
+
+	return tw.Flush()
+}
+
+// formatVerification formats the outcome of verifying a signature
+func (f *TableFormatter) formatVerification(w io.Writer, v *types.Verification) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Valid:\t%t\n", v.Valid)
+	fmt.Fprintf(tw, "Address:\t%s\n", v.Address)
+	fmt.Fprintf(tw, "Signer:\t%s\n", v.Signer)
+	fmt.Fprintf(tw, "Type:\t%s\n", v.Type)
+	fmt.Fprintf(tw, "Hash:\t%s\n", v.Hash)
+
+	return tw.Flush()
+}
+
+// formatSimulation formats the outcome of a simulated transaction and the
+// transaction as it would have been sent
+func (f *TableFormatter) formatSimulation(w io.Writer, sim *types.Simulation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SIMULATION:")
+	fmt.Fprintf(tw, "Block:\t%s\n", sim.Block)
+	fmt.Fprintf(tw, "Success:\t%t\n", sim.Success)
+	if sim.Error != "" {
+		fmt.Fprintf(tw, "Error:\t%s\n", sim.Error)
+	}
+	if sim.Revert != "" {
+		fmt.Fprintf(tw, "Revert:\t%s\n", sim.Revert)
+	}
+	if sim.GasEstimate > 0 {
+		fmt.Fprintf(tw, "Gas Estimate:\t%d\n", sim.GasEstimate)
+	}
+	if len(sim.ReturnData) > 0 {
+		fmt.Fprintf(tw, "Return Data:\t0x%x\n", sim.ReturnData)
+	}
+
+	if tx := sim.Transaction; tx != nil {
+		fmt.Fprintln(tw, "\nTRANSACTION:")
+		fmt.Fprintf(tw, "From:\t%s\n", tx.From)
+		fmt.Fprintf(tw, "To:\t%s\n", tx.To)
+		fmt.Fprintf(tw, "Value:\t%s wei\n", types.FormatBig(tx.Value))
+		fmt.Fprintf(tw, "Nonce:\t%d\n", tx.Nonce)
+		if tx.Gas > 0 {
+			fmt.Fprintf(tw, "Gas Limit:\t%d\n", tx.Gas)
+		}
+		if tx.GasPrice != nil {
+			fmt.Fprintf(tw, "Gas Price:\t%s wei\n", types.FormatBig(tx.GasPrice))
+		} else {
+			fmt.Fprintf(tw, "Max Fee:\t%s wei\n", types.FormatBig(tx.MaxFeePerGas))
+			fmt.Fprintf(tw, "Max Priority Fee:\t%s wei\n", types.FormatBig(tx.MaxPriorityFeePerGas))
+		}
+		if len(tx.Data) > 0 {
+			fmt.Fprintf(tw, "Data:\t%x\n", tx.Data)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// formatTrace formats a transaction trace as its call tree, indented by
+// call depth, followed by the executed opcodes when they were collected
+func (f *TableFormatter) formatTrace(w io.Writer, trace *types.Trace) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TRANSACTION TRACE:")
+	fmt.Fprintf(tw, "Hash:\t%s\n", trace.Hash)
+	fmt.Fprintf(tw, "Failed:\t%t\n", trace.Failed)
+	fmt.Fprintf(tw, "Gas Used:\t%d\n", trace.GasUsed)
+	if trace.Call != nil && trace.Call.Error != "" {
+		fmt.Fprintf(tw, "Error:\t%s\n", callResult(trace.Call))
+	}
+
+	fmt.Fprintln(tw, "\nCALLS:")
+	fmt.Fprintln(tw, "TYPE\tFROM\tTO\tMETHOD\tVALUE\tGAS\tGAS USED\tRESULT")
+	if trace.Call != nil {
+		formatCallFrame(tw, trace.Call, 0)
+	}
+
+	if len(trace.Steps) > 0 {
+		fmt.Fprintln(tw, "\nSTEPS:")
+		fmt.Fprintln(tw, "PC\tOP\tGAS\tCOST\tDEPTH\tERROR")
+		for _, step := range trace.Steps {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%s\n",
+				step.PC,
+				step.Op,
+				step.Gas,
+				step.GasCost,
+				step.Depth,
+				step.Error)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// formatCallFrame writes a call and the calls it made, one row each
+func formatCallFrame(w io.Writer, frame *types.CallFrame, depth int) {
+	method := frame.Method
+	if method == "" && len(frame.Input) >= 4 {
+		method = fmt.Sprintf("0x%x", frame.Input[:4])
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+		strings.Repeat("  ", depth),
+		frame.Type,
+		frame.From,
+		frame.To,
+		method,
+		types.FormatBig(frame.Value),
+		frame.Gas,
+		frame.GasUsed,
+		callResult(frame))
+
+	for _, call := range frame.Calls {
+		formatCallFrame(w, call, depth+1)
+	}
+}
+
+// callResult describes how a call ended: ok, or its error with the
+// decoded revert reason
+func callResult(frame *types.CallFrame) string {
+	switch {
+	case frame.Error == "":
+		return "ok"
+	case frame.Revert != "":
+		return frame.Error + ": " + frame.Revert
+	}
+	return frame.Error
+}
+
+// logSummary returns the event and data columns for a log: the decoded
+// event name and arguments when known, its raw topic and data otherwise
+func logSummary(l *types.Log) (string, string) {
+	if l.Decoded != nil {
+		return l.Decoded.Name, types.FormatArgs(l.Decoded.Args)
+	}
+	topic := ""
+	if len(l.Topics) > 0 {
+		topic = truncateString(l.Topics[0], 12)
+	}
+	return topic, truncateString(fmt.Sprintf("0x%x", l.Data), 18)
+}
+
+// formatEvent formats a stream event as a single line so that events can be
+// printed as they arrive
+func (f *TableFormatter) formatEvent(w io.Writer, event *types.Event) error {
+	var err error
+	switch event.Type {
+	case types.EventNewHead, types.EventReverted:
+		_, err = fmt.Fprintf(w, "%-20s #%-10d %s  txs=%d  %s\n",
+			event.Type,
+			event.Block.Height,
+			event.Block.Hash,
+			len(event.Block.Transactions),
+			time.Unix(event.Block.Timestamp, 0).Format(time.RFC3339))
+	case types.EventPendingTransaction:
+		_, err = fmt.Fprintf(w, "%-20s %s\n", event.Type, event.TransactionHash)
+	case types.EventLog:
+		l := event.Log
+		topic := ""
+		if l.Decoded != nil {
+			topic = l.Decoded.Name + types.FormatArgs(l.Decoded.Args)
+		} else if len(l.Topics) > 0 {
+			topic = truncateString(l.Topics[0], 12)
+		}
+		removed := ""
+		if l.Removed {
+			removed = "  (removed)"
+		}
+		_, err = fmt.Fprintf(w, "%-20s #%-10d %s  %s  tx=%s%s\n",
+			event.Type,
+			l.BlockNumber,
+			l.Address,
+			topic,
+			truncateString(l.TransactionHash, 12),
+			removed)
+	default:
+		return fmt.Errorf("unsupported event type: %s", event.Type)
+	}
+	return err
+}
+
 // Helper function to truncate long strings (like hashes and addresses)
 func truncateString(s string, length int) string {
 	if len(s) <= length {
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request it
+// serves
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withContext appends the request and trace IDs carried by ctx to
+// keysAndValues
+func withContext(ctx context.Context, keysAndValues []interface{}) []interface{} {
+	kv := make([]interface{}, len(keysAndValues), len(keysAndValues)+6)
+	copy(kv, keysAndValues)
+	if id := RequestID(ctx); id != "" {
+		kv = append(kv, "request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		kv = append(kv, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	return kv
+}
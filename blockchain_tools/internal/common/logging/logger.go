@@ -1,6 +1,8 @@
 package logging
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -12,6 +14,11 @@ type Logger interface {
 	Info(msg string, keysAndValues ...interface{})
 	Error(msg string, keysAndValues ...interface{})
 	Debug(msg string, keysAndValues ...interface{})
+
+	// The Context variants add the request and trace IDs carried by ctx
+	InfoContext(ctx context.Context, msg string, keysAndValues ...interface{})
+	ErrorContext(ctx context.Context, msg string, keysAndValues ...interface{})
+	DebugContext(ctx context.Context, msg string, keysAndValues ...interface{})
 }
 
 // LogLevel represents the logging level
@@ -104,7 +111,20 @@ func (l *defaultLogger) Debug(msg string, keysAndValues ...interface{}) {
 	}
 }
 
-// formatKeyValues formats key-value pairs into a string
+func (l *defaultLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Info(msg, withContext(ctx, keysAndValues)...)
+}
+
+func (l *defaultLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Error(msg, withContext(ctx, keysAndValues)...)
+}
+
+func (l *defaultLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, withContext(ctx, keysAndValues)...)
+}
+
+// formatKeyValues formats key-value pairs into a string. Keys and values of
+// any type are written as fmt.Sprint does.
 func formatKeyValues(keysAndValues ...interface{}) string {
 	if len(keysAndValues) == 0 {
 		return ""
@@ -117,7 +137,7 @@ func formatKeyValues(keysAndValues ...interface{}) string {
 		if i+1 < len(keysAndValues) {
 			value = keysAndValues[i+1]
 		}
-		result += " " + key.(string) + "=" + value.(string)
+		result += " " + fmt.Sprint(key) + "=" + fmt.Sprint(value)
 	}
 	return result
 }
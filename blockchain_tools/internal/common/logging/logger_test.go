@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFormatKeyValues(t *testing.T) {
+	tests := []struct {
+		kv   []interface{}
+		want string
+	}{
+		{nil, ""},
+		{[]interface{}{"method", "eth_call"}, " method=eth_call"},
+		{[]interface{}{"height", uint64(42), "ok", true}, " height=42 ok=true"},
+		{[]interface{}{"error", errors.New("timeout")}, " error=timeout"},
+		{[]interface{}{"key"}, " key=<no value>"},
+		{[]interface{}{7, "seven"}, " 7=seven"},
+	}
+	for _, tt := range tests {
+		if got := formatKeyValues(tt.kv...); got != tt.want {
+			t.Errorf("formatKeyValues(%v) = %q, want %q", tt.kv, got, tt.want)
+		}
+	}
+}
+
+func TestContextIDs(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = WithRequestID(ctx, "abc123")
+
+	NewLogger().ErrorContext(ctx, "Failed to read cached response", "kind", "block")
+	want := "Failed to read cached response  kind=block request_id=abc123 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("log line = %q, want it to contain %q", got, want)
+	}
+
+	// Without IDs in the context the line is left as it is
+	buf.Reset()
+	NewLogger().ErrorContext(context.Background(), "Indexing failed", "error", "timeout")
+	if got := buf.String(); strings.Contains(got, "request_id") || strings.Contains(got, "trace_id") {
+		t.Errorf("log line = %q, want no IDs", got)
+	}
+}
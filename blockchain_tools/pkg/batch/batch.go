@@ -0,0 +1,254 @@
+// Package batch sends a file of payments from a single account.
+//
+// Every row of the file is validated before anything is sent. Rows are
+// then built concurrently, given sequential nonces and signed in order, and
+// broadcast by a bounded pool of workers. Each signed transaction is
+// written to a journal before it is broadcast, so an interrupted batch can
+// be run again: rows already sent are skipped, signed ones are broadcast
+// again as they are, and failed ones are rebuilt, reusing the nonces they
+// had reserved so no gap is left behind. Rows that failed or were not
+// reached may be corrected in the file in between; the others must not
+// change.
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// Row statuses, as recorded in the journal and reported in results
+const (
+	// StatusSigned rows have a nonce and a signed transaction that the
+	// node has not accepted yet
+	StatusSigned = "signed"
+	// StatusSent rows were accepted by the node
+	StatusSent = "sent"
+	// StatusConfirmed rows were mined successfully
+	StatusConfirmed = types.StatusConfirmed
+	// StatusReverted rows were mined but reverted
+	StatusReverted = "reverted"
+	// StatusFailed rows could not be built or sent, or were dropped. They
+	// are retried when the batch is run again.
+	StatusFailed = types.StatusFailed
+)
+
+// Row is a single payment. Number is its 1-based position among the data
+// rows of the file. Value is in the smallest unit: wei, or the token's
+// base unit for token batches.
+type Row struct {
+	Number int
+	To     string
+	Value  *big.Int
+	Data   []byte
+}
+
+// RowError is a problem with a single row of a batch file
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every invalid row of a batch file
+type ValidationError []*RowError
+
+func (e ValidationError) Error() string {
+	lines := make([]string, len(e))
+	for i, rowErr := range e {
+		lines[i] = "  " + rowErr.Error()
+	}
+	return fmt.Sprintf("%d invalid row(s):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// ReadFile reads and validates the payments in a CSV or JSON file, chosen
+// by its extension. CSV files have a header row naming a to column, a value
+// (or amount) column and optionally a data column; other columns are
+// ignored. JSON files hold an array of objects with the same fields. Values
+// are decimal numbers scaled by decimals, so "1.5" with 18 decimals is
+// 1.5 ether in wei.
+func ReadFile(path string, decimals uint8) ([]*Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(f, decimals)
+	}
+	return ParseCSV(f, decimals)
+}
+
+// ParseCSV reads and validates payments in CSV format, as for ReadFile
+func ParseCSV(r io.Reader, decimals uint8) ([]*Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty batch file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	toCol, ok := columns["to"]
+	if !ok {
+		return nil, errors.New("CSV header has no to column")
+	}
+	valueCol, ok := columns["value"]
+	if !ok {
+		if valueCol, ok = columns["amount"]; !ok {
+			return nil, errors.New("CSV header has no value or amount column")
+		}
+	}
+	dataCol, hasData := columns["data"]
+
+	var raw []rawRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		row := rawRow{to: record[toCol], value: record[valueCol]}
+		if hasData {
+			row.data = record[dataCol]
+		}
+		raw = append(raw, row)
+	}
+	return validate(raw, decimals)
+}
+
+// ParseJSON reads and validates payments in JSON format, as for ReadFile.
+// Values may be JSON numbers or strings.
+func ParseJSON(r io.Reader, decimals uint8) ([]*Row, error) {
+	var rows []struct {
+		To     string      `json:"to"`
+		Value  json.Number `json:"value"`
+		Amount json.Number `json:"amount"`
+		Data   string      `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	raw := make([]rawRow, len(rows))
+	for i, row := range rows {
+		value := row.Value
+		if value == "" {
+			value = row.Amount
+		}
+		raw[i] = rawRow{to: row.To, value: value.String(), data: row.Data}
+	}
+	return validate(raw, decimals)
+}
+
+// rawRow holds the fields of a row as read from the file
+type rawRow struct {
+	to, value, data string
+}
+
+// validate converts raw into rows, collecting every invalid one
+func validate(raw []rawRow, decimals uint8) ([]*Row, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("batch file has no rows")
+	}
+
+	rows := make([]*Row, 0, len(raw))
+	var invalid ValidationError
+	for i, r := range raw {
+		row, err := parseRow(i+1, r, decimals)
+		if err != nil {
+			invalid = append(invalid, &RowError{Row: i + 1, Err: err})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+	return rows, nil
+}
+
+func parseRow(number int, r rawRow, decimals uint8) (*Row, error) {
+	to := strings.TrimSpace(r.to)
+	if !common.IsHexAddress(to) {
+		return nil, fmt.Errorf("invalid address %q", to)
+	}
+	addr := common.HexToAddress(to)
+	if addr == (common.Address{}) {
+		return nil, errors.New("recipient is the zero address")
+	}
+
+	value, err := types.ParseUnits(r.value, decimals)
+	if err != nil {
+		return nil, err
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("negative amount %q", strings.TrimSpace(r.value))
+	}
+
+	row := &Row{Number: number, To: addr.Hex(), Value: value}
+	if data := strings.TrimSpace(r.data); data != "" {
+		if row.Data, err = hexutil.Decode(data); err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+	}
+	return row, nil
+}
+
+// digest identifies the payment made by row, so a resumed batch can tell
+// whether a row that was already signed has changed since
+func digest(row *Row) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s,%s,%x", row.To, row.Value, row.Data)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Unsent returns the rows of rows that records do not show as signed or
+// sent, which are the ones a run will send
+func Unsent(rows []*Row, records map[int]*Record) []*Row {
+	var unsent []*Row
+	for _, row := range rows {
+		if rec := records[row.Number]; rec == nil || rec.Status == StatusFailed {
+			unsent = append(unsent, row)
+		}
+	}
+	return unsent
+}
+
+// Total returns the sum of the values of rows
+func Total(rows []*Row) *big.Int {
+	total := new(big.Int)
+	for _, row := range rows {
+		total.Add(total, row.Value)
+	}
+	return total
+}
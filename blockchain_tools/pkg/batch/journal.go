@@ -0,0 +1,162 @@
+// pkg/batch/journal.go
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrJournalMismatch is returned when a journal was started for a
+// different sender, chain or token than the batch being run, or a row it
+// records as signed has changed since
+var ErrJournalMismatch = errors.New("journal belongs to a different batch")
+
+// Header identifies the batch a journal belongs to
+type Header struct {
+	From    string `json:"from"`
+	ChainID string `json:"chainId"`
+	Token   string `json:"token,omitempty"`
+}
+
+// Record is the state of a row. A journal holds one record per change;
+// the last one for a row wins. Digest identifies the payment the row made.
+type Record struct {
+	Row    int           `json:"row"`
+	Digest string        `json:"digest"`
+	Nonce  *uint64       `json:"nonce,omitempty"`
+	Hash   string        `json:"hash,omitempty"`
+	Raw    hexutil.Bytes `json:"raw,omitempty"`
+	Status string        `json:"status"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// Journal is an append-only file of row records, one JSON object per line
+// after the header. Every record is synced to disk before Write returns.
+type Journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	records map[int]*Record
+}
+
+// OpenJournal opens the journal at path, creating it with header when it
+// does not exist. An existing journal must have been started with the same
+// header.
+func OpenJournal(path string, header Header) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j := &Journal{file: file, records: make(map[int]*Record)}
+
+	if err := j.load(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// load reads the records of an existing journal, or writes the header of
+// a new one
+func (j *Journal) load(header Header) error {
+	scanner := bufio.NewScanner(j.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+		return j.append(header)
+	}
+
+	var existing Header
+	if err := json.Unmarshal(scanner.Bytes(), &existing); err != nil {
+		return fmt.Errorf("invalid journal header: %w", err)
+	}
+	if existing != header {
+		return ErrJournalMismatch
+	}
+
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// A record cut short by a crash can only be the last one
+			continue
+		}
+		j.records[rec.Row] = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+	return j.terminate()
+}
+
+// terminate ends a last line cut short by a crash, so the next record
+// starts on a line of its own
+func (j *Journal) terminate() error {
+	info, err := j.file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := j.file.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	if _, err := j.file.Write([]byte{'\n'}); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Records returns the current record of every row in the journal
+func (j *Journal) Records() map[int]*Record {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records := make(map[int]*Record, len(j.records))
+	for row, rec := range j.records {
+		copied := *rec
+		records[row] = &copied
+	}
+	return records
+}
+
+// Write records a change to a row
+func (j *Journal) Write(rec *Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.append(rec); err != nil {
+		return err
+	}
+	copied := *rec
+	j.records[rec.Row] = &copied
+	return nil
+}
+
+func (j *Journal) append(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
@@ -0,0 +1,88 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	header := Header{From: "0x0000000000000000000000000000000000000001", ChainID: "1"}
+
+	j, err := OpenJournal(path, header)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	nonce := uint64(4)
+	for _, rec := range []*Record{
+		{Row: 1, Digest: "a", Nonce: &nonce, Hash: "0x01", Raw: []byte{1}, Status: StatusSigned},
+		{Row: 2, Digest: "b", Status: StatusFailed, Error: "boom"},
+		{Row: 1, Digest: "a", Nonce: &nonce, Hash: "0x01", Status: StatusSent},
+	} {
+		if err := j.Write(rec); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	j.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("journal permissions = %o, want 600", perm)
+	}
+
+	// A record cut short by a crash is dropped, and the next one starts on
+	// a new line
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"row":3,"digest":"c","sta`)
+	f.Close()
+
+	j, err = OpenJournal(path, header)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	records := j.Records()
+	if len(records) != 2 || records[1].Status != StatusSent || records[2].Error != "boom" || *records[1].Nonce != 4 {
+		t.Fatalf("records = %+v", records)
+	}
+	if err := j.Write(&Record{Row: 3, Digest: "c", Status: StatusFailed}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	j, err = OpenJournal(path, header)
+	if err != nil {
+		t.Fatalf("reopening after recovery: %v", err)
+	}
+	defer j.Close()
+	if rec := j.Records()[3]; rec == nil || rec.Digest != "c" {
+		t.Errorf("record written after recovery = %+v", rec)
+	}
+}
+
+func TestJournalRejectsOtherBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	header := Header{From: "0x0000000000000000000000000000000000000001", ChainID: "1"}
+	j, err := OpenJournal(path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	for _, other := range []Header{
+		{From: "0x0000000000000000000000000000000000000002", ChainID: "1"},
+		{From: header.From, ChainID: "5"},
+		{From: header.From, ChainID: "1", Token: "0x0000000000000000000000000000000000000003"},
+	} {
+		if _, err := OpenJournal(path, other); !errors.Is(err, ErrJournalMismatch) {
+			t.Errorf("OpenJournal(%+v) error = %v, want ErrJournalMismatch", other, err)
+		}
+	}
+}
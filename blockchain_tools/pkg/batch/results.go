@@ -0,0 +1,48 @@
+// pkg/batch/results.go
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// WriteResults writes results to path as JSON when it ends in .json and as
+// CSV otherwise. Values are in the smallest unit.
+func WriteResults(path string, results []*types.BatchResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+		return f.Close()
+	}
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"row", "to", "value", "nonce", "hash", "status", "error"})
+	for _, res := range results {
+		nonce := ""
+		if res.Nonce != nil {
+			nonce = strconv.FormatUint(*res.Nonce, 10)
+		}
+		w.Write([]string{strconv.Itoa(res.Row), res.To, types.FormatBig(res.Value),
+			nonce, res.Hash, res.Status, res.Error})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
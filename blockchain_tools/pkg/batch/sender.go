@@ -0,0 +1,423 @@
+// pkg/batch/sender.go
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/token"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// DefaultConcurrency is the number of rows built, sent or waited for at
+// the same time
+const DefaultConcurrency = 4
+
+// StatusUnsent is reported for rows a run stopped before reaching
+const StatusUnsent = "unsent"
+
+// SignFunc signs a fully built transaction with the key of its sender
+type SignFunc func(tx *types.Transaction) (*gethtypes.Transaction, error)
+
+// Sender sends the rows of a batch from one account
+type Sender struct {
+	client        *rpc.Client
+	builder       *rpc.TxBuilder
+	journal       *Journal
+	from          common.Address
+	sign          SignFunc
+	concurrency   int
+	token         string
+	overrides     *rpc.TxOverrides
+	wait          bool
+	confirmations uint64
+	timeout       time.Duration
+	progress      func(*types.BatchResult)
+}
+
+// Option configures a Sender
+type Option func(*Sender)
+
+// WithConcurrency sets how many rows are built, sent or waited for at the
+// same time
+func WithConcurrency(n int) Option {
+	return func(s *Sender) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithToken sends every row as an ERC-20 transfer of the token at address
+// instead of a transfer of ether
+func WithToken(address string) Option {
+	return func(s *Sender) {
+		s.token = address
+	}
+}
+
+// WithOverrides sets the gas limit and fees used for every row. Fees that
+// are not set are suggested once for the whole run.
+func WithOverrides(overrides *rpc.TxOverrides) Option {
+	return func(s *Sender) {
+		s.overrides = overrides
+	}
+}
+
+// WithWait makes Run wait up to timeout for every sent row to be mined
+// with the given number of confirmations
+func WithWait(confirmations uint64, timeout time.Duration) Option {
+	return func(s *Sender) {
+		s.wait = true
+		s.confirmations = confirmations
+		s.timeout = timeout
+	}
+}
+
+// WithProgress sets a function called with the new state of a row every
+// time it changes. It may be called from several goroutines at once.
+func WithProgress(fn func(*types.BatchResult)) Option {
+	return func(s *Sender) {
+		s.progress = fn
+	}
+}
+
+// NewSender creates a Sender for transactions from from, signed with sign
+// and recorded in journal
+func NewSender(client *rpc.Client, builder *rpc.TxBuilder, journal *Journal, from common.Address, sign SignFunc, opts ...Option) *Sender {
+	s := &Sender{
+		client:      client,
+		builder:     builder,
+		journal:     journal,
+		from:        from,
+		sign:        sign,
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run sends the rows that the journal does not record as sent yet and
+// returns the state of every row. Rows that fail are reported in the
+// results; errors are reserved for failures that stop the run, such as a
+// cancelled context or an unwritable journal, and come with the results
+// reached so far.
+func (s *Sender) Run(ctx context.Context, rows []*Row) ([]*types.BatchResult, error) {
+	if s.token != "" {
+		for _, row := range rows {
+			if len(row.Data) > 0 {
+				return nil, fmt.Errorf("row %d: data cannot be sent with token transfers", row.Number)
+			}
+		}
+	}
+
+	records := s.journal.Records()
+	for _, row := range rows {
+		rec := records[row.Number]
+		if rec != nil && rec.Status != StatusFailed && rec.Digest != digest(row) {
+			return nil, fmt.Errorf("%w: row %d has changed since it was signed", ErrJournalMismatch, row.Number)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r := &run{Sender: s, ctx: ctx, cancel: cancel, rows: rows}
+
+	r.build()
+	r.broadcast()
+	if s.wait {
+		r.waitMined()
+	}
+
+	results := r.results()
+	if r.err != nil {
+		return results, r.err
+	}
+	return results, ctx.Err()
+}
+
+// run holds the state of a single Run
+type run struct {
+	*Sender
+	ctx    context.Context
+	cancel context.CancelFunc
+	rows   []*Row
+
+	mu  sync.Mutex
+	err error
+}
+
+// fail stops the run with err
+func (r *run) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+		r.cancel()
+	}
+}
+
+// record writes rec to the journal and reports the row's new state
+func (r *run) record(row *Row, rec *Record) {
+	if err := r.journal.Write(rec); err != nil {
+		r.fail(err)
+		return
+	}
+	if r.progress != nil {
+		r.progress(result(row, rec))
+	}
+}
+
+// build builds, signs and journals every row without a transaction yet.
+// Rows are built concurrently but given nonces and signed in file order.
+func (r *run) build() {
+	records := r.journal.Records()
+
+	pending, err := r.client.PendingNonceAt(r.ctx, r.from)
+	if err != nil {
+		r.fail(fmt.Errorf("failed to get nonce: %w", err))
+		return
+	}
+
+	// Failed rows keep the nonce they reserved until it is used, so it can
+	// be handed to the next row built rather than left as a gap
+	next := pending
+	var free []uint64
+	var todo []*Row
+	for _, row := range r.rows {
+		rec := records[row.Number]
+		if rec != nil && rec.Nonce != nil && *rec.Nonce >= next {
+			next = *rec.Nonce + 1
+		}
+		if rec == nil || rec.Status == StatusFailed {
+			todo = append(todo, row)
+			if rec != nil && rec.Nonce != nil && *rec.Nonce >= pending {
+				free = append(free, *rec.Nonce)
+			}
+		}
+	}
+	if len(todo) == 0 {
+		return
+	}
+	sort.Slice(free, func(i, j int) bool { return free[i] < free[j] })
+
+	fees, err := r.builder.Fees(r.ctx, r.overrides)
+	if err != nil {
+		r.fail(fmt.Errorf("failed to suggest fees: %w", err))
+		return
+	}
+
+	built := make([]*types.Transaction, len(todo))
+	errs := make([]error, len(todo))
+	r.parallel(len(todo), func(i int) {
+		tx, err := r.transaction(todo[i])
+		if err == nil {
+			var nonce uint64
+			opts := *fees
+			opts.Nonce = &nonce
+			tx, err = r.builder.Build(r.ctx, tx, &opts)
+		}
+		built[i], errs[i] = tx, err
+	})
+	if r.ctx.Err() != nil {
+		return
+	}
+
+	for i, row := range todo {
+		if errs[i] != nil {
+			continue
+		}
+		nonce := next
+		if len(free) > 0 {
+			nonce, free = free[0], free[1:]
+		} else {
+			next++
+		}
+		built[i].Nonce = nonce
+
+		signed, err := r.sign(built[i])
+		if err != nil {
+			r.fail(fmt.Errorf("row %d: %w", row.Number, err))
+			return
+		}
+		raw, err := signed.MarshalBinary()
+		if err != nil {
+			r.fail(fmt.Errorf("row %d: failed to encode transaction: %w", row.Number, err))
+			return
+		}
+		r.record(row, &Record{
+			Row:    row.Number,
+			Digest: digest(row),
+			Nonce:  &nonce,
+			Hash:   signed.Hash().Hex(),
+			Raw:    raw,
+			Status: StatusSigned,
+		})
+		if r.ctx.Err() != nil {
+			return
+		}
+	}
+
+	// Nonces no row could use stay with failed rows for the next run
+	for i, row := range todo {
+		if errs[i] == nil {
+			continue
+		}
+		rec := &Record{Row: row.Number, Digest: digest(row), Status: StatusFailed, Error: errs[i].Error()}
+		if len(free) > 0 {
+			nonce := free[0]
+			rec.Nonce, free = &nonce, free[1:]
+		}
+		r.record(row, rec)
+	}
+}
+
+// transaction returns the unbuilt transaction paying row
+func (r *run) transaction(row *Row) (*types.Transaction, error) {
+	if r.token == "" {
+		return &types.Transaction{From: r.from.Hex(), To: row.To, Value: row.Value, Data: row.Data}, nil
+	}
+	data, err := token.PackTransfer(row.To, row.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Transaction{From: r.from.Hex(), To: r.token, Data: data}, nil
+}
+
+// broadcast sends every signed row in nonce order. A row the node rejects
+// is marked failed unless the node already knows its transaction.
+func (r *run) broadcast() {
+	if r.ctx.Err() != nil {
+		return
+	}
+	records := r.journal.Records()
+
+	var todo []*Row
+	for _, row := range r.rows {
+		if rec := records[row.Number]; rec != nil && rec.Status == StatusSigned {
+			todo = append(todo, row)
+		}
+	}
+	sort.Slice(todo, func(i, j int) bool {
+		return *records[todo[i].Number].Nonce < *records[todo[j].Number].Nonce
+	})
+
+	r.parallel(len(todo), func(i int) {
+		row := todo[i]
+		rec := records[row.Number]
+
+		tx := new(gethtypes.Transaction)
+		err := tx.UnmarshalBinary(rec.Raw)
+		if err == nil {
+			_, err = r.client.SendRawTransaction(r.ctx, tx)
+		}
+		if r.ctx.Err() != nil {
+			return
+		}
+		sent := *rec
+		sent.Raw, sent.Status = nil, StatusSent
+		if err != nil {
+			if known, _ := r.client.GetTransaction(r.ctx, rec.Hash); known == nil {
+				sent.Status, sent.Error = StatusFailed, err.Error()
+			}
+		}
+		r.record(row, &sent)
+	})
+}
+
+// waitMined waits for every sent row to be mined. Rows still pending when
+// the timeout expires stay sent.
+func (r *run) waitMined() {
+	if r.ctx.Err() != nil {
+		return
+	}
+	records := r.journal.Records()
+
+	var todo []*Row
+	for _, row := range r.rows {
+		if rec := records[row.Number]; rec != nil && rec.Status == StatusSent {
+			todo = append(todo, row)
+		}
+	}
+	sort.Slice(todo, func(i, j int) bool {
+		return *records[todo[i].Number].Nonce < *records[todo[j].Number].Nonce
+	})
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
+	defer cancel()
+
+	// Rows are taken in nonce order, so those mined first are waited for
+	// first
+	r.parallel(len(todo), func(i int) {
+		row := todo[i]
+		rec := records[row.Number]
+
+		receipt, err := r.client.WaitForReceipt(ctx, rec.Hash, rpc.WaitOptions{Confirmations: r.confirmations})
+		if ctx.Err() != nil || (err != nil && receipt == nil) {
+			return
+		}
+
+		done := *rec
+		done.Status = StatusConfirmed
+		switch {
+		case err != nil:
+			done.Status, done.Error = StatusFailed, err.Error()
+		case receipt.Status == types.StatusFailed:
+			done.Status = StatusReverted
+		}
+		r.record(row, &done)
+	})
+}
+
+// parallel calls fn for 0 to n-1 on at most concurrency goroutines,
+// stopping early when the run is cancelled
+func (r *run) parallel(n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < r.concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-r.ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// results returns the state of every row as recorded in the journal
+func (r *run) results() []*types.BatchResult {
+	records := r.journal.Records()
+	results := make([]*types.BatchResult, len(r.rows))
+	for i, row := range r.rows {
+		results[i] = result(row, records[row.Number])
+	}
+	return results
+}
+
+func result(row *Row, rec *Record) *types.BatchResult {
+	res := &types.BatchResult{Row: row.Number, To: row.To, Value: row.Value, Status: StatusUnsent}
+	if rec != nil {
+		res.Nonce, res.Hash, res.Status, res.Error = rec.Nonce, rec.Hash, rec.Status, rec.Error
+	}
+	return res
+}
@@ -0,0 +1,460 @@
+package batch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// testChainID is the chain the fake node runs
+var testChainID = big.NewInt(1337)
+
+// fakeNode is a JSON-RPC server that keeps the transactions it accepted
+// from one account. Its pending nonce is the first nonce without an
+// accepted transaction, as on a real node.
+type fakeNode struct {
+	start uint64
+
+	mu       sync.Mutex
+	accepted map[uint64]*gethtypes.Transaction
+	// sends counts every eth_sendRawTransaction call, accepted or not
+	sends int
+	// onSend runs before a transaction is accepted. A non-nil error rejects
+	// it.
+	onSend func(tx *gethtypes.Transaction) error
+	// estimate fails for transactions to this address when set
+	failEstimate string
+	// inFlight and maxInFlight count concurrent eth_sendRawTransaction calls
+	inFlight, maxInFlight int
+	// delay is how long eth_sendRawTransaction takes
+	delay time.Duration
+}
+
+func newFakeNode(t *testing.T, start uint64) (*fakeNode, *rpc.Client) {
+	t.Helper()
+	n := &fakeNode{start: start, accepted: make(map[uint64]*gethtypes.Transaction)}
+	srv := httptest.NewServer(n)
+	t.Cleanup(srv.Close)
+
+	client, err := rpc.NewClient(srv.URL, rpc.WithRetries(0))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return n, client
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	result, err := n.call(req.Method, req.Params)
+	if err != nil {
+		resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (n *fakeNode) call(method string, params []json.RawMessage) (interface{}, error) {
+	switch method {
+	case "eth_chainId":
+		return (*hexutil.Big)(testChainID), nil
+	case "eth_getTransactionCount":
+		return hexutil.Uint64(n.pending()), nil
+	case "eth_estimateGas":
+		var msg struct {
+			To string `json:"to"`
+		}
+		json.Unmarshal(params[0], &msg)
+		if n.failEstimate != "" && strings.EqualFold(msg.To, n.failEstimate) {
+			return nil, errors.New("execution reverted")
+		}
+		return hexutil.Uint64(21000), nil
+	case "eth_sendRawTransaction":
+		var raw hexutil.Bytes
+		if err := json.Unmarshal(params[0], &raw); err != nil {
+			return nil, err
+		}
+		tx := new(gethtypes.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		return n.send(tx)
+	case "eth_getTransactionByHash":
+		var hash common.Hash
+		json.Unmarshal(params[0], &hash)
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for _, tx := range n.accepted {
+			if tx.Hash() == hash {
+				return tx, nil
+			}
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("the method %s does not exist/is not available", method)
+}
+
+func (n *fakeNode) send(tx *gethtypes.Transaction) (interface{}, error) {
+	n.mu.Lock()
+	n.sends++
+	n.inFlight++
+	if n.inFlight > n.maxInFlight {
+		n.maxInFlight = n.inFlight
+	}
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		n.inFlight--
+		n.mu.Unlock()
+	}()
+	time.Sleep(n.delay)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if known, ok := n.accepted[tx.Nonce()]; ok {
+		if known.Hash() == tx.Hash() {
+			return nil, errors.New("already known")
+		}
+		return nil, errors.New("nonce too low")
+	}
+	if tx.Nonce() < n.start {
+		return nil, errors.New("nonce too low")
+	}
+	if n.onSend != nil {
+		if err := n.onSend(tx); err != nil {
+			return nil, err
+		}
+	}
+	n.accepted[tx.Nonce()] = tx
+	return tx.Hash(), nil
+}
+
+func (n *fakeNode) pending() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	nonce := n.start
+	for n.accepted[nonce] != nil {
+		nonce++
+	}
+	return nonce
+}
+
+// checkNonces fails unless the node accepted exactly one transaction for
+// each nonce from its start on, and each paid a different row
+func (n *fakeNode) checkNonces(t *testing.T, rows int) {
+	t.Helper()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var nonces []uint64
+	recipients := map[common.Address]bool{}
+	for nonce, tx := range n.accepted {
+		nonces = append(nonces, nonce)
+		recipients[*tx.To()] = true
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != n.start+uint64(i) {
+			t.Fatalf("accepted nonces %v have a gap or start before %d", nonces, n.start)
+		}
+	}
+	if len(nonces) != rows || len(recipients) != rows {
+		t.Fatalf("node accepted %d transactions to %d recipients, want %d", len(nonces), len(recipients), rows)
+	}
+}
+
+// testRows returns n rows paying i wei to a distinct address each
+func testRows(n int) []*Row {
+	rows := make([]*Row, n)
+	for i := range rows {
+		to := common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		rows[i] = &Row{Number: i + 1, To: to.Hex(), Value: big.NewInt(int64(i + 1))}
+	}
+	return rows
+}
+
+// fees are fixed so runs need no fee suggestion
+var fees = &rpc.TxOverrides{MaxFeePerGas: big.NewInt(2e9), MaxPriorityFeePerGas: big.NewInt(1e9)}
+
+type testBatch struct {
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	path    string
+	header  Header
+	journal *Journal
+}
+
+func newTestBatch(t *testing.T) *testBatch {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &testBatch{key: key, from: crypto.PubkeyToAddress(key.PublicKey), path: filepath.Join(t.TempDir(), "batch.journal")}
+	b.header = Header{From: b.from.Hex(), ChainID: testChainID.String()}
+	b.reopen(t)
+	t.Cleanup(func() { b.journal.Close() })
+	return b
+}
+
+// reopen closes the journal and opens it again, as a new process would
+func (b *testBatch) reopen(t *testing.T) {
+	t.Helper()
+	if b.journal != nil {
+		b.journal.Close()
+	}
+	journal, err := OpenJournal(b.path, b.header)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	b.journal = journal
+}
+
+func (b *testBatch) sender(client *rpc.Client, opts ...Option) *Sender {
+	sign := func(tx *types.Transaction) (*gethtypes.Transaction, error) {
+		unsigned, err := tx.ToGeth()
+		if err != nil {
+			return nil, err
+		}
+		return gethtypes.SignTx(unsigned, gethtypes.LatestSignerForChainID(tx.ChainID), b.key)
+	}
+	opts = append([]Option{WithOverrides(fees)}, opts...)
+	return NewSender(client, rpc.NewTxBuilder(client), b.journal, b.from, sign, opts...)
+}
+
+func statuses(results []*types.BatchResult) []string {
+	out := make([]string, len(results))
+	for i, res := range results {
+		out[i] = res.Status
+	}
+	return out
+}
+
+func TestRunResumesAfterInterrupt(t *testing.T) {
+	node, client := newFakeNode(t, 7)
+	b := newTestBatch(t)
+	rows := testRows(5)
+
+	// The run is interrupted once the node has accepted the third row, before
+	// the answer is recorded
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	node.onSend = func(tx *gethtypes.Transaction) error {
+		if tx.Nonce() == 9 {
+			cancel()
+		}
+		return nil
+	}
+	results, err := b.sender(client, WithConcurrency(1)).Run(ctx, rows)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+	want := []string{StatusSent, StatusSent, StatusSigned, StatusSigned, StatusSigned}
+	if got := statuses(results); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statuses after interrupt = %v, want %v", got, want)
+	}
+	for i, res := range results {
+		if res.Nonce == nil || *res.Nonce != 7+uint64(i) {
+			t.Fatalf("row %d has nonce %v, want %d", res.Row, res.Nonce, 7+i)
+		}
+	}
+
+	// The resumed run broadcasts the signed rows as they are. The node
+	// already knows the third one, which stays sent.
+	node.onSend = nil
+	b.reopen(t)
+	results, err = b.sender(client, WithConcurrency(1)).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	for i, res := range results {
+		if res.Status != StatusSent || *res.Nonce != 7+uint64(i) {
+			t.Errorf("row %d: status %s, nonce %d after resuming", res.Row, res.Status, *res.Nonce)
+		}
+	}
+	node.checkNonces(t, len(rows))
+	if node.sends != 6 {
+		t.Errorf("node got %d sends, want 6", node.sends)
+	}
+
+	// A finished batch sends nothing more
+	b.reopen(t)
+	if _, err := b.sender(client).Run(context.Background(), rows); err != nil {
+		t.Fatalf("third Run: %v", err)
+	}
+	if node.sends != 6 {
+		t.Errorf("finished batch was sent again: %d sends", node.sends)
+	}
+}
+
+func TestRunReusesNonceOfRejectedRow(t *testing.T) {
+	node, client := newFakeNode(t, 7)
+	b := newTestBatch(t)
+	rows := testRows(4)
+
+	// The node rejects the second row once, after later rows were signed
+	// with the following nonces
+	rejected := false
+	node.onSend = func(tx *gethtypes.Transaction) error {
+		if tx.Nonce() == 8 && !rejected {
+			rejected = true
+			return errors.New("insufficient funds for gas * price + value")
+		}
+		return nil
+	}
+	results, err := b.sender(client, WithConcurrency(1)).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := results[1]; got.Status != StatusFailed || *got.Nonce != 8 || !strings.Contains(got.Error, "insufficient funds") {
+		t.Fatalf("rejected row = %+v", got)
+	}
+
+	// The retry fills the gap with the nonce the row reserved
+	b.reopen(t)
+	results, err = b.sender(client).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if got := results[1]; got.Status != StatusSent || *got.Nonce != 8 {
+		t.Fatalf("retried row = %+v", got)
+	}
+	node.checkNonces(t, len(rows))
+}
+
+func TestRunLeavesNoGapForRowsThatFailToBuild(t *testing.T) {
+	node, client := newFakeNode(t, 7)
+	b := newTestBatch(t)
+	rows := testRows(5)
+
+	node.failEstimate = rows[2].To
+	results, err := b.sender(client).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{StatusSent, StatusSent, StatusFailed, StatusSent, StatusSent}
+	if got := statuses(results); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+	if results[2].Nonce != nil {
+		t.Errorf("row that failed to build holds nonce %d", *results[2].Nonce)
+	}
+	node.checkNonces(t, 4)
+
+	node.failEstimate = ""
+	b.reopen(t)
+	results, err = b.sender(client).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if got := results[2]; got.Status != StatusSent || *got.Nonce != 11 {
+		t.Fatalf("retried row = %+v, want nonce 11", got)
+	}
+	node.checkNonces(t, len(rows))
+}
+
+func TestRunRefusesChangedRows(t *testing.T) {
+	node, client := newFakeNode(t, 0)
+	b := newTestBatch(t)
+	rows := testRows(3)
+
+	node.failEstimate = rows[2].To
+	if _, err := b.sender(client).Run(context.Background(), rows); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	node.failEstimate = ""
+	sends := node.sends
+
+	// A sent row must not change
+	changed := testRows(3)
+	changed[1].Value = big.NewInt(1000)
+	b.reopen(t)
+	_, err := b.sender(client).Run(context.Background(), changed)
+	if !errors.Is(err, ErrJournalMismatch) {
+		t.Fatalf("Run with a changed row: error = %v, want ErrJournalMismatch", err)
+	}
+	if node.sends != sends {
+		t.Errorf("changed batch sent %d transactions", node.sends-sends)
+	}
+
+	// A failed row may be corrected
+	fixed := testRows(3)
+	fixed[2].Value = big.NewInt(1000)
+	results, err := b.sender(client).Run(context.Background(), fixed)
+	if err != nil {
+		t.Fatalf("Run with a corrected failed row: %v", err)
+	}
+	if results[2].Status != StatusSent {
+		t.Errorf("corrected row status = %s", results[2].Status)
+	}
+	node.checkNonces(t, 3)
+}
+
+func TestRunBroadcastsInParallel(t *testing.T) {
+	node, client := newFakeNode(t, 3)
+	node.delay = 20 * time.Millisecond
+	b := newTestBatch(t)
+	rows := testRows(20)
+
+	var mu sync.Mutex
+	progress := map[string]int{}
+	results, err := b.sender(client, WithConcurrency(4), WithProgress(func(res *types.BatchResult) {
+		mu.Lock()
+		progress[res.Status]++
+		mu.Unlock()
+	})).Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, res := range results {
+		if res.Status != StatusSent {
+			t.Errorf("row %d: status %s", res.Row, res.Status)
+		}
+	}
+	node.checkNonces(t, len(rows))
+	if node.maxInFlight < 2 || node.maxInFlight > 4 {
+		t.Errorf("%d transactions were sent at once, want 2 to 4", node.maxInFlight)
+	}
+	if progress[StatusSigned] != len(rows) || progress[StatusSent] != len(rows) {
+		t.Errorf("progress = %v", progress)
+	}
+
+	// Rows are signed in file order
+	for i, res := range results {
+		if *res.Nonce != 3+uint64(i) {
+			t.Errorf("row %d has nonce %d, want %d", res.Row, *res.Nonce, 3+i)
+		}
+	}
+}
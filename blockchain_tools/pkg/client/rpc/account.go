@@ -0,0 +1,99 @@
+// pkg/client/rpc/account.go
+package rpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// GetAccounts returns the addresses managed by the node
+func (c *Client) GetAccounts(ctx context.Context) ([]common.Address, error) {
+	var accounts []common.Address
+	if err := c.Call(ctx, &accounts, "eth_accounts"); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// ListAccounts returns the node's accounts with their balances and nonces
+func (c *Client) ListAccounts(ctx context.Context) ([]*types.Account, error) {
+	addresses, err := c.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*types.Account, 0, len(addresses))
+	for _, addr := range addresses {
+		account, err := c.getAccount(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// CreateAccount asks the node to create a new account protected by
+// password. Only nodes exposing the personal namespace support this.
+func (c *Client) CreateAccount(ctx context.Context, password string) (*types.Account, error) {
+	var addr common.Address
+	if err := c.Call(ctx, &addr, "personal_newAccount", password); err != nil {
+		return nil, err
+	}
+	return &types.Account{Address: addr.Hex(), Balance: new(big.Int)}, nil
+}
+
+// GetAccountBalance returns the balance of address in wei
+func (c *Client) GetAccountBalance(ctx context.Context, address string) (*big.Int, error) {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return c.balanceAt(ctx, addr)
+}
+
+// GetBalance returns the balance of addr formatted in ether
+func (c *Client) GetBalance(ctx context.Context, addr common.Address) (string, error) {
+	wei, err := c.balanceAt(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	return weiToEther(wei), nil
+}
+
+func (c *Client) getAccount(ctx context.Context, addr common.Address) (*types.Account, error) {
+	balance, err := c.balanceAt(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := c.NonceAt(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Account{
+		Address: addr.Hex(),
+		Balance: balance,
+		Nonce:   nonce,
+	}, nil
+}
+
+func (c *Client) balanceAt(ctx context.Context, addr common.Address) (*big.Int, error) {
+	var balance hexutil.Big
+	if err := c.Call(ctx, &balance, "eth_getBalance", addr, "latest"); err != nil {
+		return nil, err
+	}
+	return balance.ToInt(), nil
+}
+
+// weiToEther formats a wei amount as a decimal ether string
+func weiToEther(wei *big.Int) string {
+	ether := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether))
+	return ether.Text('f', 4)
+}
@@ -0,0 +1,156 @@
+// pkg/client/rpc/batch.go
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+const (
+	// DefaultBatchSize is the number of calls sent in one batch request
+	DefaultBatchSize = 50
+	// DefaultWorkers is the number of batch requests in flight at once
+	DefaultWorkers = 4
+)
+
+// BatchCall sends the calls in b as a single JSON-RPC batch request. The
+// request is retried and failed over like a single call. Errors of
+// individual calls are set on their elements as the typed errors in
+// errors.go; the returned error is for the request as a whole.
+func (c *Client) BatchCall(ctx context.Context, b []BatchElem) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	method := "batch"
+	for _, elem := range b {
+		// Transactions decide whether the request may be sent again
+		if sendMethods[elem.Method] {
+			method = elem.Method
+			break
+		}
+	}
+
+	err := c.call(ctx, method, len(b), func(ctx context.Context, t Transport) error {
+		return t.BatchCallContext(ctx, b)
+	})
+	if err != nil {
+		return err
+	}
+	for i := range b {
+		if b[i].Error != nil {
+			b[i].Error = wrapError(b[i].Method, b[i].Error)
+		}
+	}
+	return nil
+}
+
+// RangeOptions controls how GetBlockRange fetches blocks
+type RangeOptions struct {
+	// BatchSize is the number of blocks requested per batch
+	BatchSize int
+	// Workers is the number of batches fetched concurrently
+	Workers int
+}
+
+// GetBlockRange fetches the blocks from start to end inclusive and hands
+// them to fn in ascending order. Blocks are requested in batches, several at
+// once, and delivered as soon as every block before them has arrived, so
+// memory stays bounded however long the range is. It stops at the first
+// error of a fetch or of fn.
+func (c *Client) GetBlockRange(ctx context.Context, start, end uint64, opts RangeOptions, fn func(*types.Block) error) error {
+	if end < start {
+		return ErrInvalidRange
+	}
+	size := uint64(opts.BatchSize)
+	if size == 0 {
+		size = DefaultBatchSize
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		blocks []*types.Block
+		err    error
+	}
+	// Batches are queued in order; the capacity bounds how many are in
+	// flight ahead of the one being delivered
+	pending := make(chan chan result, workers-1)
+	go func() {
+		defer close(pending)
+		for from := start; ; from += size {
+			to := end
+			if end-from >= size {
+				to = from + size - 1
+			}
+			done := make(chan result, 1)
+			select {
+			case pending <- done:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				blocks, err := c.getBlockBatch(ctx, from, to)
+				done <- result{blocks, err}
+			}()
+			if to == end {
+				return
+			}
+		}
+	}()
+
+	for done := range pending {
+		r := <-done
+		if r.err != nil {
+			return r.err
+		}
+		for _, block := range r.blocks {
+			if err := fn(block); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// getBlockBatch fetches the blocks from start to end inclusive in one batch
+func (c *Client) getBlockBatch(ctx context.Context, start, end uint64) ([]*types.Block, error) {
+	raw := make([]json.RawMessage, end-start+1)
+	batch := make([]BatchElem, len(raw))
+	for i := range batch {
+		batch[i] = BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), true},
+			Result: &raw[i],
+		}
+	}
+	if err := c.BatchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*types.Block, len(raw))
+	for i, elem := range batch {
+		height := start + uint64(i)
+		if elem.Error != nil {
+			return nil, fmt.Errorf("block %d: %w", height, elem.Error)
+		}
+		if len(raw[i]) == 0 || string(raw[i]) == "null" {
+			return nil, fmt.Errorf("block %d: %w", height, ErrNotFound)
+		}
+		var block rpcBlock
+		if err := json.Unmarshal(raw[i], &block); err != nil {
+			return nil, &DecodeError{Method: elem.Method, Err: err}
+		}
+		blocks[i] = block.toBlock()
+	}
+	return blocks, nil
+}
@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// benchRange is the number of blocks fetched per benchmark iteration
+const benchRange = 200
+
+// BenchmarkGetBlockRange compares GetBlockRange with fetching the same
+// blocks one GetBlockByHeight call at a time. Each HTTP request to the fake
+// node takes at least latency, standing in for the round trip to a remote
+// node.
+func BenchmarkGetBlockRange(b *testing.B) {
+	for _, latency := range []time.Duration{0, time.Millisecond} {
+		node, url := newFakeNode(b)
+		node.result("eth_chainId", "0x1")
+		node.handle("eth_getBlockByNumber", blockHandler(benchRange))
+		if latency > 0 {
+			node.hook = func(*http.Request) { time.Sleep(latency) }
+		}
+		client := newTestClient(b, url)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("latency=%s/range", latency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				n := 0
+				err := client.GetBlockRange(ctx, 1, benchRange, RangeOptions{}, func(*types.Block) error {
+					n++
+					return nil
+				})
+				if err != nil {
+					b.Fatalf("GetBlockRange: %v", err)
+				}
+				if n != benchRange {
+					b.Fatalf("GetBlockRange delivered %d blocks, want %d", n, benchRange)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("latency=%s/sequential", latency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for height := uint64(1); height <= benchRange; height++ {
+					if _, err := client.GetBlockByHeight(ctx, height); err != nil {
+						b.Fatalf("GetBlockByHeight(%d): %v", height, err)
+					}
+				}
+			}
+		})
+	}
+}
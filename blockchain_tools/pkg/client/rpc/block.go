@@ -0,0 +1,63 @@
+// pkg/client/rpc/block.go
+package rpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// GetBlockByHeight returns the block at the given height
+func (c *Client) GetBlockByHeight(ctx context.Context, height uint64) (*types.Block, error) {
+	return c.getBlock(ctx, "eth_getBlockByNumber", hexutil.EncodeUint64(height))
+}
+
+// GetBlockByHash returns the block with the given hash
+func (c *Client) GetBlockByHash(ctx context.Context, hash string) (*types.Block, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.getBlock(ctx, "eth_getBlockByHash", h)
+}
+
+// GetLatestBlock returns the most recent block
+func (c *Client) GetLatestBlock(ctx context.Context) (*types.Block, error) {
+	return c.getBlock(ctx, "eth_getBlockByNumber", "latest")
+}
+
+// BlockNumber returns the height of the most recent block
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	if err := c.Call(ctx, &number, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(number), nil
+}
+
+func (c *Client) getBlock(ctx context.Context, method string, id interface{}) (*types.Block, error) {
+	var block rpcBlock
+	if err := c.callNullable(ctx, &block, method, id, true); err != nil {
+		return nil, err
+	}
+	return block.toBlock(), nil
+}
+
+// parseHash validates a 32-byte hex hash
+func parseHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, ErrInvalidHash
+	}
+	return common.BytesToHash(b), nil
+}
+
+// parseAddress validates a 20-byte hex address
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, ErrInvalidAddress
+	}
+	return common.HexToAddress(s), nil
+}
@@ -0,0 +1,319 @@
+// pkg/client/rpc/builder.go
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// Builder defaults
+const (
+	DefaultGasMultiplier     = 1.2
+	DefaultFeeHistoryBlocks  = 10
+	DefaultRewardPercentile  = 50
+	DefaultBaseFeeMultiplier = 2
+)
+
+// TxOverrides holds caller supplied values that take precedence over the
+// builder's suggestions. Nil fields are filled in by the builder.
+type TxOverrides struct {
+	Nonce                *uint64
+	Gas                  *uint64
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	Legacy               bool
+}
+
+// TxBuilder fills in nonce, gas limit and fees for outgoing transactions
+type TxBuilder struct {
+	client            *Client
+	gasMultiplier     float64
+	feeHistoryBlocks  uint64
+	rewardPercentile  float64
+	baseFeeMultiplier int64
+
+	mu     sync.Mutex
+	nonces map[common.Address]uint64
+}
+
+// BuilderOption configures a TxBuilder
+type BuilderOption func(*TxBuilder)
+
+// WithGasMultiplier sets the safety factor applied to eth_estimateGas
+func WithGasMultiplier(m float64) BuilderOption {
+	return func(b *TxBuilder) {
+		b.gasMultiplier = m
+	}
+}
+
+// WithFeeHistory sets how many blocks and which reward percentile are used
+// to suggest the priority fee
+func WithFeeHistory(blocks uint64, percentile float64) BuilderOption {
+	return func(b *TxBuilder) {
+		b.feeHistoryBlocks = blocks
+		b.rewardPercentile = percentile
+	}
+}
+
+// WithBaseFeeMultiplier sets how many times the next base fee the fee cap
+// allows for, which keeps the transaction valid across base fee increases
+func WithBaseFeeMultiplier(m int64) BuilderOption {
+	return func(b *TxBuilder) {
+		b.baseFeeMultiplier = m
+	}
+}
+
+// NewTxBuilder creates a builder backed by client
+func NewTxBuilder(client *Client, opts ...BuilderOption) *TxBuilder {
+	b := &TxBuilder{
+		client:            client,
+		gasMultiplier:     DefaultGasMultiplier,
+		feeHistoryBlocks:  DefaultFeeHistoryBlocks,
+		rewardPercentile:  DefaultRewardPercentile,
+		baseFeeMultiplier: DefaultBaseFeeMultiplier,
+		nonces:            make(map[common.Address]uint64),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Build returns a copy of tx with chain ID, nonce, gas limit and fees set.
+// From, To, Value and Data are taken from tx; when From is empty the node's
+// first account is used. Values in overrides are used as given.
+func (b *TxBuilder) Build(ctx context.Context, tx *types.Transaction, overrides *TxOverrides) (*types.Transaction, error) {
+	if overrides == nil {
+		overrides = &TxOverrides{}
+	}
+	built := *tx
+
+	from, err := b.sender(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	built.From = from.Hex()
+
+	chainID, err := b.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	built.ChainID = chainID
+
+	if err := b.fillFees(ctx, &built, overrides); err != nil {
+		return nil, err
+	}
+	if err := b.fillGas(ctx, &built, overrides); err != nil {
+		return nil, err
+	}
+
+	if overrides.Nonce != nil {
+		built.Nonce = *overrides.Nonce
+	} else if built.Nonce, err = b.nextNonce(ctx, from); err != nil {
+		return nil, err
+	}
+
+	return &built, nil
+}
+
+// Fees returns a copy of overrides with the fees filled in as Build would
+// fill them, so a series of transactions can share one suggestion
+func (b *TxBuilder) Fees(ctx context.Context, overrides *TxOverrides) (*TxOverrides, error) {
+	if overrides == nil {
+		overrides = &TxOverrides{}
+	}
+	var tx types.Transaction
+	if err := b.fillFees(ctx, &tx, overrides); err != nil {
+		return nil, err
+	}
+
+	fees := *overrides
+	if tx.GasPrice != nil {
+		fees.Legacy = true
+		fees.GasPrice = tx.GasPrice
+	} else {
+		fees.MaxFeePerGas = tx.MaxFeePerGas
+		fees.MaxPriorityFeePerGas = tx.MaxPriorityFeePerGas
+	}
+	return &fees, nil
+}
+
+// sender returns the sender of tx, which is the node's first account when
+// From is empty
+func (b *TxBuilder) sender(ctx context.Context, tx *types.Transaction) (common.Address, error) {
+	if tx.From != "" {
+		return parseAddress(tx.From)
+	}
+	accounts, err := b.client.GetAccounts(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(accounts) == 0 {
+		return common.Address{}, ErrNoAccounts
+	}
+	return accounts[0], nil
+}
+
+// ResetNonce forgets the locally tracked nonce for addr, for example after
+// a transaction built by this builder failed to broadcast
+func (b *TxBuilder) ResetNonce(addr common.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.nonces, addr)
+}
+
+// nextNonce returns the larger of the node's pending nonce and the next
+// nonce this builder has not handed out yet, so transactions built in quick
+// succession do not collide before the node sees them
+func (b *TxBuilder) nextNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	pending, err := b.client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	nonce := pending
+	if tracked, ok := b.nonces[addr]; ok && tracked > nonce {
+		nonce = tracked
+	}
+	b.nonces[addr] = nonce + 1
+	return nonce, nil
+}
+
+func (b *TxBuilder) fillGas(ctx context.Context, tx *types.Transaction, overrides *TxOverrides) error {
+	if overrides.Gas != nil {
+		tx.Gas = *overrides.Gas
+		return nil
+	}
+
+	estimate := *tx
+	estimate.Gas = 0
+	estimate.GasPrice = nil
+	estimate.MaxFeePerGas = nil
+	estimate.MaxPriorityFeePerGas = nil
+	msg, err := estimate.CallMsg()
+	if err != nil {
+		return err
+	}
+
+	gas, err := b.client.EstimateGas(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	tx.Gas = uint64(math.Ceil(float64(gas) * b.gasMultiplier))
+	return nil
+}
+
+func (b *TxBuilder) fillFees(ctx context.Context, tx *types.Transaction, overrides *TxOverrides) error {
+	tx.GasPrice, tx.MaxFeePerGas, tx.MaxPriorityFeePerGas = nil, nil, nil
+
+	if overrides.Legacy || overrides.GasPrice != nil {
+		tx.Type = 0
+		if overrides.GasPrice != nil {
+			tx.GasPrice = new(big.Int).Set(overrides.GasPrice)
+			return nil
+		}
+		price, err := b.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get gas price: %w", err)
+		}
+		tx.GasPrice = price
+		return nil
+	}
+
+	if overrides.MaxFeePerGas != nil && overrides.MaxPriorityFeePerGas != nil {
+		tx.Type = 2
+		tx.MaxFeePerGas = new(big.Int).Set(overrides.MaxFeePerGas)
+		tx.MaxPriorityFeePerGas = new(big.Int).Set(overrides.MaxPriorityFeePerGas)
+		return nil
+	}
+
+	baseFee, tip, err := b.suggestFees(ctx)
+	if err != nil {
+		return err
+	}
+	if baseFee == nil {
+		// Pre-London chain: fall back to a legacy transaction
+		return b.fillFees(ctx, tx, &TxOverrides{Legacy: true})
+	}
+
+	tx.Type = 2
+	if overrides.MaxPriorityFeePerGas != nil {
+		tip = new(big.Int).Set(overrides.MaxPriorityFeePerGas)
+	}
+	if overrides.MaxFeePerGas != nil {
+		tx.MaxFeePerGas = new(big.Int).Set(overrides.MaxFeePerGas)
+		if tip.Cmp(tx.MaxFeePerGas) > 0 {
+			tip = new(big.Int).Set(tx.MaxFeePerGas)
+		}
+	} else {
+		tx.MaxFeePerGas = new(big.Int).Mul(baseFee, big.NewInt(b.baseFeeMultiplier))
+		tx.MaxFeePerGas.Add(tx.MaxFeePerGas, tip)
+	}
+	tx.MaxPriorityFeePerGas = tip
+	return nil
+}
+
+// suggestFees returns the next block's base fee and a priority fee taken
+// from the configured eth_feeHistory reward percentile. A nil base fee means
+// the chain does not support EIP-1559.
+func (b *TxBuilder) suggestFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	history, err := b.client.FeeHistory(ctx, b.feeHistoryBlocks, "latest", []float64{b.rewardPercentile})
+	if err != nil && !IsMethodNotFound(err) {
+		return nil, nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+
+	var baseFee, tip *big.Int
+	if history != nil && len(history.BaseFee) > 0 {
+		baseFee = history.BaseFee[len(history.BaseFee)-1]
+		tip = medianReward(history.Reward)
+	} else {
+		head, err := b.client.GetLatestBlock(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get latest block: %w", err)
+		}
+		baseFee = head.BaseFee
+	}
+	if baseFee == nil {
+		return nil, nil, nil
+	}
+
+	if tip == nil || tip.Sign() == 0 {
+		suggested, err := b.client.SuggestGasTipCap(ctx)
+		switch {
+		case err == nil:
+			tip = suggested
+		case IsMethodNotFound(err):
+			tip = new(big.Int)
+		default:
+			return nil, nil, fmt.Errorf("failed to get priority fee: %w", err)
+		}
+	}
+	return baseFee, tip, nil
+}
+
+// medianReward returns the median of the first percentile column across
+// blocks, skipping empty blocks
+func medianReward(rewards [][]*big.Int) *big.Int {
+	values := make([]*big.Int, 0, len(rewards))
+	for _, row := range rewards {
+		if len(row) > 0 && row[0] != nil && row[0].Sign() > 0 {
+			values = append(values, row[0])
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	return new(big.Int).Set(values[len(values)/2])
+}
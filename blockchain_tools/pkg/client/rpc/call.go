@@ -0,0 +1,43 @@
+// pkg/client/rpc/call.go
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CallContract executes msg with eth_call at block and returns the data it
+// returned. block is a block number or one of the tags latest, pending,
+// safe, finalized and earliest; empty means latest. A reverted call fails
+// with an error from which RevertData extracts the revert data.
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, block string) ([]byte, error) {
+	tag, err := blockTag(block)
+	if err != nil {
+		return nil, err
+	}
+
+	var result hexutil.Bytes
+	if err := c.Call(ctx, &result, "eth_call", toCallArg(msg), tag); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// blockTag converts a block number or tag into a block parameter
+func blockTag(block string) (string, error) {
+	switch block {
+	case "":
+		return "latest", nil
+	case "latest", "pending", "safe", "finalized", "earliest":
+		return block, nil
+	}
+	n, err := strconv.ParseUint(block, 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w %q", ErrInvalidBlock, block)
+	}
+	return hexutil.EncodeUint64(n), nil
+}
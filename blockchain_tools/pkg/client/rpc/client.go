@@ -0,0 +1,323 @@
+// pkg/client/rpc/client.go
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/logger"
+)
+
+// DefaultTimeout is applied to calls whose context carries no deadline
+const DefaultTimeout = 30 * time.Second
+
+// Client is a JSON-RPC client for Ethereum-compatible nodes. It may be
+// given several endpoints serving the same chain: calls go to the first
+// healthy one and fail over to the next when it is throttled, failing or
+// unreachable.
+type Client struct {
+	endpoints    []*endpoint
+	dial         Dialer
+	timeout      time.Duration
+	pollInterval time.Duration
+	retries      int
+	backoff      time.Duration
+	cooldown     time.Duration
+	logger       logger.Logger
+	observe      Observer
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// Observer is told about every request sent to an endpoint, retries
+// included, with how long it took and the error it failed with. Batches
+// are reported as method "batch", or as the transaction method they carry.
+type Observer func(method string, elapsed time.Duration, err error)
+
+// Option configures a Client
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	timeout        time.Duration
+	pollInterval   time.Duration
+	httpClient     *http.Client
+	headers        http.Header
+	dialer         Dialer
+	retries        int
+	backoff        time.Duration
+	healthInterval time.Duration
+	rateLimit      *rateLimit
+	endpointLimits map[string]*rateLimit
+	logger         logger.Logger
+	observer       Observer
+}
+
+// WithTimeout sets the per-call timeout used when the context has no deadline
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithPollInterval sets how often subscriptions poll endpoints that cannot
+// push notifications, such as plain HTTP
+func WithPollInterval(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.pollInterval = d
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for HTTP endpoints
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithHeader adds a header to every request sent to the node
+func WithHeader(key, value string) Option {
+	return func(o *clientOptions) {
+		o.headers.Add(key, value)
+	}
+}
+
+// WithDialer replaces the transport used to reach endpoints. WithHTTPClient
+// and WithHeader only apply to the default transport.
+func WithDialer(dial Dialer) Option {
+	return func(o *clientOptions) {
+		o.dialer = dial
+	}
+}
+
+// WithRetries sets how often a call that failed because of throttling, a
+// server error, a timeout or a connection failure is sent again. Retries
+// go to the other endpoints first, so n is raised to try each of them.
+// Zero disables retries and failover.
+func WithRetries(n int) Option {
+	return func(o *clientOptions) {
+		o.retries = n
+	}
+}
+
+// WithBackoff sets the delay before retrying endpoints that all failed
+// already, which doubles with each round
+func WithBackoff(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.backoff = d
+	}
+}
+
+// WithHealthCheck checks every endpoint with eth_blockNumber every interval
+// in the background, so calls avoid failed endpoints and return to them
+// once they recover
+func WithHealthCheck(interval time.Duration) Option {
+	return func(o *clientOptions) {
+		o.healthInterval = interval
+	}
+}
+
+// WithRateLimit limits the requests sent to each endpoint to rps per
+// second, with bursts of up to burst requests. Calls wait for their turn.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.rateLimit = &rateLimit{rps: rps, burst: burst}
+	}
+}
+
+// WithEndpointRateLimit is WithRateLimit for the endpoint at url only
+func WithEndpointRateLimit(url string, rps float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.endpointLimits[url] = &rateLimit{rps: rps, burst: burst}
+	}
+}
+
+// WithLogger sets the logger used to report failed endpoints
+func WithLogger(l logger.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = l
+	}
+}
+
+// WithObserver sets the function told about every request, for metrics
+func WithObserver(observe Observer) Option {
+	return func(o *clientOptions) {
+		o.observer = observe
+	}
+}
+
+// NewClient creates a new client for the node at url. Both HTTP(S) and
+// WS(S) endpoints are supported. url may list several endpoints of the same
+// chain separated by commas, in order of preference.
+func NewClient(url string, opts ...Option) (*Client, error) {
+	var urls []string
+	for _, u := range strings.Split(url, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return NewMultiClient(urls, opts...)
+}
+
+// NewMultiClient creates a client for several endpoints of the same chain,
+// in order of preference. It fails only when none of them can be dialed.
+func NewMultiClient(urls []string, opts ...Option) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	options := clientOptions{
+		timeout:        DefaultTimeout,
+		pollInterval:   DefaultPollInterval,
+		headers:        make(http.Header),
+		retries:        DefaultRetries,
+		backoff:        DefaultBackoff,
+		endpointLimits: make(map[string]*rateLimit),
+		logger:         logger.Nop,
+		observer:       func(string, time.Duration, error) {},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.dialer == nil {
+		options.dialer = gethDialer(options.httpClient, options.headers)
+	}
+
+	c := &Client{
+		dial:         options.dialer,
+		timeout:      options.timeout,
+		pollInterval: options.pollInterval,
+		retries:      options.retries,
+		backoff:      options.backoff,
+		cooldown:     DefaultCooldown,
+		logger:       options.logger,
+		observe:      options.observer,
+	}
+	if options.healthInterval > 0 {
+		c.cooldown = options.healthInterval
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.timeout)
+	defer cancel()
+
+	var dialErr error
+	for _, url := range urls {
+		limit := options.rateLimit
+		if l, ok := options.endpointLimits[url]; ok {
+			limit = l
+		}
+		e := newEndpoint(url, limit)
+		if _, err := e.conn(ctx, c.dial); err != nil {
+			// Calls dial again once the endpoint is due to be tried
+			e.markDown(err, c.cooldown)
+			if dialErr == nil {
+				dialErr = fmt.Errorf("failed to dial %s: %w", url, errors.Unwrap(err))
+			}
+		}
+		c.endpoints = append(c.endpoints, e)
+	}
+	if !c.connected() {
+		return nil, dialErr
+	}
+
+	if options.healthInterval > 0 {
+		ctx, stop := context.WithCancel(context.Background())
+		c.stop, c.done = stop, make(chan struct{})
+		go func() {
+			defer close(c.done)
+			c.checkHealthEvery(ctx, options.healthInterval)
+		}()
+	}
+	return c, nil
+}
+
+// connected reports whether any endpoint was dialed
+func (c *Client) connected() bool {
+	for _, e := range c.endpoints {
+		e.mu.Lock()
+		ok := e.transport != nil
+		e.mu.Unlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// URL returns the endpoint calls are currently sent to first
+func (c *Client) URL() string {
+	return c.candidates()[0].url
+}
+
+// Close stops the health checks and closes the connections
+func (c *Client) Close() {
+	if c.stop != nil {
+		c.stop()
+		<-c.done
+	}
+	for _, e := range c.endpoints {
+		e.close()
+	}
+}
+
+// Call invokes method with args and decodes the result into result.
+// It is exported so callers can reach node APIs not wrapped by Client.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.call(ctx, method, 1, func(ctx context.Context, t Transport) error {
+		return t.CallContext(ctx, result, method, args...)
+	})
+}
+
+// subscriber returns the transport of the preferred endpoint when it can
+// push notifications
+func (c *Client) subscriber() (Subscriber, bool) {
+	for _, e := range c.candidates() {
+		e.mu.Lock()
+		t := e.transport
+		e.mu.Unlock()
+		if t == nil {
+			continue
+		}
+		s, ok := t.(Subscriber)
+		return s, ok && s.SupportsSubscriptions()
+	}
+	return nil, false
+}
+
+// supportsSubscriptions reports whether eth_subscribe can be used
+func (c *Client) supportsSubscriptions() bool {
+	_, ok := c.subscriber()
+	return ok
+}
+
+// ethSubscribe starts an eth_subscribe subscription on the preferred
+// endpoint. Subscriptions stay on that endpoint until they end.
+func (c *Client) ethSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*gethrpc.ClientSubscription, error) {
+	s, ok := c.subscriber()
+	if !ok {
+		return nil, gethrpc.ErrNotificationsUnsupported
+	}
+	return s.EthSubscribe(ctx, channel, args...)
+}
+
+// callNullable is like Call but returns ErrNotFound when the node answers null
+func (c *Client) callNullable(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var raw json.RawMessage
+	if err := c.Call(ctx, &raw, method, args...); err != nil {
+		return err
+	}
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return ErrNotFound
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return &DecodeError{Method: method, Err: err}
+	}
+	return nil
+}
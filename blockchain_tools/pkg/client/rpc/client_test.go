@@ -0,0 +1,390 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+const (
+	testAddress = "0x00000000000000000000000000000000000000aa"
+	testTxHash  = "0x1111111111111111111111111111111111111111111111111111111111111111"
+)
+
+// fakeBlockHash returns a distinct hash for the block at number
+func fakeBlockHash(number uint64) string {
+	return fmt.Sprintf("0x%064x", number+0xb000)
+}
+
+// fakeBlock returns the eth_getBlockByNumber response for the block at
+// number, holding txs
+func fakeBlock(number uint64, txs ...map[string]interface{}) map[string]interface{} {
+	if txs == nil {
+		txs = []map[string]interface{}{}
+	}
+	parent := uint64(0)
+	if number > 0 {
+		parent = number - 1
+	}
+	return map[string]interface{}{
+		"hash":          fakeBlockHash(number),
+		"number":        hexutil.EncodeUint64(number),
+		"parentHash":    fakeBlockHash(parent),
+		"timestamp":     hexutil.EncodeUint64(1700000000 + number),
+		"miner":         testAddress,
+		"gasLimit":      "0x1c9c380",
+		"gasUsed":       "0x5208",
+		"baseFeePerGas": "0x3b9aca00",
+		"size":          "0x220",
+		"transactions":  txs,
+	}
+}
+
+// fakeTx returns the JSON of a transfer mined in block number
+func fakeTx(number uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"hash":        testTxHash,
+		"from":        testAddress,
+		"to":          "0x00000000000000000000000000000000000000bb",
+		"value":       "0xde0b6b3a7640000",
+		"input":       "0x",
+		"nonce":       "0x7",
+		"gas":         "0x5208",
+		"gasPrice":    "0x3b9aca00",
+		"chainId":     "0x539",
+		"type":        "0x0",
+		"blockHash":   fakeBlockHash(number),
+		"blockNumber": hexutil.EncodeUint64(number),
+	}
+}
+
+// blockHandler answers eth_getBlockByNumber with fakeBlock for numbers up
+// to head and null above it
+func blockHandler(head uint64) fakeMethod {
+	return func(params []json.RawMessage) (interface{}, *fakeError) {
+		var id string
+		json.Unmarshal(params[0], &id)
+		number := head
+		if id != "latest" {
+			n, err := hexutil.DecodeUint64(id)
+			if err != nil {
+				return nil, &fakeError{Code: CodeInvalidParams, Message: err.Error()}
+			}
+			number = n
+		}
+		if number > head {
+			return json.RawMessage("null"), nil
+		}
+		return fakeBlock(number), nil
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient(""); !errors.Is(err, ErrNoEndpoint) {
+		t.Fatalf("NewClient(\"\") error = %v, want ErrNoEndpoint", err)
+	}
+	if _, err := NewClient(" , "); !errors.Is(err, ErrNoEndpoint) {
+		t.Fatalf("NewClient(\" , \") error = %v, want ErrNoEndpoint", err)
+	}
+	if _, err := NewClient("ftp://localhost"); err == nil {
+		t.Fatal("NewClient with an unsupported scheme succeeded")
+	}
+
+	_, first := newFakeNode(t)
+	_, second := newFakeNode(t)
+	c := newTestClient(t, first+", "+second)
+	if got := c.URL(); got != first {
+		t.Errorf("URL() = %q, want %q", got, first)
+	}
+	endpoints := c.Endpoints()
+	if len(endpoints) != 2 || endpoints[1].URL != second {
+		t.Errorf("Endpoints() = %+v, want %s and %s", endpoints, first, second)
+	}
+}
+
+func TestBlockMethods(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, *fakeError) {
+		if len(params) != 2 || string(params[1]) != "true" {
+			return nil, &fakeError{Code: CodeInvalidParams, Message: "want full transactions"}
+		}
+		return blockHandler(42)(params)
+	})
+	node.handle("eth_getBlockByHash", func(params []json.RawMessage) (interface{}, *fakeError) {
+		return fakeBlock(7, fakeTx(7)), nil
+	})
+	node.result("eth_blockNumber", "0x2a")
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	block, err := c.GetBlockByHeight(ctx, 12)
+	if err != nil {
+		t.Fatalf("GetBlockByHeight: %v", err)
+	}
+	if block.Height != 12 || block.Hash != fakeBlockHash(12) || block.PreviousHash != fakeBlockHash(11) {
+		t.Errorf("GetBlockByHeight(12) = height %d hash %s parent %s", block.Height, block.Hash, block.PreviousHash)
+	}
+	if block.BaseFee.Int64() != 1e9 || block.GasUsed != 21000 || block.Timestamp != 1700000012 {
+		t.Errorf("GetBlockByHeight(12) base fee %v, gas used %d, timestamp %d", block.BaseFee, block.GasUsed, block.Timestamp)
+	}
+
+	latest, err := c.GetLatestBlock(ctx)
+	if err != nil || latest.Height != 42 {
+		t.Errorf("GetLatestBlock() = %v, %v, want block 42", latest, err)
+	}
+
+	byHash, err := c.GetBlockByHash(ctx, fakeBlockHash(7))
+	if err != nil {
+		t.Fatalf("GetBlockByHash: %v", err)
+	}
+	if len(byHash.Transactions) != 1 {
+		t.Fatalf("GetBlockByHash returned %d transactions, want 1", len(byHash.Transactions))
+	}
+	if tx := byHash.Transactions[0]; tx.Status != types.StatusConfirmed || tx.Timestamp != byHash.Timestamp || tx.Nonce != 7 {
+		t.Errorf("block transaction = %+v, want confirmed at the block time", tx)
+	}
+	if _, err := c.GetBlockByHash(ctx, "0x1234"); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("GetBlockByHash(short hash) error = %v, want ErrInvalidHash", err)
+	}
+	if node.count("eth_getBlockByHash") != 1 {
+		t.Errorf("invalid hash reached the node")
+	}
+
+	head, err := c.BlockNumber(ctx)
+	if err != nil || head != 42 {
+		t.Errorf("BlockNumber() = %d, %v, want 42", head, err)
+	}
+}
+
+func TestNullResultIsNotFound(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.handle("eth_getBlockByNumber", blockHandler(10))
+	node.result("eth_getTransactionByHash", json.RawMessage("null"))
+	node.result("eth_getTransactionReceipt", json.RawMessage("null"))
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	if _, err := c.GetBlockByHeight(ctx, 11); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBlockByHeight(future) error = %v, want ErrNotFound", err)
+	}
+	if _, err := c.GetTransaction(ctx, testTxHash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTransaction(unknown) error = %v, want ErrNotFound", err)
+	}
+	if _, err := c.GetTransactionReceipt(ctx, testTxHash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTransactionReceipt(pending) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTransactionMethods(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.result("eth_getTransactionByHash", fakeTx(5))
+	node.result("eth_getTransactionReceipt", map[string]interface{}{
+		"transactionHash":   testTxHash,
+		"status":            "0x0",
+		"blockHash":         fakeBlockHash(5),
+		"blockNumber":       "0x5",
+		"gasUsed":           "0x5208",
+		"effectiveGasPrice": "0x3b9aca00",
+		"logs":              []interface{}{},
+	})
+	node.result("eth_getBlockByHash", map[string]interface{}{"timestamp": "0x64"})
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	tx, err := c.GetTransaction(ctx, testTxHash)
+	if err != nil {
+		t.Fatalf("GetTransaction: %v", err)
+	}
+	if tx.Hash != testTxHash || tx.BlockNumber != 5 || tx.Value.String() != "1000000000000000000" {
+		t.Errorf("GetTransaction = %+v", tx)
+	}
+	if tx.Status != types.StatusFailed || tx.Timestamp != 100 {
+		t.Errorf("GetTransaction status %q timestamp %d, want failed at 100", tx.Status, tx.Timestamp)
+	}
+
+	receipt, err := c.GetTransactionReceipt(ctx, testTxHash)
+	if err != nil {
+		t.Fatalf("GetTransactionReceipt: %v", err)
+	}
+	if receipt.GasUsed != 21000 || receipt.BlockNumber != 5 || receipt.Status != types.StatusFailed {
+		t.Errorf("GetTransactionReceipt = %+v", receipt)
+	}
+
+	if _, err := c.GetTransaction(ctx, "not a hash"); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("GetTransaction(invalid) error = %v, want ErrInvalidHash", err)
+	}
+}
+
+func TestPendingTransaction(t *testing.T) {
+	node, url := newFakeNode(t)
+	pending := fakeTx(0)
+	pending["blockHash"] = nil
+	pending["blockNumber"] = nil
+	node.result("eth_getTransactionByHash", pending)
+	c := newTestClient(t, url)
+
+	tx, err := c.GetTransaction(context.Background(), testTxHash)
+	if err != nil {
+		t.Fatalf("GetTransaction: %v", err)
+	}
+	if tx.Status != types.StatusPending || tx.BlockHash != "" {
+		t.Errorf("pending transaction = %+v", tx)
+	}
+	if node.count("eth_getTransactionReceipt") != 0 {
+		t.Error("receipt was fetched for a pending transaction")
+	}
+}
+
+func TestAccountMethods(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.result("eth_accounts", []string{testAddress})
+	node.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, *fakeError) {
+		var addr, block string
+		json.Unmarshal(params[0], &addr)
+		json.Unmarshal(params[1], &block)
+		if !strings.EqualFold(addr, testAddress) || block != "latest" {
+			return nil, &fakeError{Code: CodeInvalidParams, Message: "unexpected params"}
+		}
+		return "0x1bc16d674ec80000", nil
+	})
+	node.result("eth_getTransactionCount", "0x3")
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	balance, err := c.GetAccountBalance(ctx, testAddress)
+	if err != nil || balance.String() != "2000000000000000000" {
+		t.Errorf("GetAccountBalance() = %v, %v, want 2 ether in wei", balance, err)
+	}
+	if _, err := c.GetAccountBalance(ctx, "0x12"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("GetAccountBalance(invalid) error = %v, want ErrInvalidAddress", err)
+	}
+
+	accounts, err := c.ListAccounts(ctx)
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Nonce != 3 || accounts[0].Balance.String() != "2000000000000000000" {
+		t.Errorf("ListAccounts() = %+v", accounts)
+	}
+}
+
+func TestNodeMethods(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.result("web3_clientVersion", "Geth/v1.15.4")
+	node.result("net_version", "1337")
+	node.result("eth_chainId", "0x539")
+	node.result("eth_syncing", false)
+	node.result("eth_blockNumber", "0x64")
+	node.result("net_listening", true)
+	// net_peerCount is left out, as development nodes do
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	status, err := c.GetNodeStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetNodeStatus: %v", err)
+	}
+	want := types.NodeStatus{
+		ClientVersion: "Geth/v1.15.4",
+		NetworkID:     "1337",
+		ChainID:       1337,
+		BlockNumber:   100,
+		Listening:     true,
+	}
+	if *status != want {
+		t.Errorf("GetNodeStatus() = %+v, want %+v", *status, want)
+	}
+
+	if _, err := c.PeerCount(ctx); !IsMethodNotFound(err) {
+		t.Errorf("PeerCount() error = %v, want method not found", err)
+	}
+
+	node.result("eth_syncing", map[string]string{"startingBlock": "0x0", "currentBlock": "0x10", "highestBlock": "0x20"})
+	sync, err := c.GetSyncStatus(ctx)
+	if err != nil || !sync.Syncing || sync.CurrentBlock != 16 || sync.HighestBlock != 32 {
+		t.Errorf("GetSyncStatus() = %+v, %v, want syncing at 16 of 32", sync, err)
+	}
+}
+
+func TestErrorWrapping(t *testing.T) {
+	node, url := newFakeNode(t)
+	node.handle("eth_call", func([]json.RawMessage) (interface{}, *fakeError) {
+		return nil, &fakeError{Code: CodeExecution, Message: "execution reverted", Data: "0x08c379a0"}
+	})
+	node.result("eth_blockNumber", true)
+	c := newTestClient(t, url)
+	ctx := context.Background()
+
+	err := c.Call(ctx, new(string), "eth_call")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("eth_call error = %T %v, want *RPCError", err, err)
+	}
+	if rpcErr.Method != "eth_call" || rpcErr.Code != CodeExecution || rpcErr.Message != "execution reverted" {
+		t.Errorf("RPCError = %+v", rpcErr)
+	}
+	if data, ok := RevertData(err); !ok || hexutil.Encode(data) != "0x08c379a0" {
+		t.Errorf("RevertData() = %x, %v", data, ok)
+	}
+
+	if err := c.Call(ctx, new(string), "eth_nope"); !IsMethodNotFound(err) {
+		t.Errorf("unknown method error = %v, want method not found", err)
+	}
+
+	_, err = c.BlockNumber(ctx)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Method != "eth_blockNumber" {
+		t.Errorf("BlockNumber() with a bool result error = %T %v, want *DecodeError", err, err)
+	}
+
+	node.mu.Lock()
+	node.status = http.StatusServiceUnavailable
+	node.mu.Unlock()
+	_, err = c.BlockNumber(ctx)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("BlockNumber() on 503 error = %T %v, want *HTTPError", err, err)
+	}
+	if httpErr.StatusCode != http.StatusServiceUnavailable || httpErr.Method != "eth_blockNumber" {
+		t.Errorf("HTTPError = %+v", httpErr)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	node, url := newFakeNode(t)
+	release := make(chan struct{})
+	defer close(release)
+	node.hook = func(r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}
+	node.result("eth_blockNumber", "0x1")
+	c := newTestClient(t, url, WithRetries(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.BlockNumber(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("BlockNumber() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("cancelled call took %v", elapsed)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.BlockNumber(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("BlockNumber() past deadline error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package rpc provides a client for Ethereum-compatible JSON-RPC nodes.
+//
+// The client wraps the eth_*, net_* and web3_* namespaces and converts the
+// responses into the types defined in pkg/types. All calls honor context
+// cancellation and report failures as the typed errors in errors.go.
+//
+// A client may be given several endpoints of the same chain. Calls go to the
+// first healthy one; throttling, server errors, timeouts and connection
+// failures are retried on the others with backoff.
+package rpc
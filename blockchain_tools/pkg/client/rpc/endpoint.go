@@ -0,0 +1,370 @@
+// pkg/client/rpc/endpoint.go
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/logger"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRetries is how often a call that failed transiently is retried
+	DefaultRetries = 2
+	// DefaultBackoff is the delay before retrying endpoints that were all
+	// tried already. It doubles with every round up to MaxBackoff.
+	DefaultBackoff = 250 * time.Millisecond
+	// MaxBackoff caps the delay between retries
+	MaxBackoff = 5 * time.Second
+	// DefaultCooldown is how long a failed endpoint is passed over in favor
+	// of the others
+	DefaultCooldown = 30 * time.Second
+)
+
+// codeLimitExceeded is returned by hosted nodes that throttle a client
+const codeLimitExceeded = -32005
+
+// sendMethods submit transactions. They are only retried when the node
+// cannot have received the request, so a transaction is never sent twice.
+var sendMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_sendTransaction":    true,
+}
+
+// rateLimit is a number of requests per second with a burst size
+type rateLimit struct {
+	rps   float64
+	burst int
+}
+
+// endpoint is one node the client can send calls to
+type endpoint struct {
+	url     string
+	host    string
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	transport Transport
+	downUntil time.Time
+	lastErr   error
+	requests  uint64
+	failures  uint64
+	retries   uint64
+	latency   time.Duration
+}
+
+func newEndpoint(url string, limit *rateLimit) *endpoint {
+	e := &endpoint{url: url, host: hostOf(url)}
+	if limit != nil && limit.rps > 0 {
+		burst := limit.burst
+		if burst < 1 {
+			burst = 1
+		}
+		e.limiter = rate.NewLimiter(rate.Limit(limit.rps), burst)
+	}
+	return e
+}
+
+// conn returns the transport of the endpoint, dialing it again when an
+// earlier dial failed
+func (e *endpoint) conn(ctx context.Context, dial Dialer) (Transport, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.transport == nil {
+		t, err := dial(ctx, e.url)
+		if err != nil {
+			return nil, &TransportError{Method: "dial", Err: err}
+		}
+		e.transport = t
+	}
+	return e.transport, nil
+}
+
+// down returns when the endpoint is tried again after failing, or
+// the zero time when it has not failed recently
+func (e *endpoint) down(now time.Time) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now.Before(e.downUntil) {
+		return e.downUntil
+	}
+	return time.Time{}
+}
+
+func (e *endpoint) markDown(err error, cooldown time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil = time.Now().Add(cooldown)
+	e.lastErr = err
+}
+
+func (e *endpoint) markUp() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil = time.Time{}
+}
+
+// record counts a call sent to the endpoint. Only failures of the endpoint
+// itself count, not errors such as reverts that the node answered with.
+func (e *endpoint) record(latency time.Duration, err error, retry bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests++
+	e.latency += latency
+	if retry {
+		e.retries++
+	}
+	if err != nil && transient(err) {
+		e.failures++
+		e.lastErr = err
+	}
+}
+
+func (e *endpoint) status() *types.Endpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := &types.Endpoint{
+		URL:      e.url,
+		Healthy:  !time.Now().Before(e.downUntil),
+		Requests: e.requests,
+		Failures: e.failures,
+		Retries:  e.retries,
+	}
+	if e.requests > 0 {
+		s.AvgLatencyMs = float64(e.latency) / float64(e.requests) / float64(time.Millisecond)
+	}
+	if e.lastErr != nil {
+		s.LastError = e.lastErr.Error()
+	}
+	return s
+}
+
+func (e *endpoint) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.transport != nil {
+		e.transport.Close()
+		e.transport = nil
+	}
+}
+
+// candidates returns the endpoints in the order calls try them: those that
+// have not failed recently in the configured order, then the others by how
+// soon they recover
+func (c *Client) candidates() []*endpoint {
+	now := time.Now()
+	until := make(map[*endpoint]time.Time, len(c.endpoints))
+	for _, e := range c.endpoints {
+		until[e] = e.down(now)
+	}
+
+	candidates := slices.Clone(c.endpoints)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return until[candidates[i]].Before(until[candidates[j]])
+	})
+	return candidates
+}
+
+// call sends a request for method to the first candidate endpoint,
+// retrying transient failures on the next ones. Once every endpoint was
+// tried, it backs off before starting over. n is the number of calls the
+// request carries, which is more than one for batches.
+func (c *Client) call(ctx context.Context, method string, n int, send func(ctx context.Context, t Transport) error) error {
+	candidates := c.candidates()
+	attempts := c.retries + 1
+	if c.retries > 0 && attempts < len(candidates) {
+		attempts = len(candidates)
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if round := i / len(candidates); i > 0 && i%len(candidates) == 0 {
+			if err := sleep(ctx, c.backoffDelay(round)); err != nil {
+				return err
+			}
+		}
+
+		e := candidates[i%len(candidates)]
+		err = c.callEndpoint(ctx, e, i > 0, method, n, send)
+		if err == nil || ctx.Err() != nil || !retryable(method, err) {
+			return err
+		}
+		e.markDown(err, c.cooldown)
+		logger.InfoContext(ctx, c.logger, "RPC endpoint failed", "method", method, "endpoint", e.url, "error", err)
+	}
+	return err
+}
+
+// callEndpoint sends one request to e, waiting for its rate limit first
+func (c *Client) callEndpoint(ctx context.Context, e *endpoint, retry bool, method string, n int, send func(ctx context.Context, t Transport) error) error {
+	if e.limiter != nil {
+		if err := e.limiter.WaitN(ctx, min(n, e.limiter.Burst())); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	ctx, span := startSpan(ctx, e, method, n, retry)
+	t, err := e.conn(ctx, c.dial)
+	if err != nil {
+		e.record(0, err, retry)
+		c.observe(method, 0, err)
+		endSpan(span, err)
+		return err
+	}
+
+	start := time.Now()
+	if err = send(ctx, t); err != nil {
+		err = wrapError(method, err)
+	}
+	elapsed := time.Since(start)
+	e.record(elapsed, err, retry)
+	c.observe(method, elapsed, err)
+	endSpan(span, err)
+	return err
+}
+
+// backoffDelay returns the delay before retry round n, with jitter so that
+// clients do not retry in lockstep
+func (c *Client) backoffDelay(n int) time.Duration {
+	d := c.backoff << (n - 1)
+	if d <= 0 || d > MaxBackoff {
+		d = MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// CheckHealth calls eth_blockNumber on every endpoint and marks those that
+// fail as unhealthy, so calls avoid them until they recover
+func (c *Client) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			if err := c.ping(ctx, e); err != nil {
+				e.markDown(err, c.cooldown)
+			} else {
+				e.markUp()
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+func (c *Client) ping(ctx context.Context, e *endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	t, err := e.conn(ctx, c.dial)
+	if err != nil {
+		return err
+	}
+	var head hexutil.Uint64
+	if err := t.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return wrapError("eth_blockNumber", err)
+	}
+	return nil
+}
+
+// checkHealthEvery runs CheckHealth every interval until ctx is done
+func (c *Client) checkHealthEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckHealth(ctx)
+		}
+	}
+}
+
+// Endpoints reports the health and request counters of every endpoint
+func (c *Client) Endpoints() []*types.Endpoint {
+	endpoints := make([]*types.Endpoint, len(c.endpoints))
+	for i, e := range c.endpoints {
+		endpoints[i] = e.status()
+	}
+	return endpoints
+}
+
+// transient reports whether err may go away when the call is sent again or
+// to another endpoint: throttling, server errors, timeouts and connection
+// failures
+func transient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == codeLimitExceeded
+	}
+	var transportErr *TransportError
+	return errors.As(err, &transportErr)
+}
+
+// retryable reports whether a call of method that failed with err may be
+// sent again
+func retryable(method string, err error) bool {
+	if !transient(err) {
+		return false
+	}
+	if sendMethods[method] {
+		return unsent(err)
+	}
+	return true
+}
+
+// unsent reports whether err shows that the request never reached the node
+func unsent(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == codeLimitExceeded
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) && transportErr.Method == "dial" {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,169 @@
+// pkg/client/rpc/errors.go
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// ErrNotFound is returned when the node has no data for the request
+	ErrNotFound = errors.New("not found")
+	// ErrNoEndpoint is returned when a client is created without a URL
+	ErrNoEndpoint = errors.New("no RPC endpoint configured")
+	// ErrInvalidAddress is returned for malformed account addresses
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrInvalidHash is returned for malformed block or transaction hashes
+	ErrInvalidHash = errors.New("invalid hash")
+	// ErrInvalidRange is returned for a block range that ends before it starts
+	ErrInvalidRange = errors.New("invalid block range")
+	// ErrInvalidBlock is returned for a malformed block number or tag
+	ErrInvalidBlock = errors.New("invalid block")
+	// ErrInvalidOverride is returned for a malformed state override
+	ErrInvalidOverride = errors.New("invalid state override")
+	// ErrNoAccounts is returned when the node exposes no accounts
+	ErrNoAccounts = errors.New("node has no accounts")
+	// ErrTxDropped is returned when a transaction left the pool unmined
+	ErrTxDropped = errors.New("transaction dropped")
+	// ErrTxReplaced is returned when another transaction used the same nonce
+	ErrTxReplaced = errors.New("transaction replaced")
+)
+
+// Standard JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000
+	CodeExecution      = 3
+)
+
+// RPCError is an error object returned by the node
+type RPCError struct {
+	Method  string
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s: %s (code %d)", e.Method, e.Message, e.Code)
+}
+
+// IsMethodNotFound reports whether the node does not implement the method
+func (e *RPCError) IsMethodNotFound() bool {
+	return e.Code == CodeMethodNotFound
+}
+
+// HTTPError is returned when the node answers with a non-2xx HTTP status
+type HTTPError struct {
+	Method     string
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s: %s", e.Method, e.Status)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Method, e.Status, e.Body)
+}
+
+// DecodeError is returned when a node response cannot be decoded
+type DecodeError struct {
+	Method string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: failed to decode response: %v", e.Method, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned when the node could not be reached
+type TransportError struct {
+	Method string
+	Err    error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Method, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// IsMethodNotFound reports whether err says the node lacks a method
+func IsMethodNotFound(err error) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr) && rpcErr.IsMethodNotFound()
+}
+
+// RevertData returns the data of a reverted eth_call or eth_estimateGas,
+// which encodes the revert reason or custom error
+func RevertData(err error) ([]byte, bool) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, false
+	}
+	s, ok := rpcErr.Data.(string)
+	if !ok {
+		return nil, false
+	}
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// wrapError converts errors from the wire layer into the typed errors above
+func wrapError(method string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var httpErr gethrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return &HTTPError{
+			Method:     method,
+			StatusCode: httpErr.StatusCode,
+			Status:     httpErr.Status,
+			Body:       httpErr.Body,
+		}
+	}
+
+	var codeErr gethrpc.Error
+	if errors.As(err, &codeErr) {
+		rpcErr := &RPCError{
+			Method:  method,
+			Code:    codeErr.ErrorCode(),
+			Message: codeErr.Error(),
+		}
+		var dataErr gethrpc.DataError
+		if errors.As(err, &dataErr) {
+			rpcErr.Data = dataErr.ErrorData()
+		}
+		return rpcErr
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return &DecodeError{Method: method, Err: err}
+	}
+
+	return &TransportError{Method: method, Err: err}
+}
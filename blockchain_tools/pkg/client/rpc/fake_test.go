@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeError is returned by a fakeNode method to answer with a JSON-RPC
+// error object
+type fakeError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *fakeError) Error() string { return e.Message }
+
+// fakeMethod answers a call with its result, which is encoded as JSON
+// unless it is a json.RawMessage, or with a *fakeError
+type fakeMethod func(params []json.RawMessage) (interface{}, *fakeError)
+
+// fakeNode is an in-process JSON-RPC server answering single and batch
+// requests from the methods registered with handle
+type fakeNode struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	methods map[string]fakeMethod
+	calls   map[string]int
+	// status makes every request fail with this HTTP status when set
+	status int
+	// hook runs before each HTTP request is answered
+	hook func(r *http.Request)
+}
+
+type fakeRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type fakeResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *fakeError      `json:"error,omitempty"`
+}
+
+// newFakeNode starts a fake node that is stopped when the test ends
+func newFakeNode(t testing.TB) (*fakeNode, string) {
+	n := &fakeNode{
+		methods: make(map[string]fakeMethod),
+		calls:   make(map[string]int),
+	}
+	srv := httptest.NewServer(n)
+	t.Cleanup(srv.Close)
+	return n, srv.URL
+}
+
+// handle registers fn as the handler of method
+func (n *fakeNode) handle(method string, fn fakeMethod) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.methods[method] = fn
+}
+
+// result registers a method that always answers with v
+func (n *fakeNode) result(method string, v interface{}) {
+	n.handle(method, func([]json.RawMessage) (interface{}, *fakeError) { return v, nil })
+}
+
+// count returns how often method was called
+func (n *fakeNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if n.hook != nil {
+		n.hook(r)
+	}
+	n.mu.Lock()
+	status := n.status
+	n.mu.Unlock()
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var reqs []fakeRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]fakeResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = n.answer(req)
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	var req fakeRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(n.answer(req))
+}
+
+func (n *fakeNode) answer(req fakeRequest) fakeResponse {
+	n.mu.Lock()
+	n.calls[req.Method]++
+	fn, ok := n.methods[req.Method]
+	n.mu.Unlock()
+
+	resp := fakeResponse{JSONRPC: "2.0", ID: req.ID}
+	if !ok {
+		resp.Error = &fakeError{Code: CodeMethodNotFound, Message: "the method " + req.Method + " does not exist/is not available"}
+		return resp
+	}
+	result, rpcErr := fn(req.Params)
+	if rpcErr != nil {
+		resp.Error = rpcErr
+		return resp
+	}
+	if raw, ok := result.(json.RawMessage); ok {
+		resp.Result = raw
+		return resp
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		resp.Error = &fakeError{Code: CodeInternalError, Message: err.Error()}
+		return resp
+	}
+	resp.Result = raw
+	return resp
+}
+
+// newTestClient returns a client for the fake node at url that does not
+// retry, so failures surface at once
+func newTestClient(t testing.TB, url string, opts ...Option) *Client {
+	t.Helper()
+	c, err := NewClient(url, append([]Option{WithRetries(0)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
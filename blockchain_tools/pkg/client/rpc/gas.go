@@ -0,0 +1,129 @@
+// pkg/client/rpc/gas.go
+package rpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PendingNonceAt returns the next nonce for addr, counting transactions
+// still in the node's pool
+func (c *Client) PendingNonceAt(ctx context.Context, addr common.Address) (uint64, error) {
+	var nonce hexutil.Uint64
+	if err := c.Call(ctx, &nonce, "eth_getTransactionCount", addr, "pending"); err != nil {
+		return 0, err
+	}
+	return uint64(nonce), nil
+}
+
+// NonceAt returns the number of transactions from addr included in the
+// latest block
+func (c *Client) NonceAt(ctx context.Context, addr common.Address) (uint64, error) {
+	var nonce hexutil.Uint64
+	if err := c.Call(ctx, &nonce, "eth_getTransactionCount", addr, "latest"); err != nil {
+		return 0, err
+	}
+	return uint64(nonce), nil
+}
+
+// EstimateGas returns the gas the node expects msg to consume
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var gas hexutil.Uint64
+	if err := c.Call(ctx, &gas, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return uint64(gas), nil
+}
+
+// SuggestGasPrice returns the node's legacy gas price suggestion
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var price hexutil.Big
+	if err := c.Call(ctx, &price, "eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	return price.ToInt(), nil
+}
+
+// SuggestGasTipCap returns the node's EIP-1559 priority fee suggestion
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	var tip hexutil.Big
+	if err := c.Call(ctx, &tip, "eth_maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	return tip.ToInt(), nil
+}
+
+// toCallArg converts msg into the transaction call object used by
+// eth_call and eth_estimateGas
+func toCallArg(msg ethereum.CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+	}
+	if msg.To != nil {
+		arg["to"] = msg.To
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
+
+// FeeHistory is the decoded result of eth_feeHistory
+type FeeHistory struct {
+	OldestBlock  uint64
+	BaseFee      []*big.Int // one more entry than blocks: the next block's base fee
+	GasUsedRatio []float64
+	Reward       [][]*big.Int // per block, one entry per requested percentile
+}
+
+// FeeHistory returns base fees and priority fee percentiles for the
+// blockCount blocks ending at lastBlock ("latest", "pending" or a number)
+func (c *Client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock string, percentiles []float64) (*FeeHistory, error) {
+	var res struct {
+		OldestBlock  hexutil.Uint64   `json:"oldestBlock"`
+		Reward       [][]*hexutil.Big `json:"reward"`
+		BaseFee      []*hexutil.Big   `json:"baseFeePerGas"`
+		GasUsedRatio []float64        `json:"gasUsedRatio"`
+	}
+	if err := c.Call(ctx, &res, "eth_feeHistory", hexutil.Uint64(blockCount), lastBlock, percentiles); err != nil {
+		return nil, err
+	}
+
+	history := &FeeHistory{
+		OldestBlock:  uint64(res.OldestBlock),
+		GasUsedRatio: res.GasUsedRatio,
+	}
+	for _, fee := range res.BaseFee {
+		history.BaseFee = append(history.BaseFee, fee.ToInt())
+	}
+	for _, rewards := range res.Reward {
+		row := make([]*big.Int, 0, len(rewards))
+		for _, r := range rewards {
+			row = append(row, r.ToInt())
+		}
+		history.Reward = append(history.Reward, row)
+	}
+	return history, nil
+}
@@ -0,0 +1,167 @@
+// pkg/client/rpc/logs.go
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// DefaultLogChunkSize is the number of blocks QueryLogs asks for at once,
+// below the range limits of common providers
+const DefaultLogChunkSize = 2000
+
+// LogFilter selects contract logs. Addresses match any of the listed
+// contracts. Topics are matched by position: each position holds the
+// accepted values for that topic, and an empty position matches anything.
+type LogFilter struct {
+	FromBlock *uint64
+	ToBlock   *uint64
+	Addresses []string
+	Topics    [][]string
+}
+
+// GetLogs returns the logs matching filter
+func (c *Client) GetLogs(ctx context.Context, filter LogFilter) ([]*types.Log, error) {
+	arg, err := filter.toArg()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []*rpcLog
+	if err := c.Call(ctx, &logs, "eth_getLogs", arg); err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.Log, 0, len(logs))
+	for _, l := range logs {
+		result = append(result, l.toLog())
+	}
+	return result, nil
+}
+
+// QueryLogs returns the logs matching filter over a block range of any size
+// by splitting it into requests of at most chunkSize blocks. A missing
+// ToBlock means the current head and a missing FromBlock means ToBlock.
+// When the node rejects a chunk, for instance because it holds too many
+// logs, the chunk is halved and retried down to a single block.
+func (c *Client) QueryLogs(ctx context.Context, filter LogFilter, chunkSize uint64) ([]*types.Log, error) {
+	if _, err := filter.toArg(); err != nil {
+		return nil, err
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultLogChunkSize
+	}
+
+	var to uint64
+	if filter.ToBlock != nil {
+		to = *filter.ToBlock
+	} else {
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		to = head
+	}
+	from := to
+	if filter.FromBlock != nil {
+		from = *filter.FromBlock
+	}
+	if from > to {
+		return nil, fmt.Errorf("%w: from block %d is after to block %d", ErrInvalidRange, from, to)
+	}
+
+	logs := make([]*types.Log, 0)
+	for start := from; ; {
+		end := to
+		if to-start >= chunkSize {
+			end = start + chunkSize - 1
+		}
+
+		chunk := filter
+		chunk.FromBlock, chunk.ToBlock = &start, &end
+		got, err := c.GetLogs(ctx, chunk)
+		if err != nil {
+			var rpcErr *RPCError
+			if errors.As(err, &rpcErr) && !rpcErr.IsMethodNotFound() && end > start {
+				chunkSize = (end - start + 1) / 2
+				continue
+			}
+			return nil, err
+		}
+		logs = append(logs, got...)
+
+		if end == to {
+			return logs, nil
+		}
+		start = end + 1
+	}
+}
+
+// ParseTopics builds the Topics of a LogFilter from one string per
+// position. Each string lists the accepted topics separated by commas; an
+// empty string or "*" matches any topic.
+func ParseTopics(values []string) [][]string {
+	topics := make([][]string, 0, len(values))
+	for _, v := range values {
+		var position []string
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" && t != "*" {
+				position = append(position, t)
+			}
+		}
+		topics = append(topics, position)
+	}
+	return topics
+}
+
+// toArg converts the filter to its JSON-RPC form, validating addresses
+// and topics
+func (f LogFilter) toArg() (map[string]interface{}, error) {
+	arg := make(map[string]interface{})
+
+	if len(f.Addresses) > 0 {
+		addresses := make([]common.Address, 0, len(f.Addresses))
+		for _, a := range f.Addresses {
+			addr, err := parseAddress(a)
+			if err != nil {
+				return nil, err
+			}
+			addresses = append(addresses, addr)
+		}
+		arg["address"] = addresses
+	}
+
+	if len(f.Topics) > 0 {
+		topics := make([]interface{}, 0, len(f.Topics))
+		for _, position := range f.Topics {
+			if len(position) == 0 {
+				topics = append(topics, nil)
+				continue
+			}
+			hashes := make([]common.Hash, 0, len(position))
+			for _, t := range position {
+				h, err := parseHash(t)
+				if err != nil {
+					return nil, err
+				}
+				hashes = append(hashes, h)
+			}
+			topics = append(topics, hashes)
+		}
+		arg["topics"] = topics
+	}
+
+	if f.FromBlock != nil {
+		arg["fromBlock"] = hexutil.EncodeUint64(*f.FromBlock)
+	}
+	if f.ToBlock != nil {
+		arg["toBlock"] = hexutil.EncodeUint64(*f.ToBlock)
+	}
+	return arg, nil
+}
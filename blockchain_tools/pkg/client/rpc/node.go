@@ -0,0 +1,135 @@
+// pkg/client/rpc/node.go
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// ChainID returns the EIP-155 chain ID of the node
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var id hexutil.Big
+	if err := c.Call(ctx, &id, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return id.ToInt(), nil
+}
+
+// NetworkID returns the network ID reported by net_version
+func (c *Client) NetworkID(ctx context.Context) (string, error) {
+	var id string
+	if err := c.Call(ctx, &id, "net_version"); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ClientVersion returns the node software version
+func (c *Client) ClientVersion(ctx context.Context) (string, error) {
+	var version string
+	if err := c.Call(ctx, &version, "web3_clientVersion"); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// PeerCount returns the number of peers connected to the node
+func (c *Client) PeerCount(ctx context.Context) (uint64, error) {
+	var count hexutil.Uint64
+	if err := c.Call(ctx, &count, "net_peerCount"); err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+// GetPeers returns the peers connected to the node. This requires the
+// admin namespace to be enabled.
+func (c *Client) GetPeers(ctx context.Context) ([]*types.Peer, error) {
+	var rpcPeers []*rpcPeer
+	if err := c.Call(ctx, &rpcPeers, "admin_peers"); err != nil {
+		return nil, err
+	}
+
+	peers := make([]*types.Peer, 0, len(rpcPeers))
+	for _, p := range rpcPeers {
+		peers = append(peers, p.toPeer())
+	}
+	return peers, nil
+}
+
+// GetSyncStatus returns the synchronization progress of the node
+func (c *Client) GetSyncStatus(ctx context.Context) (*types.SyncStatus, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, &raw, "eth_syncing"); err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(raw, []byte("false")) {
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &types.SyncStatus{CurrentBlock: head, HighestBlock: head}, nil
+	}
+
+	var progress rpcSyncProgress
+	if err := json.Unmarshal(raw, &progress); err != nil {
+		return nil, &DecodeError{Method: "eth_syncing", Err: err}
+	}
+	return &types.SyncStatus{
+		Syncing:       true,
+		StartingBlock: uint64(progress.StartingBlock),
+		CurrentBlock:  uint64(progress.CurrentBlock),
+		HighestBlock:  uint64(progress.HighestBlock),
+	}, nil
+}
+
+// GetNodeStatus collects version, network and chain information from the
+// node. Peer information is optional since development nodes such as Anvil
+// do not always expose it.
+func (c *Client) GetNodeStatus(ctx context.Context) (*types.NodeStatus, error) {
+	version, err := c.ClientVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	networkID, err := c.NetworkID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := c.GetSyncStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &types.NodeStatus{
+		ClientVersion: version,
+		NetworkID:     networkID,
+		ChainID:       chainID.Uint64(),
+		BlockNumber:   sync.CurrentBlock,
+		Syncing:       sync.Syncing,
+	}
+
+	if peers, err := c.PeerCount(ctx); err == nil {
+		status.PeerCount = peers
+	} else if !IsMethodNotFound(err) {
+		return nil, err
+	}
+
+	var listening bool
+	if err := c.Call(ctx, &listening, "net_listening"); err == nil {
+		status.Listening = listening
+	} else if !IsMethodNotFound(err) {
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,168 @@
+// pkg/client/rpc/simulate.go
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// Simulate runs tx with eth_estimateGas and eth_call at block, with the
+// account state replaced by state, and reports whether it would succeed.
+// block is a block number or tag as for CallContract. The call is made
+// with the gas limit of tx, or the estimate when tx has none. A
+// transaction the node rejects or that reverts is reported in the result
+// rather than as an error; errors are reserved for failures to reach the
+// node.
+func (c *Client) Simulate(ctx context.Context, tx *types.Transaction, block string, state types.StateOverrides) (*types.Simulation, error) {
+	tag, err := blockTag(block)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := tx.CallMsg()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := toStateOverrides(state)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &types.Simulation{Transaction: tx, Block: tag}
+
+	var gas hexutil.Uint64
+	if err := c.Call(ctx, &gas, "eth_estimateGas", simulationParams(msg, tag, overrides)...); err != nil {
+		return simulationFailure(sim, err)
+	}
+	sim.GasEstimate = uint64(gas)
+
+	if msg.Gas == 0 {
+		msg.Gas = sim.GasEstimate
+	}
+	var result hexutil.Bytes
+	if err := c.Call(ctx, &result, "eth_call", simulationParams(msg, tag, overrides)...); err != nil {
+		return simulationFailure(sim, err)
+	}
+	sim.ReturnData = result
+	sim.Success = true
+
+	return sim, nil
+}
+
+func simulationParams(msg ethereum.CallMsg, tag string, overrides map[common.Address]map[string]interface{}) []interface{} {
+	params := []interface{}{toCallArg(msg), tag}
+	if len(overrides) > 0 {
+		params = append(params, overrides)
+	}
+	return params
+}
+
+// simulationFailure records an error the node returned for the simulated
+// transaction in sim and passes any other error through
+func simulationFailure(sim *types.Simulation, err error) (*types.Simulation, error) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.IsMethodNotFound() {
+		return nil, err
+	}
+	sim.Error = rpcErr.Message
+	sim.RevertData, _ = RevertData(err)
+	return sim, nil
+}
+
+// toStateOverrides converts state into the state override set accepted by
+// eth_call and eth_estimateGas
+func toStateOverrides(state types.StateOverrides) (map[common.Address]map[string]interface{}, error) {
+	overrides := make(map[common.Address]map[string]interface{}, len(state))
+	for address, account := range state {
+		addr, err := parseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not an address", ErrInvalidOverride, address)
+		}
+		if account == nil {
+			continue
+		}
+
+		override := make(map[string]interface{})
+		if account.Balance != nil {
+			override["balance"] = (*hexutil.Big)(account.Balance)
+		}
+		if account.Nonce != nil {
+			override["nonce"] = hexutil.Uint64(*account.Nonce)
+		}
+		if account.Code != nil {
+			override["code"] = hexutil.Bytes(account.Code)
+		}
+		if len(account.StateDiff) > 0 {
+			diff := make(map[common.Hash]common.Hash, len(account.StateDiff))
+			for slot, value := range account.StateDiff {
+				key, err := parseWord(slot)
+				if err != nil {
+					return nil, fmt.Errorf("%w for %s: invalid slot %q", ErrInvalidOverride, addr.Hex(), slot)
+				}
+				if diff[key], err = parseWord(value); err != nil {
+					return nil, fmt.Errorf("%w for %s: invalid value %q", ErrInvalidOverride, addr.Hex(), value)
+				}
+			}
+			override["stateDiff"] = diff
+		}
+		overrides[addr] = override
+	}
+	return overrides, nil
+}
+
+// parseWord decodes a hex storage slot or value of up to 32 bytes
+func parseWord(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid word %q", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// Simulate builds tx as Build does and simulates it at block instead of
+// sending it. The gas limit comes from the simulation's estimate times the
+// builder's multiplier unless overridden, so a transaction that would
+// revert still yields a result. The nonce is read without being reserved.
+func (b *TxBuilder) Simulate(ctx context.Context, tx *types.Transaction, overrides *TxOverrides, block string, state types.StateOverrides) (*types.Simulation, error) {
+	if overrides == nil {
+		overrides = &TxOverrides{}
+	}
+	opts := *overrides
+	if opts.Gas == nil {
+		var gas uint64
+		opts.Gas = &gas
+	}
+	from, err := b.sender(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Nonce == nil {
+		nonce, err := b.client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		opts.Nonce = &nonce
+	}
+
+	sender := *tx
+	sender.From = from.Hex()
+	built, err := b.Build(ctx, &sender, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sim, err := b.client.Simulate(ctx, built, block, state)
+	if err != nil {
+		return nil, err
+	}
+	if overrides.Gas == nil && sim.GasEstimate > 0 {
+		built.Gas = uint64(math.Ceil(float64(sim.GasEstimate) * b.gasMultiplier))
+	}
+	return sim, nil
+}
@@ -0,0 +1,382 @@
+// pkg/client/rpc/subscribe.go
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/follower"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// Subscription is a running stream of chain notifications
+type Subscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    chan error
+}
+
+// Err returns a channel that is closed when the subscription ends. If it
+// ended because of a failure, the error is delivered before the close.
+func (s *Subscription) Err() <-chan error {
+	return s.err
+}
+
+// Unsubscribe stops the subscription and waits for it to finish. No values
+// are delivered after it returns.
+func (s *Subscription) Unsubscribe() {
+	s.cancel()
+	<-s.done
+}
+
+// newSubscription runs fn in the background until it returns or the
+// subscription is stopped
+func newSubscription(ctx context.Context, fn func(ctx context.Context) error) *Subscription {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Subscription{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		err:    make(chan error, 1),
+	}
+	go func() {
+		defer close(s.done)
+		defer close(s.err)
+		if err := fn(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			s.err <- err
+		}
+	}()
+	return s
+}
+
+// SubscribeNewHeads delivers every new block header to ch. Blocks received
+// through eth_subscribe carry no transactions; polled blocks do.
+func (c *Client) SubscribeNewHeads(ctx context.Context, ch chan<- *types.Block) (*Subscription, error) {
+	if c.supportsSubscriptions() {
+		return c.subscribe(ctx, func(ctx context.Context, msg json.RawMessage) error {
+			var header rpcBlock
+			if err := json.Unmarshal(msg, &header); err != nil {
+				return &DecodeError{Method: "eth_subscribe", Err: err}
+			}
+			select {
+			case ch <- header.toBlock():
+			case <-ctx.Done():
+			}
+			return nil
+		}, "newHeads")
+	}
+
+	last, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSubscription(ctx, func(ctx context.Context) error {
+		return pollUntil(ctx, c.pollInterval, func() error {
+			head, err := c.BlockNumber(ctx)
+			if err != nil {
+				return err
+			}
+			for ; last < head; last++ {
+				block, err := c.GetBlockByHeight(ctx, last+1)
+				if err != nil {
+					return err
+				}
+				select {
+				case ch <- block:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			return nil
+		})
+	}), nil
+}
+
+// SubscribePendingTransactions delivers the hash of every transaction
+// entering the node's pool to ch
+func (c *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- string) (*Subscription, error) {
+	if c.supportsSubscriptions() {
+		return c.subscribe(ctx, func(ctx context.Context, msg json.RawMessage) error {
+			var hash common.Hash
+			if err := json.Unmarshal(msg, &hash); err != nil {
+				return &DecodeError{Method: "eth_subscribe", Err: err}
+			}
+			select {
+			case ch <- hash.Hex():
+			case <-ctx.Done():
+			}
+			return nil
+		}, "newPendingTransactions")
+	}
+
+	var id string
+	if err := c.Call(ctx, &id, "eth_newPendingTransactionFilter"); err != nil {
+		return nil, err
+	}
+	return newSubscription(ctx, func(ctx context.Context) error {
+		defer c.Call(context.Background(), nil, "eth_uninstallFilter", id)
+
+		return pollUntil(ctx, c.pollInterval, func() error {
+			var hashes []common.Hash
+			if err := c.Call(ctx, &hashes, "eth_getFilterChanges", id); err != nil {
+				return err
+			}
+			for _, hash := range hashes {
+				select {
+				case ch <- hash.Hex():
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			return nil
+		})
+	}), nil
+}
+
+// SubscribeLogs delivers logs matching filter to ch as their blocks are
+// added to the chain. The block range of filter is ignored. Logs undone by a
+// reorg are delivered again with Removed set when the node reports it.
+func (c *Client) SubscribeLogs(ctx context.Context, filter LogFilter, ch chan<- *types.Log) (*Subscription, error) {
+	filter.FromBlock, filter.ToBlock = nil, nil
+	arg, err := filter.toArg()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.supportsSubscriptions() {
+		return c.subscribe(ctx, func(ctx context.Context, msg json.RawMessage) error {
+			var l rpcLog
+			if err := json.Unmarshal(msg, &l); err != nil {
+				return &DecodeError{Method: "eth_subscribe", Err: err}
+			}
+			select {
+			case ch <- l.toLog():
+			case <-ctx.Done():
+			}
+			return nil
+		}, "logs", arg)
+	}
+
+	last, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSubscription(ctx, func(ctx context.Context) error {
+		return pollUntil(ctx, c.pollInterval, func() error {
+			head, err := c.BlockNumber(ctx)
+			if err != nil || head <= last {
+				return err
+			}
+
+			from := last + 1
+			filter.FromBlock, filter.ToBlock = &from, &head
+			logs, err := c.GetLogs(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, l := range logs {
+				select {
+				case ch <- l:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			last = head
+			return nil
+		})
+	}), nil
+}
+
+// subscribe starts an eth_subscribe subscription and hands every
+// notification to deliver
+func (c *Client) subscribe(ctx context.Context, deliver func(ctx context.Context, msg json.RawMessage) error, args ...interface{}) (*Subscription, error) {
+	messages := make(chan json.RawMessage, 16)
+	sub, err := c.ethSubscribe(ctx, messages, args...)
+	if err != nil {
+		return nil, wrapError("eth_subscribe", err)
+	}
+
+	return newSubscription(ctx, func(ctx context.Context) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-sub.Err():
+				if err != nil {
+					return wrapError("eth_subscribe", err)
+				}
+				return nil
+			case msg := <-messages:
+				if err := deliver(ctx, msg); err != nil {
+					return err
+				}
+			}
+		}
+	}), nil
+}
+
+// WatchOptions selects the streams merged by Watch. When nothing is
+// selected, new heads are watched.
+type WatchOptions struct {
+	// NewHeads streams new blocks, and the blocks reorgs remove as
+	// EventReverted
+	NewHeads            bool
+	PendingTransactions bool
+	// Logs streams the logs matching the filter when set
+	Logs *LogFilter
+}
+
+// Watch merges the selected streams into events. The subscription fails as
+// soon as any of the underlying streams fails.
+func (c *Client) Watch(ctx context.Context, opts WatchOptions, events chan<- *types.Event) (*Subscription, error) {
+	if !opts.NewHeads && !opts.PendingTransactions && opts.Logs == nil {
+		opts.NewHeads = true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var (
+		subs []*Subscription
+		wg   sync.WaitGroup
+	)
+	stop := func() {
+		cancel()
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+		wg.Wait()
+	}
+
+	if opts.NewHeads {
+		sub, err := c.followHeads(ctx, events)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	if opts.PendingTransactions {
+		hashes := make(chan string)
+		sub, err := c.SubscribePendingTransactions(ctx, hashes)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		subs = append(subs, sub)
+		wg.Add(1)
+		go forward(ctx, &wg, hashes, events, func(hash string) *types.Event {
+			return &types.Event{Type: types.EventPendingTransaction, TransactionHash: hash}
+		})
+	}
+
+	if opts.Logs != nil {
+		logs := make(chan *types.Log)
+		sub, err := c.SubscribeLogs(ctx, *opts.Logs, logs)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		subs = append(subs, sub)
+		wg.Add(1)
+		go forward(ctx, &wg, logs, events, func(l *types.Log) *types.Event {
+			return &types.Event{Type: types.EventLog, Log: l}
+		})
+	}
+
+	failed := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub *Subscription) {
+			if err, ok := <-sub.Err(); ok {
+				failed <- err
+			}
+		}(sub)
+	}
+
+	return newSubscription(ctx, func(ctx context.Context) error {
+		defer stop()
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-failed:
+			return err
+		}
+	}), nil
+}
+
+// followHeads reports new blocks and the blocks removed by reorgs, starting
+// after the current head. It wakes up on every pushed head where the
+// endpoint supports subscriptions and polls otherwise.
+func (c *Client) followHeads(ctx context.Context, events chan<- *types.Event) (*Subscription, error) {
+	f := follower.New(c)
+	// The first step only records the current head. It is never reported,
+	// not even as reverted when a reorg removes it.
+	if err := f.Step(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	send := func(ctx context.Context, e follower.Event) error {
+		event := &types.Event{Type: types.EventNewHead, Block: e.Block}
+		if e.Type == follower.Reverted {
+			event.Type = types.EventReverted
+		}
+		select {
+		case events <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return newSubscription(ctx, func(ctx context.Context) error {
+		heads, stop := c.headTicker(ctx, c.pollInterval)
+		defer stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-heads:
+				if err := f.Step(ctx, send); err != nil {
+					return err
+				}
+			}
+		}
+	}), nil
+}
+
+// forward wraps every value received on in and sends it to out until ctx
+// is done
+func forward[T any](ctx context.Context, wg *sync.WaitGroup, in <-chan T, out chan<- *types.Event, wrap func(T) *types.Event) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case v := <-in:
+			select {
+			case out <- wrap(v):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// pollUntil calls fn every interval until ctx is done or fn fails
+func pollUntil(ctx context.Context, interval time.Duration, fn func() error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// pkg/client/rpc/trace.go
+package rpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// rpcCallFrame is the JSON shape of a callTracer frame
+type rpcCallFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to"`
+	Value   *hexutil.Big    `json:"value"`
+	Gas     hexutil.Uint64  `json:"gas"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output"`
+	Error   string          `json:"error"`
+	Calls   []*rpcCallFrame `json:"calls"`
+}
+
+// rpcStructLog is the JSON shape of a struct logger trace
+type rpcStructLog struct {
+	StructLogs []*types.TraceStep `json:"structLogs"`
+}
+
+func (f *rpcCallFrame) toCallFrame() *types.CallFrame {
+	frame := &types.CallFrame{
+		Type:    f.Type,
+		From:    f.From.Hex(),
+		Value:   bigOrNil(f.Value),
+		Gas:     uint64(f.Gas),
+		GasUsed: uint64(f.GasUsed),
+		Input:   f.Input,
+		Output:  f.Output,
+		Error:   f.Error,
+	}
+	if f.To != nil {
+		frame.To = f.To.Hex()
+	}
+	for _, call := range f.Calls {
+		frame.Calls = append(frame.Calls, call.toCallFrame())
+	}
+	return frame
+}
+
+// TraceTransaction re-executes a mined transaction with
+// debug_traceTransaction and returns its call tree, built by the
+// callTracer. With steps the opcodes it executed are collected as well
+// using the struct logger, which is slow and large for busy transactions.
+// A transaction that is unknown or still pending fails with ErrNotFound.
+func (c *Client) TraceTransaction(ctx context.Context, hash string, steps bool) (*types.Trace, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.GetTransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var call rpcCallFrame
+	if err := c.Call(ctx, &call, "debug_traceTransaction", h, map[string]interface{}{
+		"tracer": "callTracer",
+	}); err != nil {
+		return nil, err
+	}
+
+	trace := &types.Trace{
+		Hash:    h.Hex(),
+		Failed:  receipt.Status == types.StatusFailed,
+		GasUsed: receipt.GasUsed,
+		Call:    call.toCallFrame(),
+	}
+
+	if steps {
+		var logs rpcStructLog
+		if err := c.Call(ctx, &logs, "debug_traceTransaction", h, map[string]interface{}{
+			"disableStorage": true,
+		}); err != nil {
+			return nil, err
+		}
+		trace.Steps = logs.StructLogs
+	}
+
+	return trace, nil
+}
@@ -0,0 +1,66 @@
+// pkg/client/rpc/tracing.go
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a client span for every request sent to an endpoint. It
+// uses the global tracer provider, so spans are only recorded once the
+// application installs one.
+var tracer = otel.Tracer("github.com/layla-lili/blockchain_tools/pkg/client/rpc")
+
+// ContextWithHeader returns a copy of ctx that makes calls over HTTP send
+// the given header, such as a request ID
+func ContextWithHeader(ctx context.Context, key, value string) context.Context {
+	return gethrpc.NewContextWithHeaders(ctx, http.Header{key: {value}})
+}
+
+// startSpan starts the span of a request for method sent to e. The
+// returned context sends the span as traceparent header, so nodes that
+// trace requests continue the trace.
+func startSpan(ctx context.Context, e *endpoint, method string, n int, retry bool) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.RPCSystemKey.String("jsonrpc"),
+		semconv.RPCMethod(method),
+		semconv.ServerAddress(e.host),
+		attribute.Bool("rpc.retry", retry),
+	}
+	if n > 1 {
+		attrs = append(attrs, attribute.Int("rpc.batch_size", n))
+	}
+	ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	header := make(http.Header)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	return gethrpc.NewContextWithHeaders(ctx, header), span
+}
+
+// endSpan ends span, marking it failed when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hostOf returns the host of an endpoint URL. Spans carry the host only,
+// as hosted node URLs often hold an API key in their path.
+func hostOf(endpointURL string) string {
+	u, err := url.Parse(endpointURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,191 @@
+// pkg/client/rpc/transaction.go
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// RecentBlockWindow is the number of blocks ListTransactions scans
+const RecentBlockWindow = 10
+
+// GetTransaction returns the transaction with the given hash. Mined
+// transactions have their status resolved from the receipt.
+func (c *Client) GetTransaction(ctx context.Context, hash string) (*types.Transaction, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var rpcTx rpcTransaction
+	if err := c.callNullable(ctx, &rpcTx, "eth_getTransactionByHash", h); err != nil {
+		return nil, err
+	}
+	tx := rpcTx.toTransaction()
+	if rpcTx.BlockHash == nil {
+		return tx, nil
+	}
+
+	receipt, err := c.GetTransactionReceipt(ctx, hash)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return tx, nil
+	case err != nil:
+		return nil, err
+	default:
+		tx.Status = receipt.Status
+	}
+
+	var header struct {
+		Timestamp hexutil.Uint64 `json:"timestamp"`
+	}
+	if err := c.callNullable(ctx, &header, "eth_getBlockByHash", rpcTx.BlockHash, false); err == nil {
+		tx.Timestamp = int64(header.Timestamp)
+	}
+	return tx, nil
+}
+
+// GetTransactionReceipt returns the receipt of a mined transaction, or
+// ErrNotFound while it is still pending
+func (c *Client) GetTransactionReceipt(ctx context.Context, hash string) (*types.Receipt, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt rpcReceipt
+	if err := c.callNullable(ctx, &receipt, "eth_getTransactionReceipt", h); err != nil {
+		return nil, err
+	}
+	return receipt.toReceipt(), nil
+}
+
+// GetBlockReceipts returns the receipts of every transaction in block, in
+// block order. Nodes without eth_getBlockReceipts are asked for each receipt
+// in turn.
+func (c *Client) GetBlockReceipts(ctx context.Context, block *types.Block) ([]*types.Receipt, error) {
+	var receipts []*rpcReceipt
+	err := c.Call(ctx, &receipts, "eth_getBlockReceipts", block.Hash)
+	if err == nil && len(receipts) == len(block.Transactions) {
+		result := make([]*types.Receipt, 0, len(receipts))
+		for _, r := range receipts {
+			result = append(result, r.toReceipt())
+		}
+		return result, nil
+	}
+	if err != nil && !IsMethodNotFound(err) {
+		return nil, err
+	}
+
+	result := make([]*types.Receipt, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		receipt, err := c.GetTransactionReceipt(ctx, tx.Hash)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, receipt)
+	}
+	return result, nil
+}
+
+// SendTransaction submits tx through eth_sendTransaction and returns its
+// hash. The node must hold the sender's key; when From is empty the node's
+// first account is used. Gas and fee fields are passed when set, and a
+// nonce of zero leaves nonce selection to the node.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) (string, error) {
+	args := map[string]interface{}{}
+	if tx.Value != nil {
+		args["value"] = (*hexutil.Big)(tx.Value)
+	}
+
+	from := tx.From
+	if from == "" {
+		accounts, err := c.GetAccounts(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(accounts) == 0 {
+			return "", ErrNoAccounts
+		}
+		from = accounts[0].Hex()
+	}
+	fromAddr, err := parseAddress(from)
+	if err != nil {
+		return "", err
+	}
+	args["from"] = fromAddr
+
+	if tx.To != "" {
+		toAddr, err := parseAddress(tx.To)
+		if err != nil {
+			return "", err
+		}
+		args["to"] = toAddr
+	}
+	if len(tx.Data) > 0 {
+		args["data"] = hexutil.Bytes(tx.Data)
+	}
+	if tx.Nonce > 0 {
+		args["nonce"] = hexutil.Uint64(tx.Nonce)
+	}
+	if tx.Gas > 0 {
+		args["gas"] = hexutil.Uint64(tx.Gas)
+	}
+	if tx.GasPrice != nil {
+		args["gasPrice"] = (*hexutil.Big)(tx.GasPrice)
+	}
+	if tx.MaxFeePerGas != nil {
+		args["maxFeePerGas"] = (*hexutil.Big)(tx.MaxFeePerGas)
+	}
+	if tx.MaxPriorityFeePerGas != nil {
+		args["maxPriorityFeePerGas"] = (*hexutil.Big)(tx.MaxPriorityFeePerGas)
+	}
+
+	var hash common.Hash
+	if err := c.Call(ctx, &hash, "eth_sendTransaction", args); err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+// ListTransactions returns the transactions included in the most recent
+// RecentBlockWindow blocks, newest first
+func (c *Client) ListTransactions(ctx context.Context) ([]*types.Transaction, error) {
+	head, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*types.Transaction, 0)
+	for i := uint64(0); i < RecentBlockWindow && i <= head; i++ {
+		block, err := c.GetBlockByHeight(ctx, head-i)
+		if err != nil {
+			return nil, err
+		}
+		for j := len(block.Transactions) - 1; j >= 0; j-- {
+			txs = append(txs, block.Transactions[j])
+		}
+	}
+	return txs, nil
+}
+
+// SendRawTransaction broadcasts a signed transaction through
+// eth_sendRawTransaction and returns its hash
+func (c *Client) SendRawTransaction(ctx context.Context, signed *gethtypes.Transaction) (string, error) {
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	var hash common.Hash
+	if err := c.Call(ctx, &hash, "eth_sendRawTransaction", hexutil.Bytes(raw)); err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
@@ -0,0 +1,43 @@
+// pkg/client/rpc/transport.go
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// Transport sends JSON-RPC calls to a single endpoint. The client keeps one
+// transport per endpoint and decides which of them a call goes to.
+type Transport interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []BatchElem) error
+	Close()
+}
+
+// BatchElem is one call of a batch request. Result and Error are set when
+// the batch returns.
+type BatchElem = gethrpc.BatchElem
+
+// Subscriber is implemented by transports that can push notifications,
+// such as WebSocket connections
+type Subscriber interface {
+	SupportsSubscriptions() bool
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*gethrpc.ClientSubscription, error)
+}
+
+// Dialer connects a transport to the endpoint at url
+type Dialer func(ctx context.Context, url string) (Transport, error)
+
+// gethDialer returns the default dialer, which uses go-ethereum's client
+// for HTTP(S) and WS(S) endpoints
+func gethDialer(httpClient *http.Client, headers http.Header) Dialer {
+	return func(ctx context.Context, url string) (Transport, error) {
+		opts := []gethrpc.ClientOption{gethrpc.WithHeaders(headers)}
+		if httpClient != nil {
+			opts = append(opts, gethrpc.WithHTTPClient(httpClient))
+		}
+		return gethrpc.DialOptions(ctx, url, opts...)
+	}
+}
@@ -0,0 +1,240 @@
+// pkg/client/rpc/wait.go
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// Wait defaults
+const (
+	DefaultPollInterval = 2 * time.Second
+	DefaultDroppedAfter = 5
+	// maxReplacementScan bounds how many blocks are searched for the
+	// transaction that replaced the one being waited for
+	maxReplacementScan = 64
+)
+
+// WaitOptions controls WaitForReceipt
+type WaitOptions struct {
+	// Confirmations is the number of blocks, including the one holding the
+	// transaction, that must be on top of the chain. Zero means one.
+	Confirmations uint64
+	// PollInterval is used when the endpoint cannot push new heads
+	PollInterval time.Duration
+	// DroppedAfter is the number of consecutive checks in which the node
+	// no longer knows the transaction before it is reported as dropped
+	DroppedAfter int
+}
+
+// WaitForReceipt blocks until the transaction hash has the requested number
+// of confirmations and returns its receipt. A reverted transaction is
+// returned with StatusFailed and no error. Dropped and replaced transactions
+// return a receipt with the matching status together with ErrTxDropped or
+// ErrTxReplaced. Set a deadline on ctx to bound the wait.
+//
+// New heads are received through eth_subscribe on WebSocket endpoints;
+// HTTP endpoints are polled every PollInterval.
+func (c *Client) WaitForReceipt(ctx context.Context, hash string, opts WaitOptions) (*types.Receipt, error) {
+	if _, err := parseHash(hash); err != nil {
+		return nil, err
+	}
+	if opts.Confirmations == 0 {
+		opts.Confirmations = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.DroppedAfter <= 0 {
+		opts.DroppedAfter = DefaultDroppedAfter
+	}
+
+	startHeight, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	heads, stop := c.headTicker(ctx, opts.PollInterval)
+	defer stop()
+
+	var (
+		sent   *types.Transaction
+		misses int
+	)
+	for {
+		receipt, err := c.checkReceipt(ctx, hash, opts.Confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil && receipt.Confirmations >= opts.Confirmations {
+			return receipt, nil
+		}
+
+		if receipt == nil {
+			tx, err := c.GetTransaction(ctx, hash)
+			switch {
+			case err == nil:
+				sent, misses = tx, 0
+			case errors.Is(err, ErrNotFound):
+				misses++
+			default:
+				return nil, err
+			}
+
+			if sent != nil {
+				replaced, err := c.checkReplaced(ctx, sent, startHeight)
+				if err != nil {
+					return nil, err
+				}
+				if replaced != nil {
+					return replaced, ErrTxReplaced
+				}
+			}
+			if misses >= opts.DroppedAfter {
+				return &types.Receipt{TransactionHash: hash, Status: types.StatusDropped}, ErrTxDropped
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return receipt, ctx.Err()
+		case <-heads:
+		}
+	}
+}
+
+// checkReceipt returns the current receipt for hash with its confirmation
+// count, or nil while the transaction is unmined
+func (c *Client) checkReceipt(ctx context.Context, hash string, want uint64) (*types.Receipt, error) {
+	receipt, err := c.GetTransactionReceipt(ctx, hash)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head >= receipt.BlockNumber {
+		receipt.Confirmations = head - receipt.BlockNumber + 1
+	}
+	return receipt, nil
+}
+
+// checkReplaced reports a replaced receipt when the sender's mined nonce has
+// moved past tx without tx itself being mined
+func (c *Client) checkReplaced(ctx context.Context, tx *types.Transaction, startHeight uint64) (*types.Receipt, error) {
+	from, err := parseAddress(tx.From)
+	if err != nil {
+		return nil, nil
+	}
+	mined, err := c.NonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if mined <= tx.Nonce {
+		return nil, nil
+	}
+
+	// The nonce may have been consumed between our receipt check and now
+	if receipt, err := c.checkReceipt(ctx, tx.Hash, 0); err != nil || receipt != nil {
+		return nil, err
+	}
+
+	replaced := &types.Receipt{TransactionHash: tx.Hash, Status: types.StatusReplaced}
+	replaced.ReplacedBy, err = c.findReplacement(ctx, from, tx.Nonce, startHeight)
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+// findReplacement searches recent blocks for the transaction from sender
+// that used nonce
+func (c *Client) findReplacement(ctx context.Context, sender common.Address, nonce uint64, startHeight uint64) (string, error) {
+	head, err := c.BlockNumber(ctx)
+	if err != nil {
+		return "", err
+	}
+	if head > startHeight+maxReplacementScan {
+		startHeight = head - maxReplacementScan
+	}
+
+	for height := head; height+1 > startHeight; height-- {
+		block, err := c.GetBlockByHeight(ctx, height)
+		if err != nil {
+			return "", err
+		}
+		for _, tx := range block.Transactions {
+			if tx.Nonce == nonce && strings.EqualFold(tx.From, sender.Hex()) {
+				return tx.Hash, nil
+			}
+		}
+		if height == 0 {
+			break
+		}
+	}
+	return "", nil
+}
+
+// headTicker returns a channel that fires whenever a new block may be
+// available: on each new head for subscription capable endpoints, and every
+// interval otherwise
+func (c *Client) headTicker(ctx context.Context, interval time.Duration) (<-chan struct{}, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	ticks := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	}
+
+	if c.supportsSubscriptions() {
+		headers := make(chan json.RawMessage, 16)
+		sub, err := c.ethSubscribe(ctx, headers, "newHeads")
+		if err == nil {
+			go func() {
+				defer sub.Unsubscribe()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-sub.Err():
+						// Fall back to polling when the subscription ends
+						pollEvery(ctx, interval, notify)
+						return
+					case <-headers:
+						notify()
+					}
+				}
+			}()
+			return ticks, cancel
+		}
+	}
+
+	go pollEvery(ctx, interval, notify)
+	return ticks, cancel
+}
+
+func pollEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// pkg/client/rpc/wire.go
+package rpc
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// rpcBlock is the JSON shape of an eth_getBlockBy* response with full transactions
+type rpcBlock struct {
+	Hash         common.Hash       `json:"hash"`
+	Number       hexutil.Uint64    `json:"number"`
+	ParentHash   common.Hash       `json:"parentHash"`
+	Timestamp    hexutil.Uint64    `json:"timestamp"`
+	Miner        common.Address    `json:"miner"`
+	GasLimit     hexutil.Uint64    `json:"gasLimit"`
+	GasUsed      hexutil.Uint64    `json:"gasUsed"`
+	BaseFee      *hexutil.Big      `json:"baseFeePerGas"`
+	Size         hexutil.Uint64    `json:"size"`
+	Transactions []*rpcTransaction `json:"transactions"`
+}
+
+// rpcTransaction is the JSON shape of an eth_getTransactionByHash response
+type rpcTransaction struct {
+	Hash                 common.Hash     `json:"hash"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Value                *hexutil.Big    `json:"value"`
+	Input                hexutil.Bytes   `json:"input"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+	Type                 hexutil.Uint64  `json:"type"`
+	BlockHash            *common.Hash    `json:"blockHash"`
+	BlockNumber          *hexutil.Big    `json:"blockNumber"`
+}
+
+// rpcReceipt is the JSON shape of an eth_getTransactionReceipt response
+type rpcReceipt struct {
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	Status            hexutil.Uint64  `json:"status"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              []*rpcLog       `json:"logs"`
+}
+
+// rpcLog is the JSON shape of a log entry in receipts and eth_getLogs
+type rpcLog struct {
+	Address         common.Address `json:"address"`
+	Topics          []common.Hash  `json:"topics"`
+	Data            hexutil.Bytes  `json:"data"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	BlockHash       common.Hash    `json:"blockHash"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	LogIndex        hexutil.Uint   `json:"logIndex"`
+	Removed         bool           `json:"removed"`
+}
+
+// rpcSyncProgress is the JSON shape of a non-false eth_syncing response
+type rpcSyncProgress struct {
+	StartingBlock hexutil.Uint64 `json:"startingBlock"`
+	CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+}
+
+// rpcPeer is the JSON shape of an admin_peers entry
+type rpcPeer struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Enode   string   `json:"enode"`
+	Caps    []string `json:"caps"`
+	Network struct {
+		LocalAddress  string `json:"localAddress"`
+		RemoteAddress string `json:"remoteAddress"`
+	} `json:"network"`
+}
+
+func (b *rpcBlock) toBlock() *types.Block {
+	block := &types.Block{
+		Hash:         b.Hash.Hex(),
+		Height:       uint64(b.Number),
+		PreviousHash: b.ParentHash.Hex(),
+		Timestamp:    int64(b.Timestamp),
+		Miner:        b.Miner.Hex(),
+		GasLimit:     uint64(b.GasLimit),
+		GasUsed:      uint64(b.GasUsed),
+		BaseFee:      bigOrNil(b.BaseFee),
+		Size:         uint64(b.Size),
+		Transactions: make([]*types.Transaction, 0, len(b.Transactions)),
+	}
+	for _, tx := range b.Transactions {
+		t := tx.toTransaction()
+		t.Status = types.StatusConfirmed
+		t.Timestamp = block.Timestamp
+		block.Transactions = append(block.Transactions, t)
+	}
+	return block
+}
+
+func (tx *rpcTransaction) toTransaction() *types.Transaction {
+	t := &types.Transaction{
+		Hash:                 tx.Hash.Hex(),
+		From:                 tx.From.Hex(),
+		Value:                bigOrNil(tx.Value),
+		Data:                 tx.Input,
+		Nonce:                uint64(tx.Nonce),
+		Gas:                  uint64(tx.Gas),
+		GasPrice:             bigOrNil(tx.GasPrice),
+		MaxFeePerGas:         bigOrNil(tx.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigOrNil(tx.MaxPriorityFeePerGas),
+		ChainID:              bigOrNil(tx.ChainID),
+		Type:                 uint8(tx.Type),
+		Status:               types.StatusPending,
+	}
+	if tx.To != nil {
+		t.To = tx.To.Hex()
+	}
+	if tx.BlockHash != nil {
+		t.BlockHash = tx.BlockHash.Hex()
+	}
+	if tx.BlockNumber != nil {
+		t.BlockNumber = tx.BlockNumber.ToInt().Uint64()
+	}
+	return t
+}
+
+func (r *rpcReceipt) toReceipt() *types.Receipt {
+	receipt := &types.Receipt{
+		TransactionHash:   r.TransactionHash.Hex(),
+		Status:            types.StatusConfirmed,
+		BlockHash:         r.BlockHash.Hex(),
+		BlockNumber:       uint64(r.BlockNumber),
+		GasUsed:           uint64(r.GasUsed),
+		EffectiveGasPrice: bigOrNil(r.EffectiveGasPrice),
+		Logs:              make([]*types.Log, 0, len(r.Logs)),
+	}
+	if r.Status == 0 {
+		receipt.Status = types.StatusFailed
+	}
+	if r.ContractAddress != nil {
+		receipt.ContractAddress = r.ContractAddress.Hex()
+	}
+	for _, l := range r.Logs {
+		receipt.Logs = append(receipt.Logs, l.toLog())
+	}
+	return receipt
+}
+
+func (l *rpcLog) toLog() *types.Log {
+	topics := make([]string, 0, len(l.Topics))
+	for _, t := range l.Topics {
+		topics = append(topics, t.Hex())
+	}
+	return &types.Log{
+		Address:         l.Address.Hex(),
+		Topics:          topics,
+		Data:            l.Data,
+		BlockNumber:     uint64(l.BlockNumber),
+		BlockHash:       l.BlockHash.Hex(),
+		TransactionHash: l.TransactionHash.Hex(),
+		LogIndex:        uint(l.LogIndex),
+		Removed:         l.Removed,
+	}
+}
+
+func bigOrNil(v *hexutil.Big) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return v.ToInt()
+}
+
+func (p *rpcPeer) toPeer() *types.Peer {
+	return &types.Peer{
+		ID:            p.ID,
+		Name:          p.Name,
+		Enode:         p.Enode,
+		RemoteAddress: p.Network.RemoteAddress,
+		LocalAddress:  p.Network.LocalAddress,
+		Caps:          p.Caps,
+	}
+}
@@ -0,0 +1,296 @@
+// pkg/contract/args.go
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// ParseArgs converts human-readable arguments into the Go values expected
+// by the ABI encoder. Arrays and tuples are written as JSON, for example
+// '["0x..","0x.."]' or '{"to":"0x..","amount":"5"}'.
+func ParseArgs(inputs abi.Arguments, args []string) ([]interface{}, error) {
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, input := range inputs {
+		v, err := ParseArg(input.Type, args[i])
+		if err != nil {
+			name := input.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("argument %s (%s): %w", name, input.Type, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// ParseArg converts a single human-readable argument of type t
+func ParseArg(t abi.Type, raw string) (interface{}, error) {
+	var v interface{} = raw
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("expected JSON: %w", err)
+		}
+	}
+
+	rv, err := convert(t, v)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+// convert builds a value of t's Go type from a string or a decoded JSON value
+func convert(t abi.Type, v interface{}) (reflect.Value, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		s, err := scalar(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return convertInt(t, s)
+
+	case abi.BoolTy:
+		s, err := scalar(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid bool %q", s)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.AddressTy:
+		s, err := scalar(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("invalid address %q", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected a string, got %v", v)
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.BytesTy:
+		b, err := hexBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy:
+		b, err := hexBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", t.Size, len(b))
+		}
+		arr := reflect.New(t.GetType()).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := v.([]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected an array, got %v", v)
+		}
+		var out reflect.Value
+		if t.T == abi.SliceTy {
+			out = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d elements, got %d", t.Size, len(items))
+			}
+			out = reflect.New(t.GetType()).Elem()
+		}
+		for i, item := range items {
+			elem, err := convert(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		items, err := tupleItems(t, v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t.GetType()).Elem()
+		for i, item := range items {
+			field, err := convert(*t.TupleElems[i], item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s: %w", t.TupleRawNames[i], err)
+			}
+			out.Field(i).Set(field)
+		}
+		return out, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+}
+
+// convertInt parses s into the sized integer or *big.Int used for t,
+// rejecting values that do not fit
+func convertInt(t abi.Type, s string) (reflect.Value, error) {
+	n, err := types.ParseBig(s)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if t.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > t.Size {
+			return reflect.Value{}, fmt.Errorf("%s out of range for uint%d", s, t.Size)
+		}
+	} else {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return reflect.Value{}, fmt.Errorf("%s out of range for int%d", s, t.Size)
+		}
+	}
+
+	typ := t.GetType()
+	if typ == reflect.TypeOf((*big.Int)(nil)) {
+		return reflect.ValueOf(n), nil
+	}
+	out := reflect.New(typ).Elem()
+	if t.T == abi.UintTy {
+		out.SetUint(n.Uint64())
+	} else {
+		out.SetInt(n.Int64())
+	}
+	return out, nil
+}
+
+// tupleItems returns the fields of a tuple given as a JSON array in field
+// order or as a JSON object keyed by field name
+func tupleItems(t abi.Type, v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) != len(t.TupleElems) {
+			return nil, fmt.Errorf("expected %d fields, got %d", len(t.TupleElems), len(v))
+		}
+		return v, nil
+	case map[string]interface{}:
+		items := make([]interface{}, len(t.TupleRawNames))
+		for i, name := range t.TupleRawNames {
+			item, ok := v[name]
+			if !ok {
+				return nil, fmt.Errorf("missing field %s", name)
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("expected an array or object, got %v", v)
+}
+
+// scalar returns the text of a command line or JSON scalar
+func scalar(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("expected a value, got %v", v)
+}
+
+func hexBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex string, got %v", v)
+	}
+	b, err := hexutil.Decode(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex %q: %w", s, err)
+	}
+	return b, nil
+}
+
+// formatArgs pairs decoded values with their ABI arguments
+func formatArgs(args abi.Arguments, values []interface{}) []*types.Argument {
+	out := make([]*types.Argument, len(args))
+	for i, arg := range args {
+		out[i] = &types.Argument{
+			Name:  arg.Name,
+			Type:  arg.Type.String(),
+			Value: formatValue(arg.Type, values[i]),
+		}
+	}
+	return out
+}
+
+// formatValue converts a decoded ABI value into the representation
+// documented on types.Argument
+func formatValue(t abi.Type, v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if n, ok := v.(*big.Int); ok {
+			return n.String()
+		}
+		if t.T == abi.UintTy {
+			return strconv.FormatUint(rv.Uint(), 10)
+		}
+		return strconv.FormatInt(rv.Int(), 10)
+
+	case abi.AddressTy:
+		return v.(common.Address).Hex()
+
+	case abi.BytesTy:
+		return hexutil.Encode(v.([]byte))
+
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = formatValue(*t.Elem, rv.Index(i).Interface())
+		}
+		return items
+
+	case abi.TupleTy:
+		fields := make([]*types.Argument, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			fields[i] = &types.Argument{
+				Name:  t.TupleRawNames[i],
+				Type:  elem.String(),
+				Value: formatValue(*elem, rv.Field(i).Interface()),
+			}
+		}
+		return fields
+	}
+	return v
+}
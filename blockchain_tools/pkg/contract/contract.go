@@ -0,0 +1,295 @@
+// Package contract encodes calls to Ethereum smart contracts and decodes
+// their return values, events and revert data using a JSON ABI.
+//
+// Arguments are given as human-readable strings, as typed on a command
+// line: decimal or 0x-prefixed integers, hex addresses and bytes, true or
+// false, and JSON for arrays and tuples. Decoded values are returned as
+// types.Argument so they can be printed by any formatter.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+var (
+	// ErrNoBytecode is returned when deploying a contract whose ABI file
+	// did not include creation bytecode
+	ErrNoBytecode = errors.New("no contract bytecode")
+	// ErrUnknownMethod is returned for a method the ABI does not define
+	ErrUnknownMethod = errors.New("unknown method")
+	// ErrUnknownEvent is returned for a log no event in the ABI matches
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
+// Contract is a contract ABI together with its creation bytecode, when known
+type Contract struct {
+	ABI      abi.ABI
+	Bytecode []byte
+}
+
+// Load reads a contract from path. See Parse for the accepted formats.
+func Load(path string) (*Contract, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ABI file: %w", err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse reads a contract from either a plain JSON ABI array or a compiler
+// artifact with "abi" and "bytecode" fields, as written by Hardhat, Truffle
+// and Foundry
+func Parse(data []byte) (*Contract, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		parsed, err := abi.JSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ABI: %w", err)
+		}
+		return &Contract{ABI: parsed}, nil
+	}
+
+	var artifact struct {
+		ABI      json.RawMessage `json:"abi"`
+		Bytecode json.RawMessage `json:"bytecode"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+	if len(artifact.ABI) == 0 {
+		return nil, fmt.Errorf("invalid ABI: no \"abi\" field")
+	}
+	parsed, err := abi.JSON(bytes.NewReader(artifact.ABI))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+
+	c := &Contract{ABI: parsed}
+	if len(artifact.Bytecode) > 0 {
+		if c.Bytecode, err = parseBytecode(artifact.Bytecode); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// parseBytecode accepts the bytecode as a hex string (Hardhat, Truffle) or
+// as an object with the hex string under "object" (Foundry)
+func parseBytecode(raw json.RawMessage) ([]byte, error) {
+	var code string
+	if err := json.Unmarshal(raw, &code); err != nil {
+		var obj struct {
+			Object string `json:"object"`
+		}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, fmt.Errorf("invalid bytecode: %w", err)
+		}
+		code = obj.Object
+	}
+	return ParseBytecode(code)
+}
+
+// ParseBytecode decodes hex creation bytecode, with or without 0x prefix
+func ParseBytecode(code string) ([]byte, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || code == "0x" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(code, "0x") && !strings.HasPrefix(code, "0X") {
+		code = "0x" + code
+	}
+	b, err := hexutil.Decode(code)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bytecode: %w", err)
+	}
+	return b, nil
+}
+
+// Method looks up a method by name, or by signature such as
+// "transfer(address,uint256)" to pick one of several overloads
+func (c *Contract) Method(name string) (abi.Method, error) {
+	if m, ok := c.ABI.Methods[name]; ok {
+		return m, nil
+	}
+	for _, m := range c.ABI.Methods {
+		if m.Sig == name {
+			return m, nil
+		}
+	}
+	return abi.Method{}, fmt.Errorf("%w %q", ErrUnknownMethod, name)
+}
+
+// Pack encodes a call to method with the given human-readable arguments
+func (c *Contract) Pack(method string, args []string) ([]byte, error) {
+	m, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ParseArgs(m.Inputs, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.Sig, err)
+	}
+	return c.ABI.Pack(m.Name, values...)
+}
+
+// PackDeploy returns the contract's creation bytecode followed by the
+// encoded constructor arguments
+func (c *Contract) PackDeploy(args []string) ([]byte, error) {
+	if len(c.Bytecode) == 0 {
+		return nil, ErrNoBytecode
+	}
+	values, err := ParseArgs(c.ABI.Constructor.Inputs, args)
+	if err != nil {
+		return nil, fmt.Errorf("constructor: %w", err)
+	}
+	packed, err := c.ABI.Pack("", values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), c.Bytecode...), packed...), nil
+}
+
+// Unpack decodes the data returned by a call to method
+func (c *Contract) Unpack(method string, data []byte) ([]*types.Argument, error) {
+	m, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 && len(m.Outputs) > 0 {
+		return nil, fmt.Errorf("%s returned no data; is there a contract at this address?", m.Sig)
+	}
+	values, err := m.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", m.Sig, err)
+	}
+	return formatArgs(m.Outputs, values), nil
+}
+
+// DecodeLog decodes a log emitted by the contract. Indexed arguments of
+// dynamic types are only stored as a hash and are returned as that hash.
+func (c *Contract) DecodeLog(l *types.Log) (*types.DecodedEvent, error) {
+	if len(l.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	event, err := c.ABI.EventByID(common.HexToHash(l.Topics[0]))
+	if err != nil {
+		return nil, ErrUnknownEvent
+	}
+
+	data, err := event.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", event.Sig, err)
+	}
+
+	args := make([]*types.Argument, 0, len(event.Inputs))
+	topics := l.Topics[1:]
+	for _, input := range event.Inputs {
+		arg := &types.Argument{Name: input.Name, Type: input.Type.String()}
+		if !input.Indexed {
+			arg.Value = formatValue(input.Type, data[0])
+			data = data[1:]
+			args = append(args, arg)
+			continue
+		}
+
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("failed to decode %s: missing topic for %s", event.Sig, input.Name)
+		}
+		topic := common.HexToHash(topics[0])
+		topics = topics[1:]
+		if isDynamic(input.Type) {
+			arg.Value = topic.Hex()
+		} else {
+			values, err := abi.Arguments{{Type: input.Type}}.Unpack(topic.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", event.Sig, err)
+			}
+			arg.Value = formatValue(input.Type, values[0])
+		}
+		args = append(args, arg)
+	}
+
+	return &types.DecodedEvent{Name: event.Name, Signature: event.Sig, Args: args}, nil
+}
+
+// DecodeLogs sets Decoded on every log the contract's ABI can decode.
+// Logs of other contracts and events are left untouched.
+func (c *Contract) DecodeLogs(logs []*types.Log) {
+	for _, l := range logs {
+		if decoded, err := c.DecodeLog(l); err == nil {
+			l.Decoded = decoded
+		}
+	}
+}
+
+// DecodeRevert describes the data returned by a reverted call without an
+// ABI: the reason of Error(string) or the meaning of a Panic(uint256) code.
+// Other data is returned as hex.
+func DecodeRevert(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		if bytes.HasPrefix(data, panicSelector) {
+			return "panic: " + reason
+		}
+		return reason
+	}
+	return hexutil.Encode(data)
+}
+
+// DecodeRevert describes the data returned by a reverted call like the
+// package-level DecodeRevert, and also decodes the custom errors of the ABI
+// with their arguments. c may be nil when no ABI is known.
+func (c *Contract) DecodeRevert(data []byte) string {
+	if _, err := abi.UnpackRevert(data); err != nil && c != nil && len(data) >= 4 {
+		var id [4]byte
+		copy(id[:], data)
+		if e, err := c.ABI.ErrorByID(id); err == nil {
+			if values, err := e.Inputs.Unpack(data[4:]); err == nil {
+				return e.Name + types.FormatArgs(formatArgs(e.Inputs, values))
+			}
+		}
+	}
+	return DecodeRevert(data)
+}
+
+// DecodeCalls walks a call tree and sets Method on the frames calling a
+// method in the ABI and Revert on the frames that reverted with data. c
+// may be nil, in which case only standard revert reasons are decoded.
+func (c *Contract) DecodeCalls(frame *types.CallFrame) {
+	if c != nil && len(frame.Input) >= 4 {
+		if method, err := c.ABI.MethodById(frame.Input[:4]); err == nil {
+			frame.Method = method.Sig
+		}
+	}
+	if frame.Error != "" && len(frame.Output) > 0 {
+		frame.Revert = c.DecodeRevert(frame.Output)
+	}
+	for _, call := range frame.Calls {
+		c.DecodeCalls(call)
+	}
+}
+
+// panicSelector is the selector of the Panic(uint256) error
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// isDynamic reports whether t is hashed rather than stored in a topic
+func isDynamic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
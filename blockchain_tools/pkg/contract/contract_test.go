@@ -0,0 +1,58 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Error(string) with the reason "not owner"
+const revertNotOwner = "0x08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000009" +
+	"6e6f74206f776e65720000000000000000000000000000000000000000000000"
+
+// Panic(uint256) with the code of a division by zero
+const revertDivision = "0x4e487b71" +
+	"0000000000000000000000000000000000000000000000000000000000000012"
+
+// Unauthorized(address) with the zero address
+const revertUnauthorized = "0x8e4a23d6" +
+	"0000000000000000000000000000000000000000000000000000000000000000"
+
+const unauthorizedABI = `[{"type":"error","name":"Unauthorized","inputs":[{"name":"account","type":"address"}]}]`
+
+func TestDecodeRevert(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{revertNotOwner, "not owner"},
+		{revertDivision, "panic: division or modulo by zero"},
+		{revertUnauthorized, revertUnauthorized},
+		{"0x1234", "0x1234"},
+	}
+	for _, tt := range tests {
+		if got := DecodeRevert(hexutil.MustDecode(tt.data)); got != tt.want {
+			t.Errorf("DecodeRevert(%s) = %q, want %q", tt.data, got, tt.want)
+		}
+		var none *Contract
+		if got := none.DecodeRevert(hexutil.MustDecode(tt.data)); got != tt.want {
+			t.Errorf("nil Contract DecodeRevert(%s) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestContractDecodeRevert(t *testing.T) {
+	c, err := Parse([]byte(unauthorizedABI))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := c.DecodeRevert(hexutil.MustDecode(revertNotOwner)); got != "not owner" {
+		t.Errorf("DecodeRevert(Error) = %q, want %q", got, "not owner")
+	}
+	got := c.DecodeRevert(hexutil.MustDecode(revertUnauthorized))
+	if want := "Unauthorized(account=0x0000000000000000000000000000000000000000)"; got != want {
+		t.Errorf("DecodeRevert(Unauthorized) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+// pkg/contract/event.go
+package contract
+
+import "github.com/ethereum/go-ethereum/accounts/abi"
+
+// FromEvents builds a contract whose ABI holds only the given events. See
+// AddEvents for the signature format.
+func FromEvents(signatures ...string) (*Contract, error) {
+	c := &Contract{ABI: abi.ABI{
+		Methods: make(map[string]abi.Method),
+		Events:  make(map[string]abi.Event),
+		Errors:  make(map[string]abi.Error),
+	}}
+	if err := c.AddEvents(signatures...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddEvents adds events given as Solidity signatures, for example
+// "Transfer(address indexed from, address indexed to, uint256 value)".
+// Parameter names are optional; tuple parameters are not supported.
+func (c *Contract) AddEvents(signatures ...string) error {
+	if c.ABI.Events == nil {
+		c.ABI.Events = make(map[string]abi.Event)
+	}
+	for _, sig := range signatures {
+		event, err := parseEvent(sig)
+		if err != nil {
+			return err
+		}
+		c.ABI.Events[event.Name] = event
+	}
+	return nil
+}
+
+// EventIDs returns the topic identifying each event in the ABI
+func (c *Contract) EventIDs() []string {
+	ids := make([]string, 0, len(c.ABI.Events))
+	for _, event := range c.ABI.Events {
+		if !event.Anonymous {
+			ids = append(ids, event.ID.Hex())
+		}
+	}
+	return ids
+}
+
+func parseEvent(sig string) (abi.Event, error) {
+	name, inputs, err := parseSignature("event", sig, true)
+	if err != nil {
+		return abi.Event{}, err
+	}
+	return abi.NewEvent(name, name, false, inputs), nil
+}
@@ -0,0 +1,88 @@
+// pkg/contract/signature.go
+package contract
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// FromErrors builds a contract whose ABI holds only the given custom
+// errors. See AddErrors for the signature format.
+func FromErrors(signatures ...string) (*Contract, error) {
+	c := &Contract{ABI: abi.ABI{
+		Methods: make(map[string]abi.Method),
+		Events:  make(map[string]abi.Event),
+		Errors:  make(map[string]abi.Error),
+	}}
+	if err := c.AddErrors(signatures...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddErrors adds custom errors given as Solidity signatures, for example
+// "InsufficientBalance(uint256 available, uint256 required)". Parameter
+// names are optional; tuple parameters are not supported.
+func (c *Contract) AddErrors(signatures ...string) error {
+	if c.ABI.Errors == nil {
+		c.ABI.Errors = make(map[string]abi.Error)
+	}
+	for _, sig := range signatures {
+		name, inputs, err := parseSignature("error", sig, false)
+		if err != nil {
+			return err
+		}
+		c.ABI.Errors[name] = abi.NewError(name, inputs)
+	}
+	return nil
+}
+
+// parseSignature splits a signature such as "Name(uint256 a, address b)",
+// optionally prefixed with kind, into its name and parameters. Parameters
+// may be marked indexed when indexed is set.
+func parseSignature(kind, sig string, indexed bool) (string, abi.Arguments, error) {
+	sig = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(sig), kind+" "))
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return "", nil, fmt.Errorf("invalid %s signature %q", kind, sig)
+	}
+	name := strings.TrimSpace(sig[:open])
+	params := strings.TrimSpace(sig[open+1 : len(sig)-1])
+	if strings.ContainsAny(params, "()") {
+		return "", nil, fmt.Errorf("invalid %s signature %q: tuple parameters are not supported", kind, sig)
+	}
+
+	var inputs abi.Arguments
+	if params != "" {
+		for i, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			if len(fields) == 0 {
+				return "", nil, fmt.Errorf("invalid %s signature %q: empty parameter", kind, sig)
+			}
+
+			typ, err := abi.NewType(fields[0], "", nil)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid %s signature %q: %w", kind, sig, err)
+			}
+			arg := abi.Argument{Type: typ}
+			fields = fields[1:]
+			if indexed && len(fields) > 0 && fields[0] == "indexed" {
+				arg.Indexed = true
+				fields = fields[1:]
+			}
+			switch len(fields) {
+			case 0:
+				arg.Name = fmt.Sprintf("arg%d", i)
+			case 1:
+				arg.Name = fields[0]
+			default:
+				return "", nil, fmt.Errorf("invalid %s signature %q: bad parameter %q", kind, sig, param)
+			}
+			inputs = append(inputs, arg)
+		}
+	}
+
+	return name, inputs, nil
+}
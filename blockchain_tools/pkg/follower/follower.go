@@ -0,0 +1,237 @@
+// Package follower tracks the canonical chain and reports reorgs.
+//
+// A Follower keeps a window of the most recently applied blocks. On every
+// step it checks that the newest of them is still canonical and that each
+// new block's parent hash matches the block before it. When they don't, it
+// walks back to the common ancestor, reporting every block it drops as
+// Reverted, before reporting the new chain as Applied. Consumers undo the
+// state derived from reverted blocks in their handler.
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// DefaultWindow is the number of recent blocks kept to detect reorgs
+const DefaultWindow = 128
+
+// ErrReorgTooDeep is returned when the common ancestor is older than every
+// block in the window
+var ErrReorgTooDeep = errors.New("reorg deeper than the block window")
+
+// Event types
+const (
+	Applied  = "applied"
+	Reverted = "reverted"
+)
+
+// Event reports a block joining or leaving the followed chain. Reverted
+// events arrive newest block first, before the Applied events of the
+// replacing chain.
+type Event struct {
+	Type  string
+	Block *types.Block
+}
+
+// Handler processes a single event. When it fails, the follower stops and
+// delivers the same event again on the next step.
+type Handler func(ctx context.Context, event Event) error
+
+// Source provides the blocks of the chain. *rpc.Client implements it.
+type Source interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	GetBlockByHeight(ctx context.Context, height uint64) (*types.Block, error)
+}
+
+// Follower follows the canonical chain of a Source
+type Follower struct {
+	source  Source
+	size    int
+	start   *uint64
+	window  []*types.Block // applied blocks, oldest first, consecutive heights
+	next    uint64
+	started bool
+	// anchor is the head block taken as starting point, which is kept to
+	// check the parent of the next block but was never reported
+	anchor *types.Block
+	// partial is set once the window no longer holds every applied block,
+	// so emptying it means the reorg went deeper than we can follow
+	partial bool
+}
+
+// Option configures a Follower
+type Option func(*Follower)
+
+// WithStartHeight sets the first block reported as Applied. By default the
+// current head is taken as the starting point and only later blocks are
+// reported.
+func WithStartHeight(height uint64) Option {
+	return func(f *Follower) {
+		f.start = &height
+	}
+}
+
+// WithWindow sets how many recent blocks are kept to detect reorgs
+func WithWindow(size int) Option {
+	return func(f *Follower) {
+		if size > 0 {
+			f.size = size
+		}
+	}
+}
+
+// WithHistory resumes following after blocks, consecutive and oldest first,
+// that a previous run already applied. Blocks beyond the window size are
+// dropped. If fewer blocks than the window holds are given, they are taken to
+// be all the applied blocks.
+func WithHistory(blocks []*types.Block) Option {
+	return func(f *Follower) {
+		f.window = append([]*types.Block(nil), blocks...)
+	}
+}
+
+// New creates a follower reading from source
+func New(source Source, opts ...Option) *Follower {
+	f := &Follower{
+		source: source,
+		size:   DefaultWindow,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if len(f.window) > f.size {
+		f.window = f.window[len(f.window)-f.size:]
+		f.partial = true
+	} else if len(f.window) == f.size {
+		f.partial = true
+	}
+	if tip := f.Tip(); tip != nil {
+		f.next = tip.Height + 1
+		f.started = true
+	} else if f.start != nil {
+		f.next = *f.start
+		f.started = true
+	}
+	return f
+}
+
+// Tip returns the newest applied block, or nil before the first one
+func (f *Follower) Tip() *types.Block {
+	if len(f.window) == 0 {
+		return nil
+	}
+	return f.window[len(f.window)-1]
+}
+
+// Step reverts blocks that left the canonical chain and applies new blocks
+// up to the current head, calling handle for each change in order
+func (f *Follower) Step(ctx context.Context, handle Handler) error {
+	head, err := f.source.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !f.started {
+		block, err := f.source.GetBlockByHeight(ctx, head)
+		if err != nil {
+			return err
+		}
+		f.push(block)
+		f.anchor = block
+		f.next, f.started = head+1, true
+		return nil
+	}
+
+	// Undo blocks the chain no longer contains, including blocks above a
+	// head that moved backwards
+	for tip := f.Tip(); tip != nil; tip = f.Tip() {
+		if tip.Height <= head {
+			block, err := f.source.GetBlockByHeight(ctx, tip.Height)
+			if err != nil {
+				return err
+			}
+			if block.Hash == tip.Hash {
+				break
+			}
+		}
+		if err := f.revert(ctx, handle); err != nil {
+			return err
+		}
+	}
+
+	for f.next <= head {
+		block, err := f.source.GetBlockByHeight(ctx, f.next)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", f.next, err)
+		}
+
+		// A parent mismatch means the chain changed while we were reading;
+		// walk back one block and compare again
+		if tip := f.Tip(); tip != nil && block.PreviousHash != tip.Hash {
+			if err := f.revert(ctx, handle); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := handle(ctx, Event{Type: Applied, Block: block}); err != nil {
+			return err
+		}
+		f.push(block)
+		f.next = block.Height + 1
+	}
+	return nil
+}
+
+// Run calls Step every interval until ctx is done or a step fails
+func (f *Follower) Run(ctx context.Context, interval time.Duration, handle Handler) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Step(ctx, handle); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// revert reports the tip as reverted and drops it from the window. The
+// anchor block was never applied, so it is dropped without an event; the
+// block replacing it is reported as Applied.
+func (f *Follower) revert(ctx context.Context, handle Handler) error {
+	tip := f.Tip()
+	if tip == nil || (len(f.window) == 1 && f.partial) {
+		return ErrReorgTooDeep
+	}
+	if tip == f.anchor {
+		f.anchor = nil
+	} else if err := handle(ctx, Event{Type: Reverted, Block: tip}); err != nil {
+		return err
+	}
+	f.window = f.window[:len(f.window)-1]
+	f.next = tip.Height
+	return nil
+}
+
+func (f *Follower) push(block *types.Block) {
+	f.window = append(f.window, block)
+	if len(f.window) > f.size {
+		f.window = f.window[1:]
+		f.partial = true
+	}
+}
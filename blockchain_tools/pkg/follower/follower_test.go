@@ -0,0 +1,228 @@
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// fakeChain is a scripted chain. Every block hash names the fork that
+// produced it, so events are easy to read in failures.
+type fakeChain struct {
+	blocks []*types.Block
+	// onGet runs before a block is returned, to change the chain while a
+	// step reads it
+	onGet func(height uint64)
+}
+
+// newFakeChain returns a chain of blocks 0 to head from fork "a"
+func newFakeChain(head uint64) *fakeChain {
+	c := &fakeChain{}
+	c.extend("a", int(head)+1)
+	return c
+}
+
+func (c *fakeChain) head() uint64 {
+	return uint64(len(c.blocks) - 1)
+}
+
+// extend mines n blocks of fork on top of the head
+func (c *fakeChain) extend(fork string, n int) {
+	for i := 0; i < n; i++ {
+		height := uint64(len(c.blocks))
+		block := &types.Block{Height: height, Hash: fmt.Sprintf("%s%d", fork, height)}
+		if height > 0 {
+			block.PreviousHash = c.blocks[height-1].Hash
+		}
+		c.blocks = append(c.blocks, block)
+	}
+}
+
+// reorg replaces the blocks above ancestor with n blocks of fork
+func (c *fakeChain) reorg(ancestor uint64, fork string, n int) {
+	c.blocks = c.blocks[:ancestor+1]
+	c.extend(fork, n)
+}
+
+func (c *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	return c.head(), nil
+}
+
+func (c *fakeChain) GetBlockByHeight(_ context.Context, height uint64) (*types.Block, error) {
+	if c.onGet != nil {
+		c.onGet(height)
+	}
+	if height > c.head() {
+		return nil, errors.New("not found")
+	}
+	return c.blocks[height], nil
+}
+
+// recorder collects events as "applied a5" or "reverted b6"
+type recorder struct {
+	events []string
+	// fail makes the handler fail once on the event with this text
+	fail string
+}
+
+func (r *recorder) handle(_ context.Context, e Event) error {
+	text := e.Type + " " + e.Block.Hash
+	if text == r.fail {
+		r.fail = ""
+		return errors.New("handler failed")
+	}
+	r.events = append(r.events, text)
+	return nil
+}
+
+func (r *recorder) take() []string {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func step(t *testing.T, f *Follower, r *recorder, want ...string) {
+	t.Helper()
+	if err := f.Step(context.Background(), r.handle); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if got := r.take(); !reflect.DeepEqual(got, want) && !(len(got) == 0 && len(want) == 0) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+func TestFollowFromHead(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{}
+
+	// The head is the starting point and is not reported
+	step(t, f, r)
+	step(t, f, r)
+	chain.extend("a", 2)
+	step(t, f, r, "applied a6", "applied a7")
+	if tip := f.Tip(); tip.Hash != "a7" {
+		t.Errorf("Tip() = %s, want a7", tip.Hash)
+	}
+}
+
+func TestFollowFromStartHeight(t *testing.T) {
+	chain := newFakeChain(2)
+	f := New(chain, WithStartHeight(0))
+	r := &recorder{}
+	step(t, f, r, "applied a0", "applied a1", "applied a2")
+}
+
+func TestReorg(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{}
+	step(t, f, r)
+	chain.extend("a", 3)
+	step(t, f, r, "applied a6", "applied a7", "applied a8")
+
+	// b replaces blocks 7 and 8 and is one block longer
+	chain.reorg(6, "b", 3)
+	step(t, f, r, "reverted a8", "reverted a7", "applied b7", "applied b8", "applied b9")
+}
+
+func TestReorgToShorterChain(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain, WithStartHeight(0))
+	r := &recorder{}
+	step(t, f, r, "applied a0", "applied a1", "applied a2", "applied a3", "applied a4", "applied a5")
+
+	// The head moves back to a block b mined at height 4
+	chain.reorg(3, "b", 1)
+	step(t, f, r, "reverted a5", "reverted a4", "applied b4")
+}
+
+func TestReorgOfStartingHead(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{}
+	step(t, f, r)
+	chain.extend("a", 1)
+	step(t, f, r, "applied a6")
+
+	// The starting head a5 was never applied, so it must not be reverted
+	chain.reorg(4, "b", 3)
+	step(t, f, r, "reverted a6", "applied b5", "applied b6", "applied b7")
+}
+
+func TestReorgOfStartingHeadBeforeNewBlocks(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{}
+	step(t, f, r)
+
+	chain.reorg(4, "b", 2)
+	step(t, f, r, "applied b5", "applied b6")
+}
+
+func TestReorgWhileReading(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{}
+	step(t, f, r)
+	chain.extend("a", 1)
+	step(t, f, r, "applied a6")
+	chain.extend("a", 2)
+
+	// After block a7 is read, the chain switches to fork b from block 7 on,
+	// so b8 does not build on the a7 just applied
+	chain.onGet = func(height uint64) {
+		if height == 8 {
+			chain.onGet = nil
+			chain.reorg(6, "b", 2)
+		}
+	}
+	step(t, f, r, "applied a7", "reverted a7", "applied b7", "applied b8")
+}
+
+func TestReorgTooDeep(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain, WithStartHeight(0), WithWindow(3))
+	r := &recorder{}
+	step(t, f, r, "applied a0", "applied a1", "applied a2", "applied a3", "applied a4", "applied a5")
+
+	chain.reorg(1, "b", 5)
+	err := f.Step(context.Background(), r.handle)
+	if !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("Step error = %v, want ErrReorgTooDeep", err)
+	}
+	if got, want := r.take(), []string{"reverted a5", "reverted a4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestResumeFromHistory(t *testing.T) {
+	chain := newFakeChain(5)
+	history := append([]*types.Block(nil), chain.blocks[3:]...)
+	chain.reorg(3, "b", 3)
+
+	f := New(chain, WithHistory(history))
+	r := &recorder{}
+	// Blocks of the history were applied, so they are reverted
+	step(t, f, r, "reverted a5", "reverted a4", "applied b4", "applied b5", "applied b6")
+}
+
+func TestHandlerFailureRedelivers(t *testing.T) {
+	chain := newFakeChain(5)
+	f := New(chain)
+	r := &recorder{fail: "applied a7"}
+	step(t, f, r)
+	chain.extend("a", 2)
+
+	if err := f.Step(context.Background(), r.handle); err == nil {
+		t.Fatal("Step succeeded although the handler failed")
+	}
+	if got, want := r.take(), []string{"applied a6"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	step(t, f, r, "applied a7")
+}
@@ -0,0 +1,69 @@
+// Package hdwallet generates BIP-39 mnemonics and derives Ethereum keys from
+// them along BIP-32/BIP-44 paths, as hardware and browser wallets do.
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidMnemonic is returned for a mnemonic with unknown words or a bad
+// checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// DefaultPath is the BIP-44 path of the first Ethereum account,
+// m/44'/60'/0'/0/0. Further accounts increment the last component.
+var DefaultPath = accounts.DefaultBaseDerivationPath
+
+// NewMnemonic generates a random English mnemonic of 12, 15, 18, 21 or 24
+// words
+func NewMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("invalid mnemonic length %d: expected 12, 15, 18, 21 or 24 words", words)
+	}
+	entropy, err := bip39.NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// Seed checks mnemonic and returns the BIP-39 seed it encodes with the
+// optional passphrase. Words may be separated by any whitespace.
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	// Recovering the entropy verifies the words and the checksum
+	if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	return bip39.NewSeed(mnemonic, passphrase), nil
+}
+
+// ParsePath parses an absolute derivation path such as m/44'/60'/0'/0/1
+func ParsePath(path string) (accounts.DerivationPath, error) {
+	if !strings.HasPrefix(path, "m/") {
+		return nil, fmt.Errorf("invalid derivation path %q: must start with m/", path)
+	}
+	return accounts.ParseDerivationPath(path)
+}
+
+// Derive returns the private key at path below the master key of seed
+func Derive(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive master key: %w", err)
+	}
+	for _, index := range path {
+		if key, err = key.NewChildKey(index); err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+	}
+	return crypto.ToECDSA(key.Key)
+}
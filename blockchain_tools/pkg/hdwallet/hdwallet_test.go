@@ -0,0 +1,106 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testMnemonic is the all-zero entropy mnemonic used by the BIP-39 and
+// wallet test vectors
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestSeed(t *testing.T) {
+	// From the BIP-39 reference vectors, which all use the passphrase TREZOR
+	seed, err := Seed(testMnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	if got := hex.EncodeToString(seed); got != want {
+		t.Errorf("Seed = %s, want %s", got, want)
+	}
+
+	// Case and whitespace do not matter
+	messy, err := Seed("  "+strings.ToUpper(strings.ReplaceAll(testMnemonic, " ", "\n\t ")), "TREZOR")
+	if err != nil || hex.EncodeToString(messy) != want {
+		t.Errorf("Seed of a reformatted mnemonic = %x, %v", messy, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		// Bad checksum
+		strings.Repeat("abandon ", 12),
+		// Unknown word
+		strings.Replace(testMnemonic, "about", "aboot", 1),
+		// Too short
+		"abandon abandon about",
+	} {
+		if _, err := Seed(bad, ""); !errors.Is(err, ErrInvalidMnemonic) {
+			t.Errorf("Seed(%q) error = %v, want ErrInvalidMnemonic", bad, err)
+		}
+	}
+}
+
+func TestDerive(t *testing.T) {
+	seed, err := Seed(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"m/44'/60'/0'/0/0", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"},
+		{"m/44'/60'/0'/0/1", "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"},
+	}
+	for _, tt := range tests {
+		path, err := ParsePath(tt.path)
+		if err != nil {
+			t.Fatalf("ParsePath(%s): %v", tt.path, err)
+		}
+		key, err := Derive(seed, path)
+		if err != nil {
+			t.Fatalf("Derive(%s): %v", tt.path, err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != tt.want {
+			t.Errorf("Derive(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	if DefaultPath.String() != "m/44'/60'/0'/0/0" {
+		t.Errorf("DefaultPath = %s", DefaultPath)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	for _, bad := range []string{"44'/60'/0'/0/0", "m/44'/x", ""} {
+		if _, err := ParsePath(bad); err == nil {
+			t.Errorf("ParsePath(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestNewMnemonic(t *testing.T) {
+	for _, words := range []int{12, 24} {
+		mnemonic, err := NewMnemonic(words)
+		if err != nil {
+			t.Fatalf("NewMnemonic(%d): %v", words, err)
+		}
+		if n := len(strings.Fields(mnemonic)); n != words {
+			t.Errorf("NewMnemonic(%d) has %d words", words, n)
+		}
+		if _, err := Seed(mnemonic, ""); err != nil {
+			t.Errorf("generated mnemonic is invalid: %v", err)
+		}
+	}
+	for _, words := range []int{0, 11, 13, 27} {
+		if _, err := NewMnemonic(words); err == nil {
+			t.Errorf("NewMnemonic(%d) succeeded", words)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package indexer follows the chain and stores blocks, transactions,
+// receipts and logs in an embedded SQLite database.
+//
+// A standard node cannot list transactions or look them up by address. The
+// Indexer copies every block from a start height into a Store, which then
+// answers those queries for the CLI and the API server. Blocks are read
+// through a pkg/follower Follower, and blocks it reports as reverted by a
+// reorg are rolled back before the replacing chain is indexed.
+package indexer
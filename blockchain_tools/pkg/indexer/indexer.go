@@ -0,0 +1,137 @@
+// pkg/indexer/indexer.go
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/layla-lili/blockchain_tools/pkg/client/rpc"
+	"github.com/layla-lili/blockchain_tools/pkg/follower"
+	"github.com/layla-lili/blockchain_tools/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// tracer records a span for every round of Run, which the RPC calls and
+// log lines of the round belong to
+var tracer = otel.Tracer("github.com/layla-lili/blockchain_tools/pkg/indexer")
+
+// Indexer copies blocks from the node into a Store
+type Indexer struct {
+	client       *rpc.Client
+	store        *Store
+	startHeight  uint64
+	pollInterval time.Duration
+	logger       logger.Logger
+	follower     *follower.Follower
+}
+
+// Option configures an Indexer
+type Option func(*Indexer)
+
+// WithStartHeight sets the first block indexed into an empty store
+func WithStartHeight(height uint64) Option {
+	return func(ix *Indexer) {
+		ix.startHeight = height
+	}
+}
+
+// WithPollInterval sets how long Run waits for new blocks once the index
+// has caught up with the chain
+func WithPollInterval(d time.Duration) Option {
+	return func(ix *Indexer) {
+		ix.pollInterval = d
+	}
+}
+
+// WithLogger sets the logger used to report progress and reorgs
+func WithLogger(l logger.Logger) Option {
+	return func(ix *Indexer) {
+		ix.logger = l
+	}
+}
+
+// New creates an indexer that reads from client and writes to store
+func New(client *rpc.Client, store *Store, opts ...Option) *Indexer {
+	ix := &Indexer{
+		client:       client,
+		store:        store,
+		pollInterval: rpc.DefaultPollInterval,
+		logger:       logger.Nop,
+	}
+	for _, opt := range opts {
+		opt(ix)
+	}
+	return ix
+}
+
+// Run indexes new blocks until ctx is done. Failures are logged and
+// retried after the poll interval.
+func (ix *Indexer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(ix.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		ix.round(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// round runs Sync in a span of its own and logs its failure
+func (ix *Indexer) round(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "indexer.Sync")
+	defer span.End()
+
+	if err := ix.Sync(ctx); err != nil && ctx.Err() == nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.ErrorContext(ctx, ix.logger, "Indexing failed", "error", err)
+	}
+}
+
+// Sync indexes every block up to the current head of the chain. Blocks
+// that a reorg removed from the chain are rolled back first.
+func (ix *Indexer) Sync(ctx context.Context) error {
+	if ix.follower == nil {
+		history, err := ix.store.RecentBlocks(ctx, follower.DefaultWindow)
+		if err != nil {
+			return err
+		}
+		ix.follower = follower.New(ix.client,
+			follower.WithStartHeight(ix.startHeight),
+			follower.WithHistory(history))
+	}
+
+	err := ix.follower.Step(ctx, ix.handle)
+	if errors.Is(err, follower.ErrReorgTooDeep) {
+		return fmt.Errorf("%w; rebuild the index", err)
+	}
+	return err
+}
+
+// handle mirrors a follower event into the store
+func (ix *Indexer) handle(ctx context.Context, event follower.Event) error {
+	block := event.Block
+
+	if event.Type == follower.Reverted {
+		logger.InfoContext(ctx, ix.logger, "Reorg detected, rolling back block", "height", block.Height, "hash", block.Hash)
+		return ix.store.Rollback(ctx, block.Height)
+	}
+
+	receipts, err := ix.client.GetBlockReceipts(ctx, block)
+	if err != nil {
+		return fmt.Errorf("failed to get receipts of block %d: %w", block.Height, err)
+	}
+	if err := ix.store.AddBlock(ctx, block, receipts); err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, ix.logger, "Indexed block", "height", block.Height, "transactions", len(block.Transactions))
+	return nil
+}
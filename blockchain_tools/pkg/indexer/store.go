@@ -0,0 +1,382 @@
+// pkg/indexer/store.go
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+)
+
+// ErrNotFound is returned when the index holds no matching data
+var ErrNotFound = errors.New("not found in index")
+
+// Page sizes for transaction queries
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	number      INTEGER PRIMARY KEY,
+	hash        TEXT NOT NULL UNIQUE,
+	parent_hash TEXT NOT NULL,
+	timestamp   INTEGER NOT NULL,
+	miner       TEXT NOT NULL,
+	gas_limit   INTEGER NOT NULL,
+	gas_used    INTEGER NOT NULL,
+	base_fee    TEXT,
+	size        INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	hash                TEXT PRIMARY KEY,
+	block_number        INTEGER NOT NULL REFERENCES blocks (number) ON DELETE CASCADE,
+	tx_index            INTEGER NOT NULL,
+	from_address        TEXT NOT NULL,
+	to_address          TEXT NOT NULL,
+	contract_address    TEXT NOT NULL,
+	value               TEXT,
+	data                BLOB,
+	nonce               INTEGER NOT NULL,
+	gas                 INTEGER NOT NULL,
+	gas_price           TEXT,
+	max_fee             TEXT,
+	max_priority_fee    TEXT,
+	chain_id            TEXT,
+	type                INTEGER NOT NULL,
+	status              TEXT NOT NULL,
+	gas_used            INTEGER NOT NULL,
+	effective_gas_price TEXT
+);
+CREATE INDEX IF NOT EXISTS transactions_block ON transactions (block_number, tx_index);
+CREATE INDEX IF NOT EXISTS transactions_from ON transactions (from_address, block_number);
+CREATE INDEX IF NOT EXISTS transactions_to ON transactions (to_address, block_number);
+CREATE INDEX IF NOT EXISTS transactions_contract ON transactions (contract_address, block_number);
+
+CREATE TABLE IF NOT EXISTS logs (
+	block_number     INTEGER NOT NULL REFERENCES blocks (number) ON DELETE CASCADE,
+	log_index        INTEGER NOT NULL,
+	transaction_hash TEXT NOT NULL,
+	address          TEXT NOT NULL,
+	topic0           TEXT,
+	topic1           TEXT,
+	topic2           TEXT,
+	topic3           TEXT,
+	data             BLOB,
+	PRIMARY KEY (block_number, log_index)
+);
+CREATE INDEX IF NOT EXISTS logs_address ON logs (address, block_number);
+CREATE INDEX IF NOT EXISTS logs_topic0 ON logs (topic0, block_number);
+`
+
+// Store is the SQLite database holding indexed chain data. Addresses are
+// stored in lower case so lookups are case-insensitive.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens the database at path, creating it and its schema if needed
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create index schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Head returns the highest indexed block without its transactions
+func (s *Store) Head(ctx context.Context) (*types.Block, error) {
+	return s.block(ctx, "ORDER BY number DESC LIMIT 1")
+}
+
+// Block returns the indexed block at height without its transactions
+func (s *Store) Block(ctx context.Context, height uint64) (*types.Block, error) {
+	return s.block(ctx, "WHERE number = ?", height)
+}
+
+// RecentBlocks returns up to n of the highest indexed blocks without their
+// transactions, oldest first
+func (s *Store) RecentBlocks(ctx context.Context, n int) ([]*types.Block, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT number, hash, parent_hash, timestamp, miner, gas_limit, gas_used, base_fee, size
+		FROM blocks ORDER BY number DESC LIMIT ?`, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*types.Block
+	for rows.Next() {
+		block, err := scanBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append([]*types.Block{block}, blocks...)
+	}
+	return blocks, rows.Err()
+}
+
+func (s *Store) block(ctx context.Context, clause string, args ...interface{}) (*types.Block, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT number, hash, parent_hash, timestamp, miner, gas_limit, gas_used, base_fee, size
+		FROM blocks `+clause, args...)
+
+	block, err := scanBlock(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return block, err
+}
+
+// scanBlock reads a block header selected in the column order used above
+func scanBlock(row interface{ Scan(...interface{}) error }) (*types.Block, error) {
+	var (
+		block   types.Block
+		baseFee sql.NullString
+	)
+	err := row.Scan(&block.Height, &block.Hash, &block.PreviousHash, &block.Timestamp,
+		&block.Miner, &block.GasLimit, &block.GasUsed, &baseFee, &block.Size)
+	if err != nil {
+		return nil, err
+	}
+	block.Miner = checksum(block.Miner)
+	block.BaseFee = parseBig(baseFee)
+	return &block, nil
+}
+
+// AddBlock stores block with its transactions, their receipts and logs.
+// receipts must be in block order.
+func (s *Store) AddBlock(ctx context.Context, block *types.Block, receipts []*types.Receipt) error {
+	if len(receipts) != len(block.Transactions) {
+		return fmt.Errorf("block %d has %d transactions but %d receipts",
+			block.Height, len(block.Transactions), len(receipts))
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO blocks (number, hash, parent_hash, timestamp, miner, gas_limit, gas_used, base_fee, size)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		block.Height, block.Hash, block.PreviousHash, block.Timestamp, lower(block.Miner),
+		block.GasLimit, block.GasUsed, formatBig(block.BaseFee), block.Size)
+	if err != nil {
+		return fmt.Errorf("failed to store block %d: %w", block.Height, err)
+	}
+
+	for i, t := range block.Transactions {
+		r := receipts[i]
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transactions (hash, block_number, tx_index, from_address, to_address,
+				contract_address, value, data, nonce, gas, gas_price, max_fee, max_priority_fee,
+				chain_id, type, status, gas_used, effective_gas_price)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.Hash, block.Height, i, lower(t.From), lower(t.To), lower(r.ContractAddress),
+			formatBig(t.Value), t.Data, t.Nonce, t.Gas, formatBig(t.GasPrice),
+			formatBig(t.MaxFeePerGas), formatBig(t.MaxPriorityFeePerGas), formatBig(t.ChainID),
+			t.Type, r.Status, r.GasUsed, formatBig(r.EffectiveGasPrice))
+		if err != nil {
+			return fmt.Errorf("failed to store transaction %s: %w", t.Hash, err)
+		}
+
+		for _, l := range r.Logs {
+			var topics [4]sql.NullString
+			for j := 0; j < len(l.Topics) && j < len(topics); j++ {
+				topics[j] = sql.NullString{String: l.Topics[j], Valid: true}
+			}
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO logs (block_number, log_index, transaction_hash, address,
+					topic0, topic1, topic2, topic3, data)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				block.Height, l.LogIndex, t.Hash, lower(l.Address),
+				topics[0], topics[1], topics[2], topics[3], l.Data)
+			if err != nil {
+				return fmt.Errorf("failed to store log %d of block %d: %w", l.LogIndex, block.Height, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Rollback removes every block from height upwards together with its
+// transactions and logs
+func (s *Store) Rollback(ctx context.Context, height uint64) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM blocks WHERE number >= ?", height)
+	return err
+}
+
+// TxQuery selects indexed transactions, newest first
+type TxQuery struct {
+	// Address matches transactions sent from, sent to or creating it
+	Address string
+	Limit   int
+	Offset  int
+}
+
+// Transactions returns the indexed transactions matching q
+func (s *Store) Transactions(ctx context.Context, q TxQuery) ([]*types.Transaction, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+
+	where, args := "", []interface{}{}
+	if q.Address != "" {
+		addr := lower(q.Address)
+		where = "WHERE t.from_address = ? OR t.to_address = ? OR t.contract_address = ?"
+		args = append(args, addr, addr, addr)
+	}
+	args = append(args, q.Limit, q.Offset)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.hash, t.from_address, t.to_address, t.value, t.data, t.nonce, t.gas,
+			t.gas_price, t.max_fee, t.max_priority_fee, t.chain_id, t.type, t.status,
+			b.hash, b.number, b.timestamp
+		FROM transactions t JOIN blocks b ON b.number = t.block_number
+		`+where+`
+		ORDER BY t.block_number DESC, t.tx_index DESC
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]*types.Transaction, 0)
+	for rows.Next() {
+		var (
+			tx                                               types.Transaction
+			value, gasPrice, maxFee, maxPriorityFee, chainID sql.NullString
+		)
+		err := rows.Scan(&tx.Hash, &tx.From, &tx.To, &value, &tx.Data, &tx.Nonce, &tx.Gas,
+			&gasPrice, &maxFee, &maxPriorityFee, &chainID, &tx.Type, &tx.Status,
+			&tx.BlockHash, &tx.BlockNumber, &tx.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		tx.From, tx.To = checksum(tx.From), checksum(tx.To)
+		tx.Value = parseBig(value)
+		tx.GasPrice = parseBig(gasPrice)
+		tx.MaxFeePerGas = parseBig(maxFee)
+		tx.MaxPriorityFeePerGas = parseBig(maxPriorityFee)
+		tx.ChainID = parseBig(chainID)
+		txs = append(txs, &tx)
+	}
+	return txs, rows.Err()
+}
+
+// transferTopic is the topic of the Transfer event shared by ERC-20 and
+// ERC-721, with the sender and recipient as the next two topics
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")).Hex()
+
+// TokenContracts returns the contracts that emitted a Transfer event to or
+// from address within the index, which are the tokens it may hold
+func (s *Store) TokenContracts(ctx context.Context, address string) ([]string, error) {
+	topic := common.BytesToHash(common.HexToAddress(address).Bytes()).Hex()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT address FROM logs
+		WHERE topic0 = ? AND (topic1 = ? OR topic2 = ?)
+		ORDER BY address`, transferTopic, topic, topic)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contracts := make([]string, 0)
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, checksum(addr))
+	}
+	return contracts, rows.Err()
+}
+
+// Status summarizes the contents of the index
+func (s *Store) Status(ctx context.Context) (*types.IndexStatus, error) {
+	status := &types.IndexStatus{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM blocks),
+			(SELECT COUNT(*) FROM transactions),
+			(SELECT COUNT(*) FROM logs),
+			(SELECT COALESCE(MIN(number), 0) FROM blocks)`).
+		Scan(&status.Blocks, &status.Transactions, &status.Logs, &status.StartHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := s.Head(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Height = head.Height
+	status.Hash = head.Hash
+	return status, nil
+}
+
+func lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// checksum restores the mixed-case form of a stored address
+func checksum(s string) string {
+	if s == "" {
+		return ""
+	}
+	return common.HexToAddress(s).Hex()
+}
+
+// formatBig stores nil amounts as NULL
+func formatBig(v *big.Int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.String()
+}
+
+func parseBig(v sql.NullString) *big.Int {
+	if !v.Valid {
+		return nil
+	}
+	n, ok := new(big.Int).SetString(v.String, 10)
+	if !ok {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,307 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+var (
+	alice = "0x00000000000000000000000000000000000A11CE"
+	bob   = "0x0000000000000000000000000000000000000B0B"
+	carol = "0x00000000000000000000000000000000000CA201"
+	// token is the contract emitting Transfer events in test blocks
+	token = "0x00000000000000000000000000000000000070CE"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "index", "chain.db"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// payment is a transaction of a test block, with addresses in any case
+type payment struct {
+	from, to string
+	// transfer adds a token Transfer event from from to to
+	transfer bool
+}
+
+// testBlock builds block height of fork with a transaction per payment and
+// their receipts. Hashes name the fork, height and position.
+func testBlock(fork string, height uint64, payments ...payment) (*types.Block, []*types.Receipt) {
+	block := &types.Block{
+		Hash:         hash(fork, height, 0),
+		Height:       height,
+		PreviousHash: hash(fork, height-1, 0),
+		Timestamp:    int64(1700000000 + height*12),
+		Miner:        carol,
+		GasLimit:     30000000,
+		GasUsed:      21000 * uint64(len(payments)),
+		BaseFee:      big.NewInt(1e9),
+		Size:         1000,
+	}
+	receipts := make([]*types.Receipt, len(payments))
+	for i, p := range payments {
+		tx := &types.Transaction{
+			Hash:                 hash(fork, height, i+1),
+			From:                 p.from,
+			To:                   p.to,
+			Value:                big.NewInt(int64(height*100) + int64(i)),
+			Nonce:                uint64(i),
+			Gas:                  21000,
+			MaxFeePerGas:         big.NewInt(2e9),
+			MaxPriorityFeePerGas: big.NewInt(1e9),
+			ChainID:              big.NewInt(1),
+			Type:                 2,
+		}
+		block.Transactions = append(block.Transactions, tx)
+
+		receipt := &types.Receipt{TransactionHash: tx.Hash, Status: types.StatusConfirmed, GasUsed: 21000}
+		if p.transfer {
+			receipt.Logs = []*types.Log{{
+				Address:  token,
+				Topics:   []string{transferTopic, addressTopic(p.from), addressTopic(p.to)},
+				Data:     common.LeftPadBytes(big.NewInt(5).Bytes(), 32),
+				LogIndex: uint(i),
+			}}
+		}
+		receipts[i] = receipt
+	}
+	return block, receipts
+}
+
+func hash(fork string, height uint64, i int) string {
+	return common.BytesToHash([]byte(fmt.Sprintf("%s-%d-%d", fork, height, i))).Hex()
+}
+
+func addressTopic(addr string) string {
+	return common.BytesToHash(common.HexToAddress(addr).Bytes()).Hex()
+}
+
+func addBlock(t *testing.T, store *Store, block *types.Block, receipts []*types.Receipt) {
+	t.Helper()
+	if err := store.AddBlock(context.Background(), block, receipts); err != nil {
+		t.Fatalf("AddBlock(%d): %v", block.Height, err)
+	}
+}
+
+func hashes(txs []*types.Transaction) []string {
+	out := make([]string, len(txs))
+	for i, tx := range txs {
+		out[i] = tx.Hash
+	}
+	return out
+}
+
+func TestStoreAddBlock(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+
+	if _, err := store.Head(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head of an empty store: error = %v, want ErrNotFound", err)
+	}
+
+	// Addresses arrive in any case and come back checksummed
+	block, receipts := testBlock("a", 10, payment{from: strings.ToLower(alice), to: strings.ToUpper("0x" + bob[2:])})
+	addBlock(t, store, block, receipts)
+
+	got, err := store.Block(ctx, 10)
+	if err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	want := *block
+	want.Transactions = nil
+	want.Miner = common.HexToAddress(carol).Hex()
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("Block = %+v, want %+v", got, &want)
+	}
+	if head, err := store.Head(ctx); err != nil || head.Hash != block.Hash {
+		t.Errorf("Head = %v, %v", head, err)
+	}
+	if _, err := store.Block(ctx, 11); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Block(11) error = %v, want ErrNotFound", err)
+	}
+
+	txs, err := store.Transactions(ctx, TxQuery{})
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d transactions, want 1", len(txs))
+	}
+	tx := txs[0]
+	if tx.From != common.HexToAddress(alice).Hex() || tx.To != common.HexToAddress(bob).Hex() {
+		t.Errorf("addresses = %s -> %s, want checksummed %s -> %s", tx.From, tx.To, alice, bob)
+	}
+	if tx.BlockHash != block.Hash || tx.BlockNumber != 10 || tx.Timestamp != block.Timestamp ||
+		tx.Status != types.StatusConfirmed || tx.Value.Cmp(block.Transactions[0].Value) != 0 ||
+		tx.MaxFeePerGas.Cmp(big.NewInt(2e9)) != 0 || tx.GasPrice != nil {
+		t.Errorf("transaction = %+v", tx)
+	}
+
+	// A block is stored once, and receipts must match its transactions
+	if err := store.AddBlock(ctx, block, receipts); err == nil {
+		t.Error("the same block was stored twice")
+	}
+	next, _ := testBlock("a", 11, payment{from: alice, to: bob})
+	if err := store.AddBlock(ctx, next, nil); err == nil {
+		t.Error("a block without its receipts was stored")
+	}
+	if _, err := store.Block(ctx, 11); !errors.Is(err, ErrNotFound) {
+		t.Error("a rejected block was stored in part")
+	}
+}
+
+func TestStoreTransactions(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+
+	for height := uint64(1); height <= 3; height++ {
+		block, receipts := testBlock("a", height,
+			payment{from: alice, to: bob},
+			payment{from: strings.ToLower(bob), to: carol},
+			payment{from: carol, to: strings.ToLower(alice)})
+		addBlock(t, store, block, receipts)
+	}
+
+	all, err := store.Transactions(ctx, TxQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 9 {
+		t.Fatalf("got %d transactions, want 9", len(all))
+	}
+	// Newest first, by block and then position
+	if all[0].Hash != hash("a", 3, 3) || all[8].Hash != hash("a", 1, 1) {
+		t.Errorf("order = %v", hashes(all))
+	}
+
+	tests := []struct {
+		name  string
+		query TxQuery
+		want  []string
+	}{
+		{"first page", TxQuery{Limit: 4}, hashes(all[:4])},
+		{"second page", TxQuery{Limit: 4, Offset: 4}, hashes(all[4:8])},
+		{"last page", TxQuery{Limit: 4, Offset: 8}, hashes(all[8:])},
+		{"past the end", TxQuery{Limit: 4, Offset: 20}, []string{}},
+		{"sent or received, checksummed query", TxQuery{Address: common.HexToAddress(bob).Hex()},
+			[]string{hash("a", 3, 2), hash("a", 3, 1), hash("a", 2, 2), hash("a", 2, 1), hash("a", 1, 2), hash("a", 1, 1)}},
+		{"lower-case query", TxQuery{Address: strings.ToLower(alice), Limit: 2},
+			[]string{hash("a", 3, 3), hash("a", 3, 1)}},
+		{"upper-case query", TxQuery{Address: "0x" + strings.ToUpper(carol[2:]), Limit: 2, Offset: 4},
+			[]string{hash("a", 1, 3), hash("a", 1, 2)}},
+		{"unknown address", TxQuery{Address: token}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := store.Transactions(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hashes(txs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transactions(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreRollback(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+
+	for height := uint64(5); height <= 8; height++ {
+		block, receipts := testBlock("a", height, payment{from: alice, to: bob, transfer: true})
+		addBlock(t, store, block, receipts)
+	}
+
+	// A reorg replaces blocks 7 and 8
+	if err := store.Rollback(ctx, 7); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	status, err := store.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.IndexStatus{Height: 6, Hash: hash("a", 6, 0), StartHeight: 5, Blocks: 2, Transactions: 2, Logs: 2}
+	if *status != want {
+		t.Errorf("Status after rollback = %+v, want %+v", *status, want)
+	}
+	txs, err := store.Transactions(ctx, TxQuery{Address: alice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes(txs); !reflect.DeepEqual(got, []string{hash("a", 6, 1), hash("a", 5, 1)}) {
+		t.Errorf("transactions after rollback = %v", got)
+	}
+
+	// The replacing blocks can be stored at the same heights
+	block, receipts := testBlock("b", 7, payment{from: carol, to: bob})
+	addBlock(t, store, block, receipts)
+	recent, err := store.RecentBlocks(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 || recent[0].Hash != hash("a", 6, 0) || recent[1].Hash != hash("b", 7, 0) {
+		t.Errorf("RecentBlocks = %v", recent)
+	}
+
+	// Rolling back past the start empties the store
+	if err := store.Rollback(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := store.Status(ctx); *status != (types.IndexStatus{}) {
+		t.Errorf("Status after full rollback = %+v", *status)
+	}
+}
+
+func TestStoreTokenContracts(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+
+	other := "0x00000000000000000000000000000000000070CF"
+	block, receipts := testBlock("a", 1,
+		payment{from: alice, to: bob, transfer: true},
+		payment{from: bob, to: carol, transfer: true},
+		payment{from: carol, to: bob})
+	// The second transfer comes from another token
+	receipts[1].Logs[0].Address = strings.ToLower(other)
+	addBlock(t, store, block, receipts)
+
+	// A log with a different event does not make a token
+	block, receipts = testBlock("a", 2, payment{from: alice, to: carol, transfer: true})
+	receipts[0].Logs[0].Topics[0] = hash("approval", 0, 0)
+	addBlock(t, store, block, receipts)
+
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		{strings.ToLower(bob), []string{common.HexToAddress(token).Hex(), common.HexToAddress(other).Hex()}},
+		{alice, []string{common.HexToAddress(token).Hex()}},
+		{carol, []string{common.HexToAddress(other).Hex()}},
+		{token, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := store.TokenContracts(ctx, tt.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenContracts(%s) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
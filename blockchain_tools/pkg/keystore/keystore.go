@@ -0,0 +1,289 @@
+// pkg/keystore/keystore.go
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned when the store holds no key for an address
+	ErrNotFound = errors.New("key not found in keystore")
+	// ErrExists is returned when importing a key that is already stored
+	ErrExists = errors.New("key already exists in keystore")
+	// ErrDecrypt is returned when a key cannot be unlocked with the passphrase
+	ErrDecrypt = gethkeystore.ErrDecrypt
+)
+
+// Scrypt parameters used when encrypting keys
+const (
+	StandardScryptN = gethkeystore.StandardScryptN
+	StandardScryptP = gethkeystore.StandardScryptP
+	LightScryptN    = gethkeystore.LightScryptN
+	LightScryptP    = gethkeystore.LightScryptP
+)
+
+// Entry is a single encrypted key held by the store
+type Entry struct {
+	Address common.Address  `json:"address"`
+	Created time.Time       `json:"created"`
+	Crypto  json.RawMessage `json:"keystore"` // Web3 Secret Storage v3 document
+	// Path is the derivation path of keys derived from the stored seed
+	Path string `json:"path,omitempty"`
+}
+
+// file is the on-disk layout of the key file
+type file struct {
+	Seed *Seed    `json:"seed,omitempty"`
+	Keys []*Entry `json:"keys"`
+}
+
+// Store is a set of encrypted private keys persisted in a single JSON file.
+// Each key is encrypted independently using the Web3 Secret Storage format,
+// so entries can be exported to and imported from other Ethereum tools.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	scryptN int
+	scryptP int
+	seed    *Seed
+	entries []*Entry
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithScrypt sets the scrypt parameters used for newly encrypted keys
+func WithScrypt(n, p int) Option {
+	return func(s *Store) {
+		s.scryptN = n
+		s.scryptP = p
+	}
+}
+
+// Open loads the key file at path. A missing file yields an empty store
+// that is created on the first write.
+func Open(path string, opts ...Option) (*Store, error) {
+	s := &Store{
+		path:    path,
+		scryptN: StandardScryptN,
+		scryptP: StandardScryptP,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+	s.seed = f.Seed
+	s.entries = f.Keys
+	return s, nil
+}
+
+// Path returns the location of the key file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Accounts returns the addresses of all stored keys
+func (s *Store) Accounts() []common.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addrs := make([]common.Address, 0, len(s.entries))
+	for _, e := range s.entries {
+		addrs = append(addrs, e.Address)
+	}
+	return addrs
+}
+
+// Has reports whether the store holds a key for addr
+func (s *Store) Has(addr common.Address) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.find(addr) != nil
+}
+
+// NewAccount generates a fresh key, encrypts it with passphrase and
+// persists it
+func (s *Store) NewAccount(passphrase string) (common.Address, error) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return s.Import(priv, passphrase)
+}
+
+// Import encrypts priv with passphrase and persists it
+func (s *Store) Import(priv *ecdsa.PrivateKey, passphrase string) (common.Address, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.importKey(priv, passphrase, "")
+}
+
+// importKey stores priv, derived at path if it is not empty. The caller must
+// hold the write lock.
+func (s *Store) importKey(priv *ecdsa.PrivateKey, passphrase, path string) (common.Address, error) {
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	if s.find(addr) != nil {
+		return common.Address{}, ErrExists
+	}
+
+	key := &gethkeystore.Key{
+		Id:         uuid.New(),
+		Address:    addr,
+		PrivateKey: priv,
+	}
+	keyJSON, err := gethkeystore.EncryptKey(key, passphrase, s.scryptN, s.scryptP)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	s.entries = append(s.entries, &Entry{
+		Address: addr,
+		Created: time.Now().UTC(),
+		Crypto:  keyJSON,
+		Path:    path,
+	})
+	if err := s.save(); err != nil {
+		s.entries = s.entries[:len(s.entries)-1]
+		return common.Address{}, err
+	}
+	return addr, nil
+}
+
+// ImportJSON stores a Web3 Secret Storage document after checking that
+// passphrase unlocks it
+func (s *Store) ImportJSON(keyJSON []byte, passphrase string) (common.Address, error) {
+	key, err := gethkeystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return s.Import(key.PrivateKey, passphrase)
+}
+
+// Export returns the Web3 Secret Storage document for addr
+func (s *Store) Export(addr common.Address) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e := s.find(addr)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), e.Crypto...), nil
+}
+
+// Delete removes the key for addr after checking passphrase unlocks it
+func (s *Store) Delete(addr common.Address, passphrase string) error {
+	if _, err := s.Unlock(addr, passphrase); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.Address == addr {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return s.save()
+		}
+	}
+	return ErrNotFound
+}
+
+// DerivationPath returns the path addr was derived at from the stored seed,
+// or "" for keys that were generated or imported
+func (s *Store) DerivationPath(addr common.Address) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e := s.find(addr); e != nil {
+		return e.Path
+	}
+	return ""
+}
+
+// Unlock decrypts and returns the private key for addr
+func (s *Store) Unlock(addr common.Address, passphrase string) (*ecdsa.PrivateKey, error) {
+	s.mu.RLock()
+	e := s.find(addr)
+	s.mu.RUnlock()
+	if e == nil {
+		return nil, ErrNotFound
+	}
+
+	key, err := gethkeystore.DecryptKey(e.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if key.Address != addr {
+		return nil, fmt.Errorf("key file entry for %s holds key for %s", addr.Hex(), key.Address.Hex())
+	}
+	return key.PrivateKey, nil
+}
+
+func (s *Store) find(addr common.Address) *Entry {
+	for _, e := range s.entries {
+		if e.Address == addr {
+			return e
+		}
+	}
+	return nil
+}
+
+// save writes the key file atomically with owner-only permissions
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(&file{Seed: s.seed, Keys: s.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key file: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".keys-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
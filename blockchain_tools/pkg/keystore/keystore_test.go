@@ -0,0 +1,229 @@
+package keystore
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// openTestStore opens an empty store in a temp dir with light scrypt
+// parameters, so tests do not spend seconds on each key
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "keys", "keys.json"), WithScrypt(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return store
+}
+
+// reopen loads the key file of store again, as a new process would
+func reopen(t *testing.T, store *Store) *Store {
+	t.Helper()
+	reopened, err := Open(store.Path(), WithScrypt(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatalf("reopening %s: %v", store.Path(), err)
+	}
+	return reopened
+}
+
+func TestSavePermissions(t *testing.T) {
+	store := openTestStore(t)
+	if _, err := os.Stat(store.Path()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("opening an empty store created its file: %v", err)
+	}
+	if _, err := store.NewAccount("pw"); err != nil {
+		t.Fatalf("NewAccount: %v", err)
+	}
+
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key file permissions = %o, want 600", perm)
+	}
+	dir, err := os.Stat(filepath.Dir(store.Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := dir.Mode().Perm(); perm != 0o700 {
+		t.Errorf("key directory permissions = %o, want 700", perm)
+	}
+
+	// A key file made readable by others is written back as owner-only, and
+	// no temporary file is left next to it
+	if err := os.Chmod(store.Path(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.NewAccount("pw"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(store.Path()); info.Mode().Perm() != 0o600 {
+		t.Errorf("key file permissions after rewrite = %o, want 600", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(store.Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("key directory holds %d files, want only the key file", len(entries))
+	}
+}
+
+func TestSaveFailureKeepsStore(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	store := openTestStore(t)
+	addr, err := store.NewAccount("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(store.Path())
+	if err := os.Chmod(dir, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0o700)
+
+	if _, err := store.NewAccount("pw"); err == nil {
+		t.Fatal("NewAccount succeeded in a read-only directory")
+	}
+	if accounts := store.Accounts(); len(accounts) != 1 || accounts[0] != addr {
+		t.Errorf("accounts after a failed save = %v", accounts)
+	}
+	if accounts := reopen(t, store).Accounts(); len(accounts) != 1 {
+		t.Errorf("key file holds %d accounts after a failed save", len(accounts))
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	store := openTestStore(t)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := store.Import(key, "right")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if addr != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("Import returned %s", addr.Hex())
+	}
+
+	store = reopen(t, store)
+	if !store.Has(addr) {
+		t.Fatal("imported key was not persisted")
+	}
+	unlocked, err := store.Unlock(addr, "right")
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if !unlocked.Equal(key) {
+		t.Error("Unlock returned another key")
+	}
+
+	if _, err := store.Unlock(addr, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Unlock with a wrong password: error = %v, want ErrDecrypt", err)
+	}
+	if _, err := store.Unlock(common.HexToAddress("0x01"), "right"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Unlock of an unknown address: error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Import(key, "other"); !errors.Is(err, ErrExists) {
+		t.Errorf("importing a key twice: error = %v, want ErrExists", err)
+	}
+
+	// Keys are deleted only with their password
+	if err := store.Delete(addr, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Delete with a wrong password: error = %v, want ErrDecrypt", err)
+	}
+	if err := store.Delete(addr, "right"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if reopen(t, store).Has(addr) {
+		t.Error("deleted key is still in the key file")
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	// A key encrypted by another tool in the Web3 Secret Storage format
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	keyJSON, err := gethkeystore.EncryptKey(&gethkeystore.Key{Id: uuid.New(), Address: addr, PrivateKey: priv},
+		"pw", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := openTestStore(t)
+	if _, err := store.ImportJSON(keyJSON, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("ImportJSON with a wrong password: error = %v, want ErrDecrypt", err)
+	}
+	if len(store.Accounts()) != 0 {
+		t.Fatal("a key that failed to import was stored")
+	}
+	imported, err := store.ImportJSON(keyJSON, "pw")
+	if err != nil {
+		t.Fatalf("ImportJSON: %v", err)
+	}
+	if imported != addr {
+		t.Fatalf("ImportJSON returned %s, want %s", imported.Hex(), addr.Hex())
+	}
+
+	// The exported document opens in other tools, and in another store
+	exported, err := reopen(t, store).Export(addr)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	key, err := gethkeystore.DecryptKey(exported, "pw")
+	if err != nil {
+		t.Fatalf("decrypting the exported key: %v", err)
+	}
+	if key.Address != addr || !key.PrivateKey.Equal(priv) {
+		t.Error("exported key differs from the imported one")
+	}
+	other := openTestStore(t)
+	if got, err := other.ImportJSON(exported, "pw"); err != nil || got != addr {
+		t.Errorf("importing the export = %s, %v", got.Hex(), err)
+	}
+
+	if _, err := store.Export(common.HexToAddress("0x01")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Export of an unknown address: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSignTx(t *testing.T) {
+	store := openTestStore(t)
+	addr, err := store.NewAccount("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := gethtypes.NewTx(&gethtypes.DynamicFeeTx{ChainID: big.NewInt(5), Nonce: 1, Gas: 21000,
+		GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e9), To: &addr, Value: big.NewInt(1)})
+
+	if _, err := store.SignTx(addr, "wrong", tx, big.NewInt(5)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("SignTx with a wrong password: error = %v, want ErrDecrypt", err)
+	}
+	if _, err := store.SignTx(addr, "pw", tx, nil); err == nil {
+		t.Error("SignTx without a chain ID succeeded")
+	}
+	signed, err := store.SignTx(addr, "pw", tx, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	sender, err := gethtypes.Sender(gethtypes.LatestSignerForChainID(big.NewInt(5)), signed)
+	if err != nil || sender != addr {
+		t.Errorf("signed by %s, %v, want %s", sender.Hex(), err, addr.Hex())
+	}
+}
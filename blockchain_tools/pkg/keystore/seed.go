@@ -0,0 +1,126 @@
+// pkg/keystore/seed.go
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/hdwallet"
+)
+
+var (
+	// ErrNoSeed is returned when deriving a key from a store without a seed
+	ErrNoSeed = errors.New("key file holds no mnemonic")
+	// ErrSeedExists is returned when storing a seed that differs from the
+	// one already held
+	ErrSeedExists = errors.New("key file already holds a different mnemonic")
+)
+
+// Seed is the encrypted BIP-39 seed of a mnemonic. Only the seed is kept:
+// the mnemonic itself cannot be recovered from the key file.
+type Seed struct {
+	Created time.Time               `json:"created"`
+	Crypto  gethkeystore.CryptoJSON `json:"crypto"`
+}
+
+// HasSeed reports whether the store holds a mnemonic seed
+func (s *Store) HasSeed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seed != nil
+}
+
+// SetMnemonic encrypts the seed of mnemonic with passphrase and persists it,
+// so keys can later be derived with Derive. seedPassphrase is the optional
+// BIP-39 passphrase mixed into the seed, which wallets also call the 25th
+// word; it is only needed here, as the seed is what gets stored. Setting the
+// mnemonic the store already holds is a no-op; a different one, or the same
+// one with another seed passphrase, fails with ErrSeedExists.
+func (s *Store) SetMnemonic(mnemonic, seedPassphrase, passphrase string) error {
+	seed, err := hdwallet.Seed(mnemonic, seedPassphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seed != nil {
+		stored, err := gethkeystore.DecryptDataV3(s.seed.Crypto, passphrase)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(stored, seed) {
+			return ErrSeedExists
+		}
+		return nil
+	}
+
+	enc, err := gethkeystore.EncryptDataV3(seed, []byte(passphrase), s.scryptN, s.scryptP)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt seed: %w", err)
+	}
+	s.seed = &Seed{Created: time.Now().UTC(), Crypto: enc}
+	if err := s.save(); err != nil {
+		s.seed = nil
+		return err
+	}
+	return nil
+}
+
+// Derive derives the key at path from the stored seed, encrypts it with
+// passphrase, which must also unlock the seed, and persists it. Deriving a
+// path that is already stored returns its address.
+func (s *Store) Derive(path accounts.DerivationPath, passphrase string) (common.Address, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seed == nil {
+		return common.Address{}, ErrNoSeed
+	}
+	for _, e := range s.entries {
+		if e.Path == path.String() {
+			return e.Address, nil
+		}
+	}
+
+	seed, err := gethkeystore.DecryptDataV3(s.seed.Crypto, passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	priv, err := hdwallet.Derive(seed, path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return s.importKey(priv, passphrase, path.String())
+}
+
+// NextPath returns the default BIP-44 path following the highest account
+// index derived so far, or the first one if none was
+func (s *Store) NextPath() accounts.DerivationPath {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	base := hdwallet.DefaultPath
+	next := uint32(0)
+	for _, e := range s.entries {
+		path, err := accounts.ParseDerivationPath(e.Path)
+		if err != nil || len(path) != len(base) || !slices.Equal(path[:len(path)-1], base[:len(base)-1]) {
+			continue
+		}
+		if index := path[len(path)-1]; index >= next {
+			next = index + 1
+		}
+	}
+
+	path := make(accounts.DerivationPath, len(base))
+	copy(path, base)
+	path[len(path)-1] = next
+	return path
+}
@@ -0,0 +1,124 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/layla-lili/blockchain_tools/pkg/hdwallet"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestSeedRoundTrip(t *testing.T) {
+	store := openTestStore(t)
+	if store.HasSeed() {
+		t.Fatal("new store holds a seed")
+	}
+	if _, err := store.Derive(hdwallet.DefaultPath, "pw"); !errors.Is(err, ErrNoSeed) {
+		t.Fatalf("Derive without a seed: error = %v, want ErrNoSeed", err)
+	}
+
+	if err := store.SetMnemonic(testMnemonic, "", "pw"); err != nil {
+		t.Fatalf("SetMnemonic: %v", err)
+	}
+
+	// Only the encrypted seed reaches the disk
+	data, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "abandon") {
+		t.Error("key file holds the mnemonic")
+	}
+
+	// The seed is decrypted again after reopening
+	store = reopen(t, store)
+	if !store.HasSeed() {
+		t.Fatal("seed was not persisted")
+	}
+	if _, err := store.Derive(hdwallet.DefaultPath, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Derive with a wrong password: error = %v, want ErrDecrypt", err)
+	}
+	addr, err := store.Derive(hdwallet.DefaultPath, "pw")
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	if addr.Hex() != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Errorf("first account = %s", addr.Hex())
+	}
+	if path := store.DerivationPath(addr); path != "m/44'/60'/0'/0/0" {
+		t.Errorf("DerivationPath = %q", path)
+	}
+	if next := store.NextPath().String(); next != "m/44'/60'/0'/0/1" {
+		t.Errorf("NextPath = %s", next)
+	}
+	again, err := store.Derive(hdwallet.DefaultPath, "pw")
+	if err != nil || again != addr {
+		t.Errorf("deriving a stored path = %s, %v", again.Hex(), err)
+	}
+
+	// The derived key is stored like any other
+	if _, err := store.Unlock(addr, "pw"); err != nil {
+		t.Errorf("Unlock derived key: %v", err)
+	}
+}
+
+func TestSetMnemonicTwice(t *testing.T) {
+	store := openTestStore(t)
+	if err := store.SetMnemonic(testMnemonic, "", "pw"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same mnemonic is accepted again, in any form
+	if err := store.SetMnemonic(strings.ToUpper(testMnemonic), "", "pw"); err != nil {
+		t.Errorf("setting the same mnemonic: %v", err)
+	}
+	other, err := hdwallet.NewMnemonic(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMnemonic(other, "", "pw"); !errors.Is(err, ErrSeedExists) {
+		t.Errorf("setting another mnemonic: error = %v, want ErrSeedExists", err)
+	}
+	if err := store.SetMnemonic(testMnemonic, "TREZOR", "pw"); !errors.Is(err, ErrSeedExists) {
+		t.Errorf("setting another seed passphrase: error = %v, want ErrSeedExists", err)
+	}
+	if err := store.SetMnemonic(testMnemonic, "", "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong password: error = %v, want ErrDecrypt", err)
+	}
+	if err := openTestStore(t).SetMnemonic("abandon about", "", "pw"); !errors.Is(err, hdwallet.ErrInvalidMnemonic) {
+		t.Errorf("invalid mnemonic: error = %v, want ErrInvalidMnemonic", err)
+	}
+}
+
+func TestSetMnemonicSeedPassphrase(t *testing.T) {
+	store := openTestStore(t)
+	if err := store.SetMnemonic(testMnemonic, "TREZOR", "pw"); err != nil {
+		t.Fatal(err)
+	}
+	addr, err := store.Derive(hdwallet.DefaultPath, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed, err := hdwallet.Seed(testMnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := hdwallet.Derive(seed, hdwallet.DefaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := store.Unlock(addr, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unlocked.Equal(key) {
+		t.Error("derived key does not come from the seed with the passphrase")
+	}
+	if addr.Hex() == "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Error("seed passphrase was ignored")
+	}
+}
@@ -0,0 +1,42 @@
+// pkg/keystore/sign.go
+package keystore
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/layla-lili/blockchain_tools/pkg/signature"
+)
+
+// SignTx unlocks the key for addr and signs tx for chainID. Legacy
+// transactions are signed with EIP-155 replay protection; typed
+// transactions use the signer for their type.
+func (s *Store) SignTx(addr common.Address, passphrase string, tx *gethtypes.Transaction, chainID *big.Int) (*gethtypes.Transaction, error) {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("a positive chain ID is required for signing")
+	}
+
+	priv, err := s.Unlock(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := gethtypes.SignTx(tx, gethtypes.LatestSignerForChainID(chainID), priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// SignHash unlocks the key for addr and signs hash, returning a 65-byte
+// signature with V set to 27 or 28 as expected for EIP-191 and EIP-712
+// signatures
+func (s *Store) SignHash(addr common.Address, passphrase string, hash common.Hash) ([]byte, error) {
+	priv, err := s.Unlock(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Sign(priv, hash)
+}
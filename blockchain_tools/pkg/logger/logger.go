@@ -0,0 +1,47 @@
+// Package logger defines the logger accepted by the clients and services of
+// this module. internal/common/logging.Logger implements it, and any value
+// may be passed as a key or value.
+package logger
+
+import "context"
+
+// Logger receives messages with key-value pairs
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// ContextLogger is implemented by loggers that add the request and trace
+// IDs carried by a context, such as internal/common/logging.Logger
+type ContextLogger interface {
+	InfoContext(ctx context.Context, msg string, keysAndValues ...interface{})
+	ErrorContext(ctx context.Context, msg string, keysAndValues ...interface{})
+}
+
+// Nop discards every message
+var Nop Logger = nop{}
+
+type nop struct{}
+
+func (nop) Info(string, ...interface{})  {}
+func (nop) Error(string, ...interface{}) {}
+
+// InfoContext logs through l.InfoContext when l is a ContextLogger and
+// through l.Info otherwise
+func InfoContext(ctx context.Context, l Logger, msg string, keysAndValues ...interface{}) {
+	if cl, ok := l.(ContextLogger); ok {
+		cl.InfoContext(ctx, msg, keysAndValues...)
+		return
+	}
+	l.Info(msg, keysAndValues...)
+}
+
+// ErrorContext logs through l.ErrorContext when l is a ContextLogger and
+// through l.Error otherwise
+func ErrorContext(ctx context.Context, l Logger, msg string, keysAndValues ...interface{}) {
+	if cl, ok := l.(ContextLogger); ok {
+		cl.ErrorContext(ctx, msg, keysAndValues...)
+		return
+	}
+	l.Error(msg, keysAndValues...)
+}
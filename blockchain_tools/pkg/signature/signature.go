@@ -0,0 +1,135 @@
+// Package signature signs and verifies off-chain messages: EIP-191
+// personal messages, as produced by personal_sign, and EIP-712 typed data,
+// as produced by eth_signTypedData_v4.
+//
+// Signatures are 65 bytes, [R || S || V], with V set to 27 or 28 as wallets
+// return them. Signatures with V set to 0 or 1 are accepted when
+// recovering.
+package signature
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Kinds of signed data
+const (
+	// KindMessage is an EIP-191 personal message
+	KindMessage = "message"
+	// KindTypedData is an EIP-712 typed data document
+	KindTypedData = "typed-data"
+)
+
+// ErrInvalidSignature is returned for signatures that are malformed or do
+// not recover to a public key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// TypedData is an EIP-712 document with its types, domain and message
+type TypedData = apitypes.TypedData
+
+// HashMessage returns the EIP-191 hash of msg:
+// keccak256("\x19Ethereum Signed Message:\n" + len(msg) + msg)
+func HashMessage(msg []byte) common.Hash {
+	return common.BytesToHash(accounts.TextHash(msg))
+}
+
+// ParseTypedData parses an EIP-712 document in the JSON format accepted by
+// eth_signTypedData_v4
+func ParseTypedData(data []byte) (*TypedData, error) {
+	var td TypedData
+	if err := json.Unmarshal(data, &td); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	if td.PrimaryType == "" {
+		return nil, errors.New("invalid typed data: primaryType is required")
+	}
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		return nil, errors.New("invalid typed data: types must include EIP712Domain")
+	}
+	return &td, nil
+}
+
+// HashTypedData returns the EIP-712 hash of td:
+// keccak256("\x19\x01" + domainSeparator + hashStruct(message))
+func HashTypedData(td *TypedData) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(*td)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid typed data: %w", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// Sign signs hash with key, returning a signature with V set to 27 or 28
+func Sign(key *ecdsa.PrivateKey, hash common.Hash) ([]byte, error) {
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// SignMessage signs msg as an EIP-191 personal message
+func SignMessage(key *ecdsa.PrivateKey, msg []byte) ([]byte, error) {
+	return Sign(key, HashMessage(msg))
+}
+
+// SignTypedData signs td as EIP-712 typed data
+func SignTypedData(key *ecdsa.PrivateKey, td *TypedData) ([]byte, error) {
+	hash, err := HashTypedData(td)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(key, hash)
+}
+
+// Recover returns the address whose key produced sig over hash
+func Recover(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, crypto.SignatureLength, len(sig))
+	}
+	normalized := make([]byte, len(sig))
+	copy(normalized, sig)
+	if v := normalized[crypto.RecoveryIDOffset]; v >= 27 {
+		normalized[crypto.RecoveryIDOffset] = v - 27
+	}
+	if normalized[crypto.RecoveryIDOffset] > 1 {
+		return common.Address{}, fmt.Errorf("%w: recovery id must be 0, 1, 27 or 28", ErrInvalidSignature)
+	}
+
+	pub, err := crypto.SigToPub(hash[:], normalized)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// RecoverMessage returns the signer of an EIP-191 personal message
+func RecoverMessage(msg, sig []byte) (common.Address, error) {
+	return Recover(HashMessage(msg), sig)
+}
+
+// RecoverTypedData returns the signer of EIP-712 typed data
+func RecoverTypedData(td *TypedData, sig []byte) (common.Address, error) {
+	hash, err := HashTypedData(td)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return Recover(hash, sig)
+}
+
+// Verify reports whether sig over hash was produced by the key of addr
+func Verify(hash common.Hash, sig []byte, addr common.Address) (bool, error) {
+	signer, err := Recover(hash, sig)
+	if err != nil {
+		return false, err
+	}
+	return signer == addr, nil
+}
@@ -0,0 +1,193 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mailTypedData is the example of the EIP-712 specification, signed there
+// by the key keccak256("cow")
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+// cowKey returns the key of the EIP-712 example, keccak256("cow")
+func cowKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+	seed := crypto.Keccak256([]byte("cow"))
+	key, err := crypto.ToECDSA(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+}
+
+func TestHashMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"hello world", "0xd9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68"},
+		{"Hello Joe", "0xa080337ae51c4e064c189e113edd0ba391df9206e2f49db658bb32cf2911730b"},
+	}
+	for _, tt := range tests {
+		if got := HashMessage([]byte(tt.msg)).Hex(); got != tt.want {
+			t.Errorf("HashMessage(%q) = %s, want %s", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	key, addr := cowKey(t)
+	msg := []byte("hello world")
+
+	sig, err := SignMessage(key, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		t.Fatalf("signature %x is not [R || S || V] with V 27 or 28", sig)
+	}
+	// Signing is deterministic (RFC 6979), as personal_sign is in wallets
+	again, _ := SignMessage(key, msg)
+	if !bytes.Equal(sig, again) {
+		t.Error("signing the same message twice gave different signatures")
+	}
+
+	signer, err := RecoverMessage(msg, sig)
+	if err != nil || signer != addr {
+		t.Fatalf("RecoverMessage = %s, %v, want %s", signer.Hex(), err, addr.Hex())
+	}
+	if ok, err := Verify(HashMessage(msg), sig, addr); !ok || err != nil {
+		t.Errorf("Verify = %t, %v", ok, err)
+	}
+
+	// Other messages recover to other addresses
+	if signer, err := RecoverMessage([]byte("hello world!"), sig); err == nil && signer == addr {
+		t.Error("signature verified for another message")
+	}
+}
+
+func TestTypedDataSpecExample(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatalf("ParseTypedData: %v", err)
+	}
+	hash, err := HashTypedData(td)
+	if err != nil {
+		t.Fatalf("HashTypedData: %v", err)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; hash.Hex() != want {
+		t.Fatalf("HashTypedData = %s, want %s", hash.Hex(), want)
+	}
+
+	// The signature given by the specification
+	want := hexutil.MustDecode("0x" +
+		"4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" +
+		"1c")
+	key, addr := cowKey(t)
+	sig, err := SignTypedData(key, td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Errorf("SignTypedData = %x, want %x", sig, want)
+	}
+
+	signer, err := RecoverTypedData(td, want)
+	if err != nil || signer != addr {
+		t.Errorf("RecoverTypedData = %s, %v, want %s", signer.Hex(), err, addr.Hex())
+	}
+
+	// Changing the message changes the signer
+	td.Message["contents"] = "Hello, Alice!"
+	if signer, err := RecoverTypedData(td, want); err == nil && signer == addr {
+		t.Error("signature verified for a changed message")
+	}
+}
+
+func TestParseTypedDataRejects(t *testing.T) {
+	for _, doc := range []string{
+		`not json`,
+		`{"types": {"EIP712Domain": []}, "domain": {}, "message": {}}`,
+		`{"types": {"Mail": []}, "primaryType": "Mail", "domain": {}, "message": {}}`,
+	} {
+		if _, err := ParseTypedData([]byte(doc)); err == nil {
+			t.Errorf("ParseTypedData(%s) succeeded", doc)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	key, addr := cowKey(t)
+	hash := crypto.Keccak256Hash([]byte("payload"))
+	sig, err := Sign(key, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// V may be 0 or 1 as well as 27 or 28
+	raw := append([]byte(nil), sig...)
+	raw[64] -= 27
+	for _, s := range [][]byte{sig, raw} {
+		if signer, err := Recover(hash, s); err != nil || signer != addr {
+			t.Errorf("Recover(V=%d) = %s, %v, want %s", s[64], signer.Hex(), err, addr.Hex())
+		}
+	}
+	if sig[64] < 27 {
+		t.Fatal("Recover changed the signature it was given")
+	}
+
+	badV := append([]byte(nil), sig...)
+	badV[64] = 29
+	for name, s := range map[string][]byte{
+		"short":      sig[:64],
+		"long":       append(append([]byte(nil), sig...), 0),
+		"bad V":      badV,
+		"zero R & S": append(make([]byte, 64), 27),
+	} {
+		if _, err := Recover(hash, s); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Recover(%s) error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	other, _ := crypto.GenerateKey()
+	if ok, err := Verify(hash, sig, crypto.PubkeyToAddress(other.PublicKey)); ok || err != nil {
+		t.Errorf("Verify with another address = %t, %v", ok, err)
+	}
+}
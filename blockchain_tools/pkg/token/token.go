@@ -0,0 +1,199 @@
+// Package token reads ERC-20 and ERC-721 tokens and encodes transfers and
+// approvals through the standard ABIs.
+package token
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/layla-lili/blockchain_tools/pkg/contract"
+	"github.com/layla-lili/blockchain_tools/pkg/types"
+)
+
+// ErrNotToken is returned for an address that does not implement ERC-20 or
+// ERC-721
+var ErrNotToken = errors.New("not a token contract")
+
+// MaxAmount is the largest uint256, used for unlimited approvals
+var MaxAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Caller executes read-only contract calls. *rpc.Client implements it.
+type Caller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, block string) ([]byte, error)
+}
+
+// Info reads the standard, name, symbol, decimals and total supply of the
+// token at address. Name and symbol are optional in both standards and are
+// left empty when missing; ERC-20 tokens without decimals() report zero.
+func Info(ctx context.Context, caller Caller, address string) (*types.Token, error) {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	token := &types.Token{Address: addr.Hex(), Standard: types.StandardERC20}
+
+	if isERC721(ctx, caller, addr) {
+		token.Standard = types.StandardERC721
+		token.Name, _ = callString(ctx, caller, ERC721, addr, "name")
+		token.Symbol, _ = callString(ctx, caller, ERC721, addr, "symbol")
+		if out, err := call(ctx, caller, ERC721, addr, "totalSupply"); err == nil {
+			token.TotalSupply = out[0].(*big.Int)
+		}
+		return token, nil
+	}
+
+	out, err := call(ctx, caller, ERC20, addr, "totalSupply")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotToken, addr.Hex())
+	}
+	token.TotalSupply = out[0].(*big.Int)
+	token.Name, _ = callString(ctx, caller, ERC20, addr, "name")
+	token.Symbol, _ = callString(ctx, caller, ERC20, addr, "symbol")
+	if out, err := call(ctx, caller, ERC20, addr, "decimals"); err == nil {
+		token.Decimals = out[0].(uint8)
+	}
+	return token, nil
+}
+
+// BalanceOf returns the balance account holds of token, as returned by
+// Info. For ERC-721 tokens the balance is the number of tokens owned.
+func BalanceOf(ctx context.Context, caller Caller, token *types.Token, account string) (*types.TokenBalance, error) {
+	owner, err := parseAddress(account)
+	if err != nil {
+		return nil, err
+	}
+
+	abi := ERC20
+	if token.Standard == types.StandardERC721 {
+		abi = ERC721
+	}
+	out, err := call(ctx, caller, abi, common.HexToAddress(token.Address), "balanceOf", owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	return &types.TokenBalance{
+		Token:    token.Address,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+		Account:  owner.Hex(),
+		Balance:  out[0].(*big.Int),
+	}, nil
+}
+
+// OwnerOf returns the owner of an ERC-721 token and its metadata URI, when
+// the contract provides one
+func OwnerOf(ctx context.Context, caller Caller, address string, tokenID *big.Int) (*types.NFT, error) {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := types.CheckUint256(tokenID); err != nil {
+		return nil, fmt.Errorf("invalid token ID: %w", err)
+	}
+	out, err := call(ctx, caller, ERC721, addr, "ownerOf", tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner of token %s: %w", tokenID, err)
+	}
+
+	nft := &types.NFT{
+		Token:   addr.Hex(),
+		TokenID: tokenID,
+		Owner:   out[0].(common.Address).Hex(),
+	}
+	nft.TokenURI, _ = callString(ctx, caller, ERC721, addr, "tokenURI", tokenID)
+	return nft, nil
+}
+
+// PackTransfer encodes an ERC-20 transfer of amount to to
+func PackTransfer(to string, amount *big.Int) ([]byte, error) {
+	addr, err := parseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.CheckUint256(amount); err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	return ERC20.ABI.Pack("transfer", addr, amount)
+}
+
+// PackApprove encodes an ERC-20 approval letting spender transfer up to
+// amount
+func PackApprove(spender string, amount *big.Int) ([]byte, error) {
+	addr, err := parseAddress(spender)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.CheckUint256(amount); err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	return ERC20.ABI.Pack("approve", addr, amount)
+}
+
+// PackTransferNFT encodes an ERC-721 transferFrom of tokenID
+func PackTransferNFT(from, to string, tokenID *big.Int) ([]byte, error) {
+	fromAddr, err := parseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddr, err := parseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.CheckUint256(tokenID); err != nil {
+		return nil, fmt.Errorf("invalid token ID: %w", err)
+	}
+	return ERC721.ABI.Pack("transferFrom", fromAddr, toAddr, tokenID)
+}
+
+// isERC721 asks the contract through ERC-165 whether it implements ERC-721
+func isERC721(ctx context.Context, caller Caller, addr common.Address) bool {
+	out, err := call(ctx, caller, ERC721, addr, "supportsInterface", erc721InterfaceID)
+	return err == nil && out[0].(bool)
+}
+
+func call(ctx context.Context, caller Caller, abi *contract.Contract, addr common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := callRaw(ctx, caller, abi, addr, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABI.Unpack(method, data)
+}
+
+func callRaw(ctx context.Context, caller Caller, abi *contract.Contract, addr common.Address, method string, args ...interface{}) ([]byte, error) {
+	input, err := abi.ABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return caller.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: input}, "")
+}
+
+// callString calls a method returning a string. Some early tokens return
+// bytes32 instead, which is accepted as well.
+func callString(ctx context.Context, caller Caller, abi *contract.Contract, addr common.Address, method string, args ...interface{}) (string, error) {
+	data, err := callRaw(ctx, caller, abi, addr, method, args...)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 32 {
+		return string(bytes.Trim(data, "\x00")), nil
+	}
+	out, err := abi.ABI.Unpack(method, data)
+	if err != nil {
+		return "", err
+	}
+	return out[0].(string), nil
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
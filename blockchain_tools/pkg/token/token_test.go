@@ -0,0 +1,50 @@
+package token
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const testSpender = "0x000000000000000000000000000000000000dEaD"
+
+func TestPackRejectsOutOfRange(t *testing.T) {
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 256)
+	for _, v := range []*big.Int{big.NewInt(-1), big.NewInt(-5), tooBig} {
+		if data, err := PackTransfer(testSpender, v); err == nil {
+			t.Errorf("PackTransfer(%s) = %s, want error", v, hexutil.Encode(data))
+		}
+		if data, err := PackApprove(testSpender, v); err == nil {
+			t.Errorf("PackApprove(%s) = %s, want error", v, hexutil.Encode(data))
+		}
+		if data, err := PackTransferNFT(testSpender, testSpender, v); err == nil {
+			t.Errorf("PackTransferNFT(%s) = %s, want error", v, hexutil.Encode(data))
+		}
+	}
+}
+
+func TestPackApprove(t *testing.T) {
+	data, err := PackApprove(testSpender, MaxAmount)
+	if err != nil {
+		t.Fatalf("PackApprove(MaxAmount): %v", err)
+	}
+	want := "0x095ea7b3" +
+		"000000000000000000000000000000000000000000000000000000000000dead" +
+		strings.Repeat("f", 64)
+	if got := hexutil.Encode(data); got != want {
+		t.Errorf("PackApprove(MaxAmount) = %s, want %s", got, want)
+	}
+
+	data, err = PackTransfer(testSpender, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("PackTransfer(5): %v", err)
+	}
+	want = "0xa9059cbb" +
+		"000000000000000000000000000000000000000000000000000000000000dead" +
+		"0000000000000000000000000000000000000000000000000000000000000005"
+	if got := hexutil.Encode(data); got != want {
+		t.Errorf("PackTransfer(5) = %s, want %s", got, want)
+	}
+}
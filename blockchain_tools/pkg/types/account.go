@@ -0,0 +1,102 @@
+// pkg/types/account.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// Account represents an account known to the node
+type Account struct {
+	Address string
+	Balance *big.Int
+	Nonce   uint64
+	// Path is the HD derivation path of a local key derived from a mnemonic
+	Path string
+}
+
+type accountEnc struct {
+	Address string   `json:"address" yaml:"address"`
+	Balance *decimal `json:"balance" yaml:"balance"`
+	Nonce   uint64   `json:"nonce" yaml:"nonce"`
+	Path    string   `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (a Account) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&accountEnc{a.Address, (*decimal)(a.Balance), a.Nonce, a.Path})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var enc accountEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	*a = Account{enc.Address, (*big.Int)(enc.Balance), enc.Nonce, enc.Path}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (a Account) MarshalYAML() (interface{}, error) {
+	return &accountEnc{a.Address, (*decimal)(a.Balance), a.Nonce, a.Path}, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (a *Account) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc accountEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	*a = Account{enc.Address, (*big.Int)(enc.Balance), enc.Nonce, enc.Path}
+	return nil
+}
+
+// Balance is the balance of a single address, matching the Balance schema
+// in api/openapi/blockchain.yaml
+type Balance struct {
+	Address string
+	Amount  *big.Int
+}
+
+type balanceEnc struct {
+	Address string   `json:"address" yaml:"address"`
+	Amount  *decimal `json:"amount" yaml:"amount"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (b Balance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&balanceEnc{b.Address, (*decimal)(b.Amount)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *Balance) UnmarshalJSON(data []byte) error {
+	var enc balanceEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	*b = Balance{enc.Address, (*big.Int)(enc.Amount)}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (b Balance) MarshalYAML() (interface{}, error) {
+	return &balanceEnc{b.Address, (*decimal)(b.Amount)}, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *Balance) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc balanceEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	*b = Balance{enc.Address, (*big.Int)(enc.Amount)}
+	return nil
+}
+
+// Mnemonic is a newly generated mnemonic together with the first account
+// derived from it
+type Mnemonic struct {
+	Phrase  string   `json:"mnemonic" yaml:"mnemonic"`
+	Account *Account `json:"account" yaml:"account"`
+}
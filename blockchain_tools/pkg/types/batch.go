@@ -0,0 +1,67 @@
+// pkg/types/batch.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// BatchResult is the outcome of one row of a batch send. Nonce is unset
+// for rows that never got as far as being signed.
+type BatchResult struct {
+	Row    int
+	To     string
+	Value  *big.Int
+	Nonce  *uint64
+	Hash   string
+	Status string
+	Error  string
+}
+
+type batchResultEnc struct {
+	Row    int      `json:"row" yaml:"row"`
+	To     string   `json:"to" yaml:"to"`
+	Value  *decimal `json:"value" yaml:"value"`
+	Nonce  *uint64  `json:"nonce,omitempty" yaml:"nonce,omitempty"`
+	Hash   string   `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Status string   `json:"status" yaml:"status"`
+	Error  string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (r BatchResult) encode() *batchResultEnc {
+	return &batchResultEnc{r.Row, r.To, (*decimal)(r.Value), r.Nonce, r.Hash, r.Status, r.Error}
+}
+
+func (r *BatchResult) decode(enc *batchResultEnc) {
+	*r = BatchResult{enc.Row, enc.To, (*big.Int)(enc.Value), enc.Nonce, enc.Hash, enc.Status, enc.Error}
+}
+
+// MarshalJSON implements json.Marshaler
+func (r BatchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *BatchResult) UnmarshalJSON(data []byte) error {
+	var enc batchResultEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	r.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (r BatchResult) MarshalYAML() (interface{}, error) {
+	return r.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (r *BatchResult) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc batchResultEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	r.decode(&enc)
+	return nil
+}
@@ -0,0 +1,126 @@
+// pkg/types/big.go
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseBig parses a decimal or 0x-prefixed hexadecimal integer
+func ParseBig(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	base := 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s, base = s[2:], 16
+	}
+	v, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// ParseUint256 parses a decimal or 0x-prefixed hexadecimal integer that
+// fits in a uint256, such as a raw token amount or a token ID. Negative
+// numbers are rejected rather than encoded in two's complement.
+func ParseUint256(s string) (*big.Int, error) {
+	v, err := ParseBig(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckUint256(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// CheckUint256 returns an error unless v is non-negative and at most 256
+// bits long
+func CheckUint256(v *big.Int) error {
+	if v.Sign() < 0 || v.BitLen() > 256 {
+		return fmt.Errorf("%s out of range for uint256", v)
+	}
+	return nil
+}
+
+// FormatBig formats v as a decimal string, returning "0" for nil
+func FormatBig(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
+
+// decimal encodes a big integer as a base-10 string in JSON and YAML so
+// values above 2^53 survive JavaScript clients. Decoding also accepts hex
+// strings and bare JSON numbers.
+type decimal big.Int
+
+func (d *decimal) MarshalText() ([]byte, error) {
+	return []byte((*big.Int)(d).String()), nil
+}
+
+func (d *decimal) UnmarshalText(text []byte) error {
+	v, err := ParseBig(string(text))
+	if err != nil {
+		return err
+	}
+	(*big.Int)(d).Set(v)
+	return nil
+}
+
+func (d *decimal) UnmarshalJSON(data []byte) error {
+	return d.UnmarshalText(bytes.Trim(data, `"`))
+}
+
+// FormatUnits formats an integer amount of a token's smallest unit as a
+// decimal number with the given number of decimals, without trailing zeros.
+// For example 1500000 with 6 decimals is "1.5".
+func FormatUnits(v *big.Int, decimals uint8) string {
+	if v == nil {
+		return "0"
+	}
+	s := new(big.Int).Abs(v).String()
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals == 0 {
+		return sign + s
+	}
+
+	d := int(decimals)
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-d], strings.TrimRight(s[len(s)-d:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// ParseUnits parses a decimal number such as "1.5" into an integer amount of
+// a token's smallest unit, rejecting signed amounts, more fractional digits
+// than decimals and results that do not fit in a uint256
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return nil, fmt.Errorf("invalid amount %q: must not be signed", s)
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("invalid amount %q: more than %d decimals", s, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok || whole+frac == "" || strings.ContainsAny(whole+frac, "+-") {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	if err := CheckUint256(v); err != nil {
+		return nil, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals uint8
+		want     string
+		wantErr  bool
+	}{
+		{in: "1.5", decimals: 18, want: "1500000000000000000"},
+		{in: " 0.000001 ", decimals: 6, want: "1"},
+		{in: "42", decimals: 0, want: "42"},
+		{in: ".5", decimals: 1, want: "5"},
+		{in: "1.", decimals: 2, want: "100"},
+		{in: "1.234", decimals: 2, wantErr: true},
+		{in: "-1", decimals: 18, wantErr: true},
+		{in: "+1", decimals: 18, wantErr: true},
+		{in: "-0.5", decimals: 18, wantErr: true},
+		{in: "1.-5", decimals: 18, wantErr: true},
+		{in: "", decimals: 18, wantErr: true},
+		{in: ".", decimals: 18, wantErr: true},
+		{in: "1e18", decimals: 0, wantErr: true},
+		{in: "115792089237316195423570985008687907853269984665640564039457584007913129639935", decimals: 0, want: "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{in: "115792089237316195423570985008687907853269984665640564039457584007913129639936", decimals: 0, wantErr: true},
+		{in: "1", decimals: 255, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.in, tt.decimals)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d) = %s, want error", tt.in, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): %v", tt.in, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseUint256(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "0", want: "0"},
+		{in: "1000000", want: "1000000"},
+		{in: "0xff", want: "255"},
+		{in: "0x" + strings.Repeat("f", 64), want: "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{in: "0x1" + strings.Repeat("0", 64), wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "-0x5", wantErr: true},
+		{in: "1.5", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUint256(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseUint256(%q) = %s, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUint256(%q): %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUint256(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// pkg/types/block.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// Block represents a block on the chain
+type Block struct {
+	Hash         string
+	Height       uint64
+	PreviousHash string
+	Timestamp    int64
+	Miner        string
+	GasLimit     uint64
+	GasUsed      uint64
+	BaseFee      *big.Int
+	Size         uint64
+	Transactions []*Transaction
+}
+
+// blockEnc is the JSON and YAML shape of a Block, matching the Block schema
+// in api/openapi/blockchain.yaml
+type blockEnc struct {
+	Hash         string         `json:"hash" yaml:"hash"`
+	Height       uint64         `json:"number" yaml:"number"`
+	PreviousHash string         `json:"parentHash" yaml:"parentHash"`
+	Timestamp    int64          `json:"timestamp" yaml:"timestamp"`
+	Miner        string         `json:"miner,omitempty" yaml:"miner,omitempty"`
+	GasLimit     uint64         `json:"gasLimit" yaml:"gasLimit"`
+	GasUsed      uint64         `json:"gasUsed" yaml:"gasUsed"`
+	BaseFee      *decimal       `json:"baseFeePerGas,omitempty" yaml:"baseFeePerGas,omitempty"`
+	Size         uint64         `json:"size" yaml:"size"`
+	Transactions []*Transaction `json:"transactions" yaml:"transactions"`
+}
+
+func (b Block) encode() *blockEnc {
+	return &blockEnc{
+		Hash:         b.Hash,
+		Height:       b.Height,
+		PreviousHash: b.PreviousHash,
+		Timestamp:    b.Timestamp,
+		Miner:        b.Miner,
+		GasLimit:     b.GasLimit,
+		GasUsed:      b.GasUsed,
+		BaseFee:      (*decimal)(b.BaseFee),
+		Size:         b.Size,
+		Transactions: b.Transactions,
+	}
+}
+
+func (b *Block) decode(enc *blockEnc) {
+	*b = Block{
+		Hash:         enc.Hash,
+		Height:       enc.Height,
+		PreviousHash: enc.PreviousHash,
+		Timestamp:    enc.Timestamp,
+		Miner:        enc.Miner,
+		GasLimit:     enc.GasLimit,
+		GasUsed:      enc.GasUsed,
+		BaseFee:      (*big.Int)(enc.BaseFee),
+		Size:         enc.Size,
+		Transactions: enc.Transactions,
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (b Block) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *Block) UnmarshalJSON(data []byte) error {
+	var enc blockEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	b.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (b Block) MarshalYAML() (interface{}, error) {
+	return b.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *Block) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc blockEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	b.decode(&enc)
+	return nil
+}
@@ -0,0 +1,59 @@
+// pkg/types/contract.go
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Argument is a value decoded with a contract ABI. Value holds a decimal
+// string for integers, a hex string for addresses and bytes, a string, a
+// bool, a []interface{} for arrays or a []*Argument for tuples.
+type Argument struct {
+	Name  string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Type  string      `json:"type" yaml:"type"`
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+// CallResult is the decoded return value of a read-only contract call
+type CallResult struct {
+	Contract string      `json:"contract" yaml:"contract"`
+	Method   string      `json:"method" yaml:"method"`
+	Outputs  []*Argument `json:"outputs" yaml:"outputs"`
+}
+
+// DecodedEvent is a log decoded with the ABI of the contract that emitted it
+type DecodedEvent struct {
+	Name      string      `json:"name" yaml:"name"`
+	Signature string      `json:"signature" yaml:"signature"`
+	Args      []*Argument `json:"args" yaml:"args"`
+}
+
+// FormatArgs renders arguments on one line as "(name=value, ...)"
+func FormatArgs(args []*Argument) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			parts[i] = arg.Name + "=" + FormatValue(arg.Value)
+		} else {
+			parts[i] = FormatValue(arg.Value)
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// FormatValue renders an Argument value on one line, with arrays as
+// [a, b] and tuples as (name=a, name=b)
+func FormatValue(v interface{}) string {
+	switch v := v.(type) {
+	case []*Argument:
+		return FormatArgs(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = FormatValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,8 @@
+// Package types defines the domain model shared by the CLI, the API server
+// and the RPC client.
+//
+// Wei amounts, gas prices and fees are *big.Int values. They are encoded as
+// decimal strings in JSON and YAML so they match the OpenAPI schemas and
+// survive clients that parse numbers as doubles. Conversions to and from
+// go-ethereum's core/types live in geth.go.
+package types
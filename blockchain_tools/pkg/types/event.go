@@ -0,0 +1,20 @@
+// pkg/types/event.go
+package types
+
+// Event types
+const (
+	EventNewHead            = "newHead"
+	EventReverted           = "reverted"
+	EventPendingTransaction = "pendingTransaction"
+	EventLog                = "log"
+)
+
+// Event is a single notification from a chain stream. Depending on Type,
+// exactly one of Block, TransactionHash and Log is set. Reverted events
+// carry a block that a reorg removed from the chain.
+type Event struct {
+	Type            string `json:"type" yaml:"type"`
+	Block           *Block `json:"block,omitempty" yaml:"block,omitempty"`
+	TransactionHash string `json:"transactionHash,omitempty" yaml:"transactionHash,omitempty"`
+	Log             *Log   `json:"log,omitempty" yaml:"log,omitempty"`
+}
@@ -0,0 +1,160 @@
+// pkg/types/geth.go
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// NewBlockFromHeader converts a go-ethereum header into a Block without
+// transactions
+func NewBlockFromHeader(h *gethtypes.Header) *Block {
+	return &Block{
+		Hash:         h.Hash().Hex(),
+		Height:       h.Number.Uint64(),
+		PreviousHash: h.ParentHash.Hex(),
+		Timestamp:    int64(h.Time),
+		Miner:        h.Coinbase.Hex(),
+		GasLimit:     h.GasLimit,
+		GasUsed:      h.GasUsed,
+		BaseFee:      copyBig(h.BaseFee),
+		Size:         uint64(h.Size()),
+		Transactions: []*Transaction{},
+	}
+}
+
+// NewBlockFromGeth converts a go-ethereum block into a Block
+func NewBlockFromGeth(b *gethtypes.Block) *Block {
+	block := NewBlockFromHeader(b.Header())
+	block.Size = b.Size()
+
+	for _, gtx := range b.Transactions() {
+		tx := NewTransactionFromGeth(gtx)
+		tx.Status = StatusConfirmed
+		tx.BlockHash = block.Hash
+		tx.BlockNumber = block.Height
+		tx.Timestamp = block.Timestamp
+		block.Transactions = append(block.Transactions, tx)
+	}
+	return block
+}
+
+// NewTransactionFromGeth converts a signed go-ethereum transaction into a
+// pending Transaction. The sender is recovered from the signature.
+func NewTransactionFromGeth(gtx *gethtypes.Transaction) *Transaction {
+	tx := &Transaction{
+		Hash:     gtx.Hash().Hex(),
+		Value:    copyBig(gtx.Value()),
+		Data:     gtx.Data(),
+		Nonce:    gtx.Nonce(),
+		Gas:      gtx.Gas(),
+		GasPrice: copyBig(gtx.GasPrice()),
+		Type:     gtx.Type(),
+		Status:   StatusPending,
+	}
+	if gtx.To() != nil {
+		tx.To = gtx.To().Hex()
+	}
+	if gtx.Type() != gethtypes.LegacyTxType {
+		tx.MaxFeePerGas = copyBig(gtx.GasFeeCap())
+		tx.MaxPriorityFeePerGas = copyBig(gtx.GasTipCap())
+	}
+	if gtx.Protected() {
+		tx.ChainID = copyBig(gtx.ChainId())
+	}
+
+	signer := gethtypes.LatestSignerForChainID(gtx.ChainId())
+	if from, err := gethtypes.Sender(signer, gtx); err == nil {
+		tx.From = from.Hex()
+	}
+	return tx
+}
+
+// ToGeth converts tx into an unsigned go-ethereum transaction. A dynamic fee
+// transaction is built when MaxFeePerGas is set, a legacy one otherwise.
+func (tx *Transaction) ToGeth() (*gethtypes.Transaction, error) {
+	to, err := tx.toAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.MaxFeePerGas != nil {
+		if tx.ChainID == nil {
+			return nil, fmt.Errorf("chain ID is required for dynamic fee transactions")
+		}
+		return gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+			ChainID:   copyBig(tx.ChainID),
+			Nonce:     tx.Nonce,
+			GasTipCap: orZero(tx.MaxPriorityFeePerGas),
+			GasFeeCap: copyBig(tx.MaxFeePerGas),
+			Gas:       tx.Gas,
+			To:        to,
+			Value:     orZero(tx.Value),
+			Data:      tx.Data,
+		}), nil
+	}
+
+	return gethtypes.NewTx(&gethtypes.LegacyTx{
+		Nonce:    tx.Nonce,
+		GasPrice: orZero(tx.GasPrice),
+		Gas:      tx.Gas,
+		To:       to,
+		Value:    orZero(tx.Value),
+		Data:     tx.Data,
+	}), nil
+}
+
+// CallMsg converts tx into a go-ethereum call message for eth_call and
+// eth_estimateGas
+func (tx *Transaction) CallMsg() (ethereum.CallMsg, error) {
+	to, err := tx.toAddress()
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+
+	msg := ethereum.CallMsg{
+		To:        to,
+		Gas:       tx.Gas,
+		GasPrice:  tx.GasPrice,
+		GasFeeCap: tx.MaxFeePerGas,
+		GasTipCap: tx.MaxPriorityFeePerGas,
+		Value:     tx.Value,
+		Data:      tx.Data,
+	}
+	if tx.From != "" {
+		if !common.IsHexAddress(tx.From) {
+			return ethereum.CallMsg{}, fmt.Errorf("invalid from address %q", tx.From)
+		}
+		msg.From = common.HexToAddress(tx.From)
+	}
+	return msg, nil
+}
+
+func (tx *Transaction) toAddress() (*common.Address, error) {
+	if tx.To == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(tx.To) {
+		return nil, fmt.Errorf("invalid to address %q", tx.To)
+	}
+	to := common.HexToAddress(tx.To)
+	return &to, nil
+}
+
+func copyBig(v *big.Int) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return new(big.Int).Set(v)
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(v)
+}
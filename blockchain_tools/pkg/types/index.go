@@ -0,0 +1,12 @@
+// pkg/types/index.go
+package types
+
+// IndexStatus describes the contents of the local chain index
+type IndexStatus struct {
+	StartHeight  uint64 `json:"startHeight" yaml:"startHeight"`
+	Height       uint64 `json:"height" yaml:"height"`
+	Hash         string `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Blocks       uint64 `json:"blocks" yaml:"blocks"`
+	Transactions uint64 `json:"transactions" yaml:"transactions"`
+	Logs         uint64 `json:"logs" yaml:"logs"`
+}
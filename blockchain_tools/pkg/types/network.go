@@ -0,0 +1,20 @@
+// pkg/types/network.go
+package types
+
+// Network is a CLI network profile: the endpoints of a chain and the
+// defaults used when sending to it. Active marks the profile selected with
+// --network or the network setting.
+type Network struct {
+	Name           string  `json:"name" yaml:"name"`
+	Active         bool    `json:"active" yaml:"active"`
+	RPCURL         string  `json:"rpcUrl" yaml:"rpcUrl"`
+	WSURL          string  `json:"wsUrl,omitempty" yaml:"wsUrl,omitempty"`
+	APIURL         string  `json:"apiUrl,omitempty" yaml:"apiUrl,omitempty"`
+	ChainID        uint64  `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	ExplorerURL    string  `json:"explorerUrl,omitempty" yaml:"explorerUrl,omitempty"`
+	DefaultAccount string  `json:"defaultAccount,omitempty" yaml:"defaultAccount,omitempty"`
+	GasMultiplier  float64 `json:"gasMultiplier,omitempty" yaml:"gasMultiplier,omitempty"`
+	MaxFee         string  `json:"maxFee,omitempty" yaml:"maxFee,omitempty"`
+	MaxPriorityFee string  `json:"maxPriorityFee,omitempty" yaml:"maxPriorityFee,omitempty"`
+	Legacy         bool    `json:"legacy,omitempty" yaml:"legacy,omitempty"`
+}
@@ -0,0 +1,42 @@
+// pkg/types/node.go
+package types
+
+// NodeStatus summarizes the state of the connected node
+type NodeStatus struct {
+	ClientVersion string `json:"clientVersion" yaml:"clientVersion"`
+	NetworkID     string `json:"networkId" yaml:"networkId"`
+	ChainID       uint64 `json:"chainId" yaml:"chainId"`
+	BlockNumber   uint64 `json:"blockNumber" yaml:"blockNumber"`
+	PeerCount     uint64 `json:"peerCount" yaml:"peerCount"`
+	Listening     bool   `json:"listening" yaml:"listening"`
+	Syncing       bool   `json:"syncing" yaml:"syncing"`
+}
+
+// Peer represents a peer connected to the node
+type Peer struct {
+	ID            string   `json:"id" yaml:"id"`
+	Name          string   `json:"name" yaml:"name"`
+	Enode         string   `json:"enode,omitempty" yaml:"enode,omitempty"`
+	RemoteAddress string   `json:"remoteAddress" yaml:"remoteAddress"`
+	LocalAddress  string   `json:"localAddress" yaml:"localAddress"`
+	Caps          []string `json:"caps,omitempty" yaml:"caps,omitempty"`
+}
+
+// SyncStatus reports the synchronization progress of the node
+type SyncStatus struct {
+	Syncing       bool   `json:"syncing" yaml:"syncing"`
+	StartingBlock uint64 `json:"startingBlock" yaml:"startingBlock"`
+	CurrentBlock  uint64 `json:"currentBlock" yaml:"currentBlock"`
+	HighestBlock  uint64 `json:"highestBlock" yaml:"highestBlock"`
+}
+
+// Endpoint reports the health and usage of one RPC endpoint of a client
+type Endpoint struct {
+	URL          string  `json:"url" yaml:"url"`
+	Healthy      bool    `json:"healthy" yaml:"healthy"`
+	Requests     uint64  `json:"requests" yaml:"requests"`
+	Failures     uint64  `json:"failures" yaml:"failures"`
+	Retries      uint64  `json:"retries" yaml:"retries"`
+	AvgLatencyMs float64 `json:"avgLatencyMs" yaml:"avgLatencyMs"`
+	LastError    string  `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+}
@@ -0,0 +1,161 @@
+// pkg/types/receipt.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Receipt is the outcome of a transaction. Status is one of the Status*
+// constants; dropped and replaced receipts carry only the hash and status.
+type Receipt struct {
+	TransactionHash   string
+	Status            string
+	BlockHash         string
+	BlockNumber       uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+	ContractAddress   string
+	Confirmations     uint64
+	ReplacedBy        string
+	Logs              []*Log
+}
+
+type receiptEnc struct {
+	TransactionHash   string   `json:"transactionHash" yaml:"transactionHash"`
+	Status            string   `json:"status" yaml:"status"`
+	BlockHash         string   `json:"blockHash,omitempty" yaml:"blockHash,omitempty"`
+	BlockNumber       uint64   `json:"blockNumber,omitempty" yaml:"blockNumber,omitempty"`
+	GasUsed           uint64   `json:"gasUsed,omitempty" yaml:"gasUsed,omitempty"`
+	EffectiveGasPrice *decimal `json:"effectiveGasPrice,omitempty" yaml:"effectiveGasPrice,omitempty"`
+	ContractAddress   string   `json:"contractAddress,omitempty" yaml:"contractAddress,omitempty"`
+	Confirmations     uint64   `json:"confirmations" yaml:"confirmations"`
+	ReplacedBy        string   `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+	Logs              []*Log   `json:"logs" yaml:"logs"`
+}
+
+func (r Receipt) encode() *receiptEnc {
+	return &receiptEnc{
+		TransactionHash:   r.TransactionHash,
+		Status:            r.Status,
+		BlockHash:         r.BlockHash,
+		BlockNumber:       r.BlockNumber,
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: (*decimal)(r.EffectiveGasPrice),
+		ContractAddress:   r.ContractAddress,
+		Confirmations:     r.Confirmations,
+		ReplacedBy:        r.ReplacedBy,
+		Logs:              r.Logs,
+	}
+}
+
+func (r *Receipt) decode(enc *receiptEnc) {
+	*r = Receipt{
+		TransactionHash:   enc.TransactionHash,
+		Status:            enc.Status,
+		BlockHash:         enc.BlockHash,
+		BlockNumber:       enc.BlockNumber,
+		GasUsed:           enc.GasUsed,
+		EffectiveGasPrice: (*big.Int)(enc.EffectiveGasPrice),
+		ContractAddress:   enc.ContractAddress,
+		Confirmations:     enc.Confirmations,
+		ReplacedBy:        enc.ReplacedBy,
+		Logs:              enc.Logs,
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (r Receipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *Receipt) UnmarshalJSON(data []byte) error {
+	var enc receiptEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	r.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (r Receipt) MarshalYAML() (interface{}, error) {
+	return r.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (r *Receipt) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc receiptEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	r.decode(&enc)
+	return nil
+}
+
+// Log is an event emitted by a contract. Decoded is set once the log has
+// been matched against the emitting contract's ABI.
+type Log struct {
+	Address         string
+	Topics          []string
+	Data            []byte
+	BlockNumber     uint64
+	BlockHash       string
+	TransactionHash string
+	LogIndex        uint
+	Removed         bool
+	Decoded         *DecodedEvent
+}
+
+type logEnc struct {
+	Address         string        `json:"address" yaml:"address"`
+	Topics          []string      `json:"topics" yaml:"topics"`
+	Data            hexutil.Bytes `json:"data" yaml:"data"`
+	BlockNumber     uint64        `json:"blockNumber" yaml:"blockNumber"`
+	BlockHash       string        `json:"blockHash" yaml:"blockHash"`
+	TransactionHash string        `json:"transactionHash" yaml:"transactionHash"`
+	LogIndex        uint          `json:"logIndex" yaml:"logIndex"`
+	Removed         bool          `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Decoded         *DecodedEvent `json:"decoded,omitempty" yaml:"decoded,omitempty"`
+}
+
+func (l Log) encode() *logEnc {
+	return &logEnc{l.Address, l.Topics, l.Data, l.BlockNumber, l.BlockHash, l.TransactionHash, l.LogIndex, l.Removed, l.Decoded}
+}
+
+func (l *Log) decode(enc *logEnc) {
+	*l = Log{enc.Address, enc.Topics, enc.Data, enc.BlockNumber, enc.BlockHash, enc.TransactionHash, enc.LogIndex, enc.Removed, enc.Decoded}
+}
+
+// MarshalJSON implements json.Marshaler
+func (l Log) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *Log) UnmarshalJSON(data []byte) error {
+	var enc logEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	l.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (l Log) MarshalYAML() (interface{}, error) {
+	return l.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *Log) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc logEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	l.decode(&enc)
+	return nil
+}
@@ -0,0 +1,24 @@
+// pkg/types/signature.go
+package types
+
+import "github.com/ethereum/go-ethereum/common/hexutil"
+
+// Signature is a signature over an EIP-191 message or EIP-712 typed data.
+// Type is "message" or "typed-data" and Hash is the digest that was
+// signed.
+type Signature struct {
+	Address   string        `json:"address" yaml:"address"`
+	Type      string        `json:"type" yaml:"type"`
+	Hash      string        `json:"hash" yaml:"hash"`
+	Signature hexutil.Bytes `json:"signature" yaml:"signature"`
+}
+
+// Verification is the outcome of checking a signature against an expected
+// address. Signer is the address recovered from the signature.
+type Verification struct {
+	Address string `json:"address" yaml:"address"`
+	Signer  string `json:"signer" yaml:"signer"`
+	Type    string `json:"type" yaml:"type"`
+	Hash    string `json:"hash" yaml:"hash"`
+	Valid   bool   `json:"valid" yaml:"valid"`
+}
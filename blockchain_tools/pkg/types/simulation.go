@@ -0,0 +1,134 @@
+// pkg/types/simulation.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Simulation is the outcome of running a built transaction through
+// eth_call and eth_estimateGas without sending it. Transaction carries the
+// gas limit that would be sent; Revert is the decoded reason of a reverted
+// call.
+type Simulation struct {
+	Transaction *Transaction
+	Block       string
+	Success     bool
+	GasEstimate uint64
+	ReturnData  []byte
+	Error       string
+	RevertData  []byte
+	Revert      string
+}
+
+type simulationEnc struct {
+	Transaction *Transaction  `json:"transaction" yaml:"transaction"`
+	Block       string        `json:"block" yaml:"block"`
+	Success     bool          `json:"success" yaml:"success"`
+	GasEstimate uint64        `json:"gasEstimate,omitempty" yaml:"gasEstimate,omitempty"`
+	ReturnData  hexutil.Bytes `json:"returnData,omitempty" yaml:"returnData,omitempty"`
+	Error       string        `json:"error,omitempty" yaml:"error,omitempty"`
+	RevertData  hexutil.Bytes `json:"revertData,omitempty" yaml:"revertData,omitempty"`
+	Revert      string        `json:"revert,omitempty" yaml:"revert,omitempty"`
+}
+
+func (s Simulation) encode() *simulationEnc {
+	return &simulationEnc{s.Transaction, s.Block, s.Success, s.GasEstimate,
+		s.ReturnData, s.Error, s.RevertData, s.Revert}
+}
+
+func (s *Simulation) decode(enc *simulationEnc) {
+	*s = Simulation{enc.Transaction, enc.Block, enc.Success, enc.GasEstimate,
+		enc.ReturnData, enc.Error, enc.RevertData, enc.Revert}
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Simulation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Simulation) UnmarshalJSON(data []byte) error {
+	var enc simulationEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	s.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (s Simulation) MarshalYAML() (interface{}, error) {
+	return s.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Simulation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc simulationEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	s.decode(&enc)
+	return nil
+}
+
+// StateOverrides replaces account state for the duration of a simulation,
+// keyed by account address
+type StateOverrides map[string]*AccountOverride
+
+// AccountOverride is the state of a single account during a simulation.
+// Unset fields keep the account's real state; StateDiff replaces single
+// storage slots, keyed and valued by 32-byte hex words.
+type AccountOverride struct {
+	Balance   *big.Int
+	Nonce     *uint64
+	Code      []byte
+	StateDiff map[string]string
+}
+
+type accountOverrideEnc struct {
+	Balance   *decimal          `json:"balance,omitempty" yaml:"balance,omitempty"`
+	Nonce     *uint64           `json:"nonce,omitempty" yaml:"nonce,omitempty"`
+	Code      hexutil.Bytes     `json:"code,omitempty" yaml:"code,omitempty"`
+	StateDiff map[string]string `json:"stateDiff,omitempty" yaml:"stateDiff,omitempty"`
+}
+
+func (o AccountOverride) encode() *accountOverrideEnc {
+	return &accountOverrideEnc{(*decimal)(o.Balance), o.Nonce, o.Code, o.StateDiff}
+}
+
+func (o *AccountOverride) decode(enc *accountOverrideEnc) {
+	*o = AccountOverride{(*big.Int)(enc.Balance), enc.Nonce, enc.Code, enc.StateDiff}
+}
+
+// MarshalJSON implements json.Marshaler
+func (o AccountOverride) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *AccountOverride) UnmarshalJSON(data []byte) error {
+	var enc accountOverrideEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	o.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (o AccountOverride) MarshalYAML() (interface{}, error) {
+	return o.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (o *AccountOverride) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc accountOverrideEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	o.decode(&enc)
+	return nil
+}
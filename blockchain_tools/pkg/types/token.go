@@ -0,0 +1,179 @@
+// pkg/types/token.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// Token standards
+const (
+	StandardERC20  = "ERC20"
+	StandardERC721 = "ERC721"
+)
+
+// Token describes a token contract. ERC-721 tokens have no decimals and
+// only report a total supply when they implement the enumerable extension.
+type Token struct {
+	Address     string
+	Standard    string
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+}
+
+type tokenEnc struct {
+	Address     string   `json:"address" yaml:"address"`
+	Standard    string   `json:"standard" yaml:"standard"`
+	Name        string   `json:"name" yaml:"name"`
+	Symbol      string   `json:"symbol" yaml:"symbol"`
+	Decimals    uint8    `json:"decimals" yaml:"decimals"`
+	TotalSupply *decimal `json:"totalSupply,omitempty" yaml:"totalSupply,omitempty"`
+}
+
+func (t Token) encode() *tokenEnc {
+	return &tokenEnc{t.Address, t.Standard, t.Name, t.Symbol, t.Decimals, (*decimal)(t.TotalSupply)}
+}
+
+func (t *Token) decode(enc *tokenEnc) {
+	*t = Token{enc.Address, enc.Standard, enc.Name, enc.Symbol, enc.Decimals, (*big.Int)(enc.TotalSupply)}
+}
+
+// MarshalJSON implements json.Marshaler
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var enc tokenEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	t.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (t Token) MarshalYAML() (interface{}, error) {
+	return t.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (t *Token) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc tokenEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	t.decode(&enc)
+	return nil
+}
+
+// TokenBalance is the balance an account holds of a token, in the token's
+// smallest unit. It is encoded together with the balance scaled by the
+// token's decimals.
+type TokenBalance struct {
+	Token    string
+	Symbol   string
+	Decimals uint8
+	Account  string
+	Balance  *big.Int
+}
+
+type tokenBalanceEnc struct {
+	Token     string   `json:"token" yaml:"token"`
+	Symbol    string   `json:"symbol" yaml:"symbol"`
+	Decimals  uint8    `json:"decimals" yaml:"decimals"`
+	Account   string   `json:"account" yaml:"account"`
+	Balance   *decimal `json:"balance" yaml:"balance"`
+	Formatted string   `json:"formatted" yaml:"formatted"`
+}
+
+func (b TokenBalance) encode() *tokenBalanceEnc {
+	return &tokenBalanceEnc{b.Token, b.Symbol, b.Decimals, b.Account, (*decimal)(b.Balance), b.Formatted()}
+}
+
+func (b *TokenBalance) decode(enc *tokenBalanceEnc) {
+	*b = TokenBalance{enc.Token, enc.Symbol, enc.Decimals, enc.Account, (*big.Int)(enc.Balance)}
+}
+
+// Formatted returns the balance scaled by the token's decimals
+func (b TokenBalance) Formatted() string {
+	return FormatUnits(b.Balance, b.Decimals)
+}
+
+// MarshalJSON implements json.Marshaler
+func (b TokenBalance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *TokenBalance) UnmarshalJSON(data []byte) error {
+	var enc tokenBalanceEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	b.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (b TokenBalance) MarshalYAML() (interface{}, error) {
+	return b.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *TokenBalance) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc tokenBalanceEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	b.decode(&enc)
+	return nil
+}
+
+// NFT is the owner of a single ERC-721 token
+type NFT struct {
+	Token    string
+	TokenID  *big.Int
+	Owner    string
+	TokenURI string
+}
+
+type nftEnc struct {
+	Token    string   `json:"token" yaml:"token"`
+	TokenID  *decimal `json:"tokenId" yaml:"tokenId"`
+	Owner    string   `json:"owner" yaml:"owner"`
+	TokenURI string   `json:"tokenURI,omitempty" yaml:"tokenURI,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NFT) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&nftEnc{n.Token, (*decimal)(n.TokenID), n.Owner, n.TokenURI})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *NFT) UnmarshalJSON(data []byte) error {
+	var enc nftEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	*n = NFT{enc.Token, (*big.Int)(enc.TokenID), enc.Owner, enc.TokenURI}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (n NFT) MarshalYAML() (interface{}, error) {
+	return &nftEnc{n.Token, (*decimal)(n.TokenID), n.Owner, n.TokenURI}, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (n *NFT) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc nftEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	*n = NFT{enc.Token, (*big.Int)(enc.TokenID), enc.Owner, enc.TokenURI}
+	return nil
+}
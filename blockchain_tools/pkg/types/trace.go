@@ -0,0 +1,104 @@
+// pkg/types/trace.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Trace is the execution trace of a transaction: its call tree and,
+// when requested, the opcodes it executed
+type Trace struct {
+	Hash    string       `json:"hash" yaml:"hash"`
+	Failed  bool         `json:"failed" yaml:"failed"`
+	GasUsed uint64       `json:"gasUsed" yaml:"gasUsed"`
+	Call    *CallFrame   `json:"call" yaml:"call"`
+	Steps   []*TraceStep `json:"steps,omitempty" yaml:"steps,omitempty"`
+}
+
+// CallFrame is a single call in a transaction's call tree. Method is the
+// signature of the method called and Revert the decoded reason of a
+// reverted frame, set when they can be decoded.
+type CallFrame struct {
+	Type    string
+	From    string
+	To      string
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	Error   string
+	Method  string
+	Revert  string
+	Calls   []*CallFrame
+}
+
+type callFrameEnc struct {
+	Type    string        `json:"type" yaml:"type"`
+	From    string        `json:"from" yaml:"from"`
+	To      string        `json:"to" yaml:"to"`
+	Value   *decimal      `json:"value,omitempty" yaml:"value,omitempty"`
+	Gas     uint64        `json:"gas" yaml:"gas"`
+	GasUsed uint64        `json:"gasUsed" yaml:"gasUsed"`
+	Input   hexutil.Bytes `json:"input,omitempty" yaml:"input,omitempty"`
+	Output  hexutil.Bytes `json:"output,omitempty" yaml:"output,omitempty"`
+	Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Method  string        `json:"method,omitempty" yaml:"method,omitempty"`
+	Revert  string        `json:"revert,omitempty" yaml:"revert,omitempty"`
+	Calls   []*CallFrame  `json:"calls,omitempty" yaml:"calls,omitempty"`
+}
+
+func (f CallFrame) encode() *callFrameEnc {
+	return &callFrameEnc{f.Type, f.From, f.To, (*decimal)(f.Value), f.Gas, f.GasUsed,
+		f.Input, f.Output, f.Error, f.Method, f.Revert, f.Calls}
+}
+
+func (f *CallFrame) decode(enc *callFrameEnc) {
+	*f = CallFrame{enc.Type, enc.From, enc.To, (*big.Int)(enc.Value), enc.Gas, enc.GasUsed,
+		enc.Input, enc.Output, enc.Error, enc.Method, enc.Revert, enc.Calls}
+}
+
+// MarshalJSON implements json.Marshaler
+func (f CallFrame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *CallFrame) UnmarshalJSON(data []byte) error {
+	var enc callFrameEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	f.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (f CallFrame) MarshalYAML() (interface{}, error) {
+	return f.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (f *CallFrame) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc callFrameEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	f.decode(&enc)
+	return nil
+}
+
+// TraceStep is one opcode executed by a transaction, as reported by the
+// struct logger
+type TraceStep struct {
+	PC      uint64   `json:"pc" yaml:"pc"`
+	Op      string   `json:"op" yaml:"op"`
+	Gas     uint64   `json:"gas" yaml:"gas"`
+	GasCost uint64   `json:"gasCost" yaml:"gasCost"`
+	Depth   int      `json:"depth" yaml:"depth"`
+	Stack   []string `json:"stack,omitempty" yaml:"stack,omitempty"`
+	Error   string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
@@ -0,0 +1,134 @@
+// pkg/types/transaction.go
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Transaction statuses
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+	// StatusDropped marks a transaction that left the pool without being mined
+	StatusDropped = "dropped"
+	// StatusReplaced marks a transaction whose nonce was used by another one
+	StatusReplaced = "replaced"
+)
+
+// Transaction represents a transaction on the chain. Amounts are kept as
+// big integers since wei values routinely exceed 64 bits.
+type Transaction struct {
+	Hash                 string
+	From                 string
+	To                   string
+	Value                *big.Int
+	Data                 []byte
+	Nonce                uint64
+	Gas                  uint64
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	ChainID              *big.Int
+	Type                 uint8
+	Status               string
+	BlockHash            string
+	BlockNumber          uint64
+	Timestamp            int64
+}
+
+// transactionEnc is the JSON and YAML shape of a Transaction, matching the
+// Transaction schema in api/openapi/blockchain.yaml
+type transactionEnc struct {
+	Hash                 string        `json:"hash" yaml:"hash"`
+	From                 string        `json:"from" yaml:"from"`
+	To                   string        `json:"to" yaml:"to"`
+	Value                *decimal      `json:"value" yaml:"value"`
+	Data                 hexutil.Bytes `json:"data,omitempty" yaml:"data,omitempty"`
+	Nonce                uint64        `json:"nonce" yaml:"nonce"`
+	Gas                  uint64        `json:"gas,omitempty" yaml:"gas,omitempty"`
+	GasPrice             *decimal      `json:"gasPrice,omitempty" yaml:"gasPrice,omitempty"`
+	MaxFeePerGas         *decimal      `json:"maxFeePerGas,omitempty" yaml:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *decimal      `json:"maxPriorityFeePerGas,omitempty" yaml:"maxPriorityFeePerGas,omitempty"`
+	ChainID              *decimal      `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	Type                 uint8         `json:"type" yaml:"type"`
+	Status               string        `json:"status" yaml:"status"`
+	BlockHash            string        `json:"blockHash,omitempty" yaml:"blockHash,omitempty"`
+	BlockNumber          uint64        `json:"blockNumber,omitempty" yaml:"blockNumber,omitempty"`
+	Timestamp            int64         `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+}
+
+func (tx Transaction) encode() *transactionEnc {
+	return &transactionEnc{
+		Hash:                 tx.Hash,
+		From:                 tx.From,
+		To:                   tx.To,
+		Value:                (*decimal)(tx.Value),
+		Data:                 tx.Data,
+		Nonce:                tx.Nonce,
+		Gas:                  tx.Gas,
+		GasPrice:             (*decimal)(tx.GasPrice),
+		MaxFeePerGas:         (*decimal)(tx.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*decimal)(tx.MaxPriorityFeePerGas),
+		ChainID:              (*decimal)(tx.ChainID),
+		Type:                 tx.Type,
+		Status:               tx.Status,
+		BlockHash:            tx.BlockHash,
+		BlockNumber:          tx.BlockNumber,
+		Timestamp:            tx.Timestamp,
+	}
+}
+
+func (tx *Transaction) decode(enc *transactionEnc) {
+	*tx = Transaction{
+		Hash:                 enc.Hash,
+		From:                 enc.From,
+		To:                   enc.To,
+		Value:                (*big.Int)(enc.Value),
+		Data:                 enc.Data,
+		Nonce:                enc.Nonce,
+		Gas:                  enc.Gas,
+		GasPrice:             (*big.Int)(enc.GasPrice),
+		MaxFeePerGas:         (*big.Int)(enc.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*big.Int)(enc.MaxPriorityFeePerGas),
+		ChainID:              (*big.Int)(enc.ChainID),
+		Type:                 enc.Type,
+		Status:               enc.Status,
+		BlockHash:            enc.BlockHash,
+		BlockNumber:          enc.BlockNumber,
+		Timestamp:            enc.Timestamp,
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (tx Transaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tx.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (tx *Transaction) UnmarshalJSON(data []byte) error {
+	var enc transactionEnc
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	tx.decode(&enc)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (tx Transaction) MarshalYAML() (interface{}, error) {
+	return tx.encode(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (tx *Transaction) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enc transactionEnc
+	if err := unmarshal(&enc); err != nil {
+		return err
+	}
+	tx.decode(&enc)
+	return nil
+}